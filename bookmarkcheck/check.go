@@ -0,0 +1,275 @@
+package bookmarkcheck
+
+import (
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"slices"
+	"sort"
+)
+
+// Validates the structure of the bookmark tree, similar to the bookmark validator of firefox
+// https://searchfox.org/mozilla-central/source/services/sync/modules/bookmark_validator.sys.mjs
+
+type ProblemType string
+
+const (
+	ProblemOrphan          ProblemType = "orphan"            // the parent of the record does not exist
+	ProblemParentNotFolder ProblemType = "parent-not-folder" // the parent of the record is not a folder
+	ProblemParentMismatch  ProblemType = "parent-mismatch"   // the `parentid` of the record does not match the folder that lists it as a child
+	ProblemMultipleParents ProblemType = "multiple-parents"  // the record is listed in the children of multiple folders
+	ProblemDuplicateChild  ProblemType = "duplicate-child"   // a folder lists the same child multiple times
+	ProblemMissingChild    ProblemType = "missing-child"     // a folder lists a child that does not exist
+	ProblemDeletedChild    ProblemType = "deleted-child"     // a folder lists a child that is deleted
+	ProblemParentName      ProblemType = "parent-name"       // the `parentName` of the record is not the title of its parent
+	ProblemCycle           ProblemType = "cycle"             // the record is (transitively) its own parent
+)
+
+type Problem struct {
+	Type     ProblemType
+	RecordID string
+	Message  string
+}
+
+// Patch contains the fields of a record that need to be changed to repair the tree (nil = unchanged)
+type Patch struct {
+	Create     bool // the record is a missing root folder and needs to be created
+	ParentID   *string
+	ParentName *string
+	Children   *[]string
+}
+
+type Result struct {
+	Problems []Problem
+	Patches  map[string]*Patch
+}
+
+// Check validates the bookmark records (including the deleted ones) and calculates the changes to repair them
+// Orphans (and records in a cycle) are moved to the `unfiled` root, the children of every folder are rewritten to match the parentid of the records.
+func Check(records []models.BookmarkRecord) Result {
+	c := &checker{
+		live:    make(map[string]models.BookmarkRecord, len(records)),
+		deleted: make(map[string]bool),
+		ids:     make([]string, 0, len(records)),
+		parent:  make(map[string]string, len(records)),
+		result:  Result{Problems: make([]Problem, 0), Patches: make(map[string]*Patch)},
+	}
+
+	for _, v := range records {
+		if v.Deleted {
+			c.deleted[v.ID] = true
+		} else if _, ok := c.live[v.ID]; !ok {
+			c.live[v.ID] = v
+			c.ids = append(c.ids, v.ID)
+		}
+	}
+	sort.Strings(c.ids)
+
+	listedBy := c.checkChildren()
+	c.assignParents(listedBy)
+	c.breakCycles()
+	c.rebuildChildren()
+	c.checkParentFields()
+
+	return c.result
+}
+
+type checker struct {
+	live    map[string]models.BookmarkRecord
+	deleted map[string]bool
+	ids     []string          // the IDs of all live records (sorted)
+	parent  map[string]string // the (repaired) parent of every live record
+	result  Result
+}
+
+func (c *checker) problem(t ProblemType, id string, format string, args ...any) {
+	c.result.Problems = append(c.result.Problems, Problem{Type: t, RecordID: id, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) patch(id string) *Patch {
+	if p, ok := c.result.Patches[id]; ok {
+		return p
+	}
+	p := &Patch{}
+	c.result.Patches[id] = p
+	return p
+}
+
+func (c *checker) isFolder(id string) bool {
+	v, ok := c.live[id]
+	return ok && (v.Type == models.BookmarkTypeFolder || v.Type == models.BookmarkTypeLivemark)
+}
+
+func isRoot(id string) bool {
+	return langext.InArray(id, consts.BookmarkRootIDs)
+}
+
+// checkChildren validates the children of all folders and returns the folders that list each record
+func (c *checker) checkChildren() map[string][]string {
+	listedBy := make(map[string][]string)
+
+	for _, fid := range c.ids {
+		if !c.isFolder(fid) {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, cid := range c.live[fid].Children {
+			if seen[cid] {
+				c.problem(ProblemDuplicateChild, fid, "folder lists the child '%s' multiple times", cid)
+				continue
+			}
+			seen[cid] = true
+
+			if c.deleted[cid] {
+				c.problem(ProblemDeletedChild, fid, "folder lists the deleted record '%s' as a child", cid)
+				continue
+			}
+			if _, ok := c.live[cid]; !ok {
+				c.problem(ProblemMissingChild, fid, "folder lists the non-existent record '%s' as a child", cid)
+				continue
+			}
+
+			listedBy[cid] = append(listedBy[cid], fid)
+		}
+	}
+
+	return listedBy
+}
+
+// assignParents decides for every record in which folder it belongs
+func (c *checker) assignParents(listedBy map[string][]string) {
+	for _, id := range c.ids {
+		v := c.live[id]
+
+		if id == consts.BookmarkIDPlaces {
+			continue
+		}
+
+		if isRoot(id) {
+			c.parent[id] = consts.BookmarkIDPlaces
+			if v.ParentID != consts.BookmarkIDPlaces {
+				c.problem(ProblemParentMismatch, id, "root folder has the parentid '%s' (expected '%s')", v.ParentID, consts.BookmarkIDPlaces)
+			}
+			continue
+		}
+
+		claimed := listedBy[id]
+		if len(claimed) > 1 {
+			c.problem(ProblemMultipleParents, id, "record is listed in multiple folders (%v)", claimed)
+		}
+
+		if c.isFolder(v.ParentID) && langext.InArray(v.ParentID, claimed) {
+			c.parent[id] = v.ParentID
+		} else if len(claimed) > 0 {
+			c.parent[id] = claimed[0]
+			c.problem(ProblemParentMismatch, id, "record has the parentid '%s' but is listed in the folder '%s'", v.ParentID, claimed[0])
+		} else if c.isFolder(v.ParentID) {
+			c.parent[id] = v.ParentID
+			c.problem(ProblemParentMismatch, id, "record is not listed in the children of its parent '%s'", v.ParentID)
+		} else {
+			c.parent[id] = consts.BookmarkIDUnfiled
+			if _, ok := c.live[v.ParentID]; ok {
+				c.problem(ProblemParentNotFolder, id, "the parent '%s' is not a folder", v.ParentID)
+			} else if c.deleted[v.ParentID] {
+				c.problem(ProblemOrphan, id, "the parent '%s' is deleted", v.ParentID)
+			} else {
+				c.problem(ProblemOrphan, id, "the parent '%s' does not exist", v.ParentID)
+			}
+		}
+	}
+}
+
+// breakCycles moves records whose ancestors contain the record itself into `unfiled`
+func (c *checker) breakCycles() {
+	for _, id := range c.ids {
+		path := make(map[string]bool)
+		for cur := id; cur != consts.BookmarkIDPlaces; {
+			if path[cur] {
+				c.problem(ProblemCycle, cur, "record is its own ancestor")
+				c.parent[cur] = consts.BookmarkIDUnfiled
+				break
+			}
+			path[cur] = true
+
+			p, ok := c.parent[cur]
+			if !ok {
+				break
+			}
+			cur = p
+		}
+	}
+}
+
+// rebuildChildren rewrites the children of every folder to match the assigned parents (existing order first, then the newly attached records)
+func (c *checker) rebuildChildren() {
+	children := make(map[string][]string)
+	contains := make(map[string]bool)
+
+	for _, fid := range c.ids {
+		if !c.isFolder(fid) {
+			continue
+		}
+		children[fid] = make([]string, 0, len(c.live[fid].Children))
+		for _, cid := range c.live[fid].Children {
+			if c.parent[cid] == fid && !contains[cid] {
+				children[fid] = append(children[fid], cid)
+				contains[cid] = true
+			}
+		}
+	}
+
+	for _, id := range c.ids {
+		p, ok := c.parent[id]
+		if !ok || contains[id] || p == consts.BookmarkIDPlaces {
+			continue
+		}
+		children[p] = append(children[p], id)
+		contains[id] = true
+	}
+
+	for fid, newChildren := range children {
+		old, ok := c.live[fid]
+		if !ok {
+			c.patch(fid).Create = true
+			c.patch(fid).Children = langext.Ptr(newChildren)
+			continue
+		}
+		if !slices.Equal(old.Children, newChildren) {
+			c.patch(fid).Children = langext.Ptr(newChildren)
+		}
+	}
+}
+
+// checkParentFields validates (and repairs) the `parentid` and `parentName` fields
+func (c *checker) checkParentFields() {
+	for _, id := range c.ids {
+		v := c.live[id]
+
+		p, ok := c.parent[id]
+		if !ok {
+			continue
+		}
+
+		if v.ParentID != p {
+			c.patch(id).ParentID = langext.Ptr(p)
+		}
+
+		if p == consts.BookmarkIDPlaces {
+			continue
+		}
+
+		expectedName := p // missing roots are created with their ID as title
+		if parent, ok := c.live[p]; ok {
+			expectedName = parent.Title
+		}
+
+		if v.ParentName != expectedName {
+			if v.ParentID == p {
+				c.problem(ProblemParentName, id, "record has the parentName '%s' (expected '%s')", v.ParentName, expectedName)
+			}
+			c.patch(id).ParentName = langext.Ptr(expectedName)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package bookmarkcheck
+
+import (
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"strings"
+	"testing"
+)
+
+func folder(id string, parent string, title string, children ...string) models.BookmarkRecord {
+	return models.BookmarkRecord{ID: id, Type: models.BookmarkTypeFolder, ParentID: parent, ParentName: parentTitle(parent), Title: title, Children: children}
+}
+
+func bookmark(id string, parent string) models.BookmarkRecord {
+	return models.BookmarkRecord{ID: id, Type: models.BookmarkTypeBookmark, ParentID: parent, ParentName: parentTitle(parent), Title: id, URI: "https://example.com/" + id}
+}
+
+func parentTitle(id string) string {
+	if id == consts.BookmarkIDPlaces {
+		return ""
+	}
+	return strings.ToUpper(id)
+}
+
+func problemTypes(r Result) map[ProblemType]int {
+	result := make(map[ProblemType]int)
+	for _, v := range r.Problems {
+		result[v.Type]++
+	}
+	return result
+}
+
+func TestCheckValidTree(t *testing.T) {
+	r := Check([]models.BookmarkRecord{
+		folder("menu", "places", "MENU", "aaaa", "bbbb"),
+		folder("toolbar", "places", "TOOLBAR"),
+		bookmark("aaaa", "menu"),
+		folder("bbbb", "menu", "BBBB", "cccc"),
+		bookmark("cccc", "bbbb"),
+	})
+
+	if len(r.Problems) != 0 || len(r.Patches) != 0 {
+		t.Errorf("expected no problems, got %v / %v", r.Problems, r.Patches)
+	}
+}
+
+func TestCheckOrphanAndChildren(t *testing.T) {
+	r := Check([]models.BookmarkRecord{
+		folder("menu", "places", "MENU", "aaaa", "aaaa", "gone", "xxxx"),
+		folder("unfiled", "places", "UNFILED"),
+		bookmark("aaaa", "menu"),
+		bookmark("orph", "nope"),
+		{ID: "gone", Deleted: true},
+	})
+
+	types := problemTypes(r)
+	if types[ProblemDuplicateChild] != 1 || types[ProblemDeletedChild] != 1 || types[ProblemMissingChild] != 1 || types[ProblemOrphan] != 1 {
+		t.Errorf("unexpected problems: %v", r.Problems)
+	}
+
+	if p := r.Patches["menu"]; p == nil || p.Children == nil || strings.Join(*p.Children, ",") != "aaaa" {
+		t.Errorf("children of menu were not repaired: %+v", p)
+	}
+	if p := r.Patches["unfiled"]; p == nil || p.Children == nil || strings.Join(*p.Children, ",") != "orph" {
+		t.Errorf("orphan was not attached to unfiled: %+v", p)
+	}
+	if p := r.Patches["orph"]; p == nil || p.ParentID == nil || *p.ParentID != "unfiled" || p.ParentName == nil || *p.ParentName != "UNFILED" {
+		t.Errorf("parent of orphan was not repaired: %+v", p)
+	}
+}
+
+func TestCheckParentMismatch(t *testing.T) {
+	r := Check([]models.BookmarkRecord{
+		folder("menu", "places", "MENU", "aaaa"),
+		folder("toolbar", "places", "TOOLBAR"),
+		bookmark("aaaa", "toolbar"),
+		{ID: "bbbb", Type: models.BookmarkTypeBookmark, ParentID: "menu", ParentName: "wrong"},
+	})
+
+	types := problemTypes(r)
+	if types[ProblemParentMismatch] != 2 {
+		t.Errorf("unexpected problems: %v", r.Problems)
+	}
+
+	if p := r.Patches["aaaa"]; p == nil || p.ParentID == nil || *p.ParentID != "menu" {
+		t.Errorf("parentid was not repaired: %+v", p)
+	}
+	if p := r.Patches["menu"]; p == nil || strings.Join(*p.Children, ",") != "aaaa,bbbb" {
+		t.Errorf("unlisted child was not added: %+v", p)
+	}
+	if _, ok := r.Patches["toolbar"]; ok {
+		t.Errorf("toolbar should not be changed")
+	}
+}
+
+func TestCheckParentName(t *testing.T) {
+	b := bookmark("aaaa", "menu")
+	b.ParentName = "Old Name"
+
+	r := Check([]models.BookmarkRecord{folder("menu", "places", "MENU", "aaaa"), b})
+
+	if types := problemTypes(r); types[ProblemParentName] != 1 || len(r.Problems) != 1 {
+		t.Errorf("unexpected problems: %v", r.Problems)
+	}
+	if p := r.Patches["aaaa"]; p == nil || p.ParentName == nil || *p.ParentName != "MENU" || p.ParentID != nil {
+		t.Errorf("parentName was not repaired: %+v", p)
+	}
+}
+
+func TestCheckCycle(t *testing.T) {
+	r := Check([]models.BookmarkRecord{
+		folder("menu", "places", "MENU"),
+		folder("aaaa", "bbbb", "AAAA", "bbbb"),
+		folder("bbbb", "aaaa", "BBBB", "aaaa"),
+	})
+
+	if types := problemTypes(r); types[ProblemCycle] != 1 {
+		t.Errorf("unexpected problems: %v", r.Problems)
+	}
+
+	p := r.Patches["unfiled"]
+	if p == nil || !p.Create || p.Children == nil || len(*p.Children) != 1 {
+		t.Fatalf("cycle was not moved to a new unfiled root: %+v", p)
+	}
+
+	moved := (*p.Children)[0]
+	if r.Patches[moved] == nil || r.Patches[moved].ParentID == nil || *r.Patches[moved].ParentID != "unfiled" {
+		t.Errorf("parent of %s was not repaired: %+v", moved, r.Patches[moved])
+	}
+}
@@ -0,0 +1,166 @@
+package bookmarkdedupe
+
+import (
+	"ffsyncclient/models"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type KeepStrategy string
+
+const (
+	KeepOldest      KeepStrategy = "oldest"        // the entry with the earliest dateAdded
+	KeepNewest      KeepStrategy = "newest"        // the entry with the latest dateAdded
+	KeepFirstInTree KeepStrategy = "first-in-tree" // the first entry in the (pre-order) tree
+)
+
+func ParseKeepStrategy(v string) (KeepStrategy, error) {
+	switch KeepStrategy(v) {
+	case KeepOldest, KeepNewest, KeepFirstInTree:
+		return KeepStrategy(v), nil
+	default:
+		return "", fmt.Errorf("unknown keep strategy '%s' (possible values: oldest, newest, first-in-tree)", v)
+	}
+}
+
+type Group struct {
+	URL      string // the normalized URL
+	ParentID string // only set if the bookmarks were grouped per folder
+	Entries  []models.BookmarkRecord
+	Keep     int // the index of the entry that survives
+}
+
+// NormalizeURL returns a canonical form of the URL that is used to detect duplicates
+//
+// The scheme and host are lowercased, default ports, empty queries/fragments and trailing slashes are removed.
+// URLs that cannot be parsed are only trimmed.
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Opaque != "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	u.ForceQuery = false
+
+	return u.String()
+}
+
+// Find groups the bookmarks by their normalized URL (and their parent, if perFolder is true)
+//
+// `order` is the (pre-order) position of the entries in the tree, it defines the order of the groups and their entries.
+// Only groups with more than one entry are returned.
+func Find(bookmarks []models.BookmarkRecord, order map[string]int, perFolder bool, keep KeepStrategy) []Group {
+	sorted := make([]models.BookmarkRecord, 0, len(bookmarks))
+	for _, v := range bookmarks {
+		if v.Type == models.BookmarkTypeBookmark && v.URI != "" && !v.Deleted {
+			sorted = append(sorted, v)
+		}
+	}
+
+	// entries that are not in the tree come last
+	sort.SliceStable(sorted, func(i, j int) bool {
+		oi, iok := order[sorted[i].ID]
+		oj, jok := order[sorted[j].ID]
+		if iok != jok {
+			return iok
+		}
+		if iok && oi != oj {
+			return oi < oj
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	groupKeys := make([]string, 0)
+	groupMap := make(map[string]*Group)
+
+	for _, v := range sorted {
+		norm := NormalizeURL(v.URI)
+
+		key := norm
+		parent := ""
+		if perFolder {
+			key = v.ParentID + "\n" + norm
+			parent = v.ParentID
+		}
+
+		if grp, ok := groupMap[key]; ok {
+			grp.Entries = append(grp.Entries, v)
+		} else {
+			groupKeys = append(groupKeys, key)
+			groupMap[key] = &Group{URL: norm, ParentID: parent, Entries: []models.BookmarkRecord{v}}
+		}
+	}
+
+	result := make([]Group, 0)
+	for _, key := range groupKeys {
+		grp := *groupMap[key]
+		if len(grp.Entries) < 2 {
+			continue
+		}
+		grp.Keep = chooseKeep(grp.Entries, keep)
+		result = append(result, grp)
+	}
+
+	return result
+}
+
+// chooseKeep returns the index of the surviving entry, the entries must be in tree order
+// Entries without dateAdded are never preferred by `oldest` and `newest`.
+func chooseKeep(entries []models.BookmarkRecord, keep KeepStrategy) int {
+	best := 0
+	for i, v := range entries {
+		if v.DateAdded == nil {
+			continue
+		}
+		cur := entries[best].DateAdded
+		switch keep {
+		case KeepOldest:
+			if cur == nil || v.DateAdded.Before(*cur) {
+				best = i
+			}
+		case KeepNewest:
+			if cur == nil || v.DateAdded.After(*cur) {
+				best = i
+			}
+		}
+	}
+	return best
+}
+
+// Merge returns the tags and the keyword of the surviving entry after merging the group
+// The tags of all entries are combined (in order, without duplicates), the keyword of the survivor is only replaced if it is empty.
+func Merge(grp Group) ([]string, string) {
+	keep := grp.Entries[grp.Keep]
+
+	tags := make([]string, 0, len(keep.Tags))
+	seen := make(map[string]bool)
+
+	keyword := keep.Keyword
+
+	for _, v := range append([]models.BookmarkRecord{keep}, grp.Entries...) {
+		for _, tag := range v.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		if keyword == "" {
+			keyword = v.Keyword
+		}
+	}
+
+	return tags, keyword
+}
@@ -0,0 +1,105 @@
+package bookmarkdedupe
+
+import (
+	"ffsyncclient/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	cases := map[string]string{
+		"https://Example.COM/":          "https://example.com",
+		"HTTPS://example.com:443/docs/": "https://example.com/docs",
+		"http://example.com:80/a?":      "http://example.com/a",
+		"http://example.com:8080/a#":    "http://example.com:8080/a",
+		"https://example.com/a?q=1#top": "https://example.com/a?q=1#top",
+		"  https://example.com/Path/  ": "https://example.com/Path",
+		"javascript:alert(1)":           "javascript:alert(1)",
+		"place:sort=8&maxResults=10":    "place:sort=8&maxResults=10",
+	}
+
+	for input, expected := range cases {
+		if v := NormalizeURL(input); v != expected {
+			t.Errorf("[%s] expected %q, got %q", input, expected, v)
+		}
+	}
+}
+
+func date(d int) *time.Time {
+	v := time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	return &v
+}
+
+func testBookmarks() ([]models.BookmarkRecord, map[string]int) {
+	bookmarks := []models.BookmarkRecord{
+		{ID: "a", Type: models.BookmarkTypeBookmark, ParentID: "toolbar", URI: "https://go.dev/", DateAdded: date(5), Tags: []string{"go"}},
+		{ID: "b", Type: models.BookmarkTypeBookmark, ParentID: "menu", URI: "https://GO.dev", DateAdded: date(2), Tags: []string{"docs", "go"}, Keyword: "go"},
+		{ID: "c", Type: models.BookmarkTypeBookmark, ParentID: "toolbar", URI: "https://go.dev", DateAdded: date(9)},
+		{ID: "d", Type: models.BookmarkTypeBookmark, ParentID: "toolbar", URI: "https://example.com/"},
+		{ID: "e", Type: models.BookmarkTypeFolder, ParentID: "toolbar", Title: "https://example.com/"},
+		{ID: "f", Type: models.BookmarkTypeBookmark, ParentID: "menu", URI: "https://example.com", Deleted: true},
+	}
+	order := map[string]int{"b": 0, "a": 1, "c": 2, "d": 3, "e": 4}
+	return bookmarks, order
+}
+
+func ids(grp Group) string {
+	r := make([]string, 0, len(grp.Entries))
+	for _, v := range grp.Entries {
+		r = append(r, v.ID)
+	}
+	return strings.Join(r, ",")
+}
+
+func TestFindGlobal(t *testing.T) {
+	bookmarks, order := testBookmarks()
+
+	groups := Find(bookmarks, order, false, KeepOldest)
+	if len(groups) != 1 {
+		t.Fatalf("expected one group, got %d", len(groups))
+	}
+	if ids(groups[0]) != "b,a,c" || groups[0].URL != "https://go.dev" {
+		t.Errorf("unexpected group: %s (%s)", ids(groups[0]), groups[0].URL)
+	}
+	if groups[0].Entries[groups[0].Keep].ID != "b" {
+		t.Errorf("expected b to be kept (oldest), got %s", groups[0].Entries[groups[0].Keep].ID)
+	}
+
+	groups = Find(bookmarks, order, false, KeepNewest)
+	if groups[0].Entries[groups[0].Keep].ID != "c" {
+		t.Errorf("expected c to be kept (newest), got %s", groups[0].Entries[groups[0].Keep].ID)
+	}
+
+	groups = Find(bookmarks, map[string]int{"c": 0, "a": 1, "b": 2}, false, KeepFirstInTree)
+	if groups[0].Entries[groups[0].Keep].ID != "c" {
+		t.Errorf("expected c to be kept (first-in-tree), got %s", groups[0].Entries[groups[0].Keep].ID)
+	}
+}
+
+func TestFindPerFolder(t *testing.T) {
+	bookmarks, order := testBookmarks()
+
+	groups := Find(bookmarks, order, true, KeepOldest)
+	if len(groups) != 1 {
+		t.Fatalf("expected one group, got %d", len(groups))
+	}
+	if ids(groups[0]) != "a,c" || groups[0].ParentID != "toolbar" {
+		t.Errorf("unexpected group: %s (%s)", ids(groups[0]), groups[0].ParentID)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	bookmarks, order := testBookmarks()
+
+	groups := Find(bookmarks, order, false, KeepNewest)
+
+	tags, keyword := Merge(groups[0])
+	if strings.Join(tags, ",") != "docs,go" || keyword != "go" {
+		t.Errorf("unexpected merge result: %v %q", tags, keyword)
+	}
+
+	if _, err := ParseKeepStrategy("largest"); err == nil {
+		t.Error("unknown strategies should be rejected")
+	}
+}
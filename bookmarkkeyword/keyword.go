@@ -0,0 +1,60 @@
+package bookmarkkeyword
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrNoParameter is returned if terms are given for a keyword whose URL has no `%s` placeholder
+var ErrNoParameter = errors.New("the keyword does not take a parameter")
+
+var rexPlaceholder = regexp.MustCompile(`(?i)%s`)
+
+// Expand substitutes the search terms into the URL of a keyword bookmark
+// Follows the firefox rules (BrowserUtils.parseUrlAndPostData):
+//   - `%s` is replaced by the URL-encoded terms (like encodeURIComponent)
+//   - `%S` is replaced by the unescaped terms
+//   - if the URL has no placeholder, terms are not allowed
+func Expand(url string, terms string) (string, error) {
+	if !rexPlaceholder.MatchString(url) {
+		if terms != "" {
+			return "", ErrNoParameter
+		}
+		return url, nil
+	}
+
+	encoded := EncodeURIComponent(terms)
+
+	return rexPlaceholder.ReplaceAllStringFunc(url, func(v string) string {
+		if v == "%S" {
+			return terms
+		}
+		return encoded
+	}), nil
+}
+
+// EncodeURIComponent escapes the string like the javascript function of the same name
+// All characters except `A-Z a-z 0-9 - _ . ! ~ * ' ( )` are percent-encoded (as UTF-8).
+func EncodeURIComponent(v string) string {
+	const hex = "0123456789ABCDEF"
+
+	sb := strings.Builder{}
+	for _, b := range []byte(v) {
+		if isUnreserved(b) {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteByte('%')
+			sb.WriteByte(hex[b>>4])
+			sb.WriteByte(hex[b&0x0F])
+		}
+	}
+	return sb.String()
+}
+
+func isUnreserved(b byte) bool {
+	if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') {
+		return true
+	}
+	return strings.IndexByte("-_.!~*'()", b) >= 0
+}
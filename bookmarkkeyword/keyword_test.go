@@ -0,0 +1,36 @@
+package bookmarkkeyword
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	cases := []struct {
+		url      string
+		terms    string
+		expected string
+	}{
+		{"https://github.com/search?q=%s", "foo bar", "https://github.com/search?q=foo%20bar"},
+		{"https://example.com/%S/%s", "a/b c", "https://example.com/a/b c/a%2Fb%20c"},
+		{"https://example.com/?q=%s", "c++ & ü", "https://example.com/?q=c%2B%2B%20%26%20%C3%BC"},
+		{"https://example.com/?q=%s", "it's (ok)!~*", "https://example.com/?q=it's%20(ok)!~*"},
+		{"https://example.com/?q=%s", "", "https://example.com/?q="},
+		{"https://example.com/", "", "https://example.com/"},
+	}
+
+	for _, c := range cases {
+		v, err := Expand(c.url, c.terms)
+		if err != nil {
+			t.Errorf("[%s | %s] unexpected error: %v", c.url, c.terms, err)
+			continue
+		}
+		if v != c.expected {
+			t.Errorf("[%s | %s] expected %q, got %q", c.url, c.terms, c.expected, v)
+		}
+	}
+
+	if _, err := Expand("https://example.com/", "foo"); !errors.Is(err, ErrNoParameter) {
+		t.Errorf("expected ErrNoParameter, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package bookmarkmirror
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The local representation of the mirrored folder:
+//   - every folder is a directory (named after its title)
+//   - every bookmark is a `.url` (windows internet shortcut) or `.desktop` (freedesktop link) file
+//   - the order of the entries (and the separators) of a folder is stored in the `.order` file of the directory
+//   - the base state of the last run is stored in `.ffsclient-mirror.json` in the mirror directory
+
+const (
+	ExtURL     = ".url"
+	ExtDesktop = ".desktop"
+
+	OrderFileName = ".order"
+	StateFileName = ".ffsclient-mirror.json"
+
+	SeparatorLine = "---" // a separator in the `.order` file
+
+	LocalIDPrefix = "local:" // the (temporary) ID of entries that only exist in the directory
+)
+
+const maxNameLength = 200 // in bytes, without the extension
+
+// ParseFileFormat returns the file extension for the format name (`url` or `desktop`)
+func ParseFileFormat(v string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(v, ".")) {
+	case "url":
+		return ExtURL, nil
+	case "desktop":
+		return ExtDesktop, nil
+	default:
+		return "", fmt.Errorf("unknown file format '%s' (possible values: url, desktop)", v)
+	}
+}
+
+func IsLocalID(id string) bool {
+	return strings.HasPrefix(id, LocalIDPrefix)
+}
+
+// SanitizeName converts a title into a portable file name
+// Characters that are not allowed in file names (on any common OS) are replaced by `_`, leading dots are removed (hidden files are ignored).
+func SanitizeName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, title)
+
+	name = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(name), "."))
+	name = strings.TrimRight(name, ". ")
+
+	if len(name) > maxNameLength {
+		cut := maxNameLength
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = strings.TrimSpace(name[:cut])
+	}
+
+	if name == "" {
+		return "Untitled"
+	}
+	return name
+}
+
+// uniqueName appends a counter to the name until it is not used in the directory (case-insensitive) and marks it as used
+func uniqueName(base string, ext string, used map[string]bool) string {
+	name := base + ext
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// FormatFile returns the content of the bookmark file in the format of the extension
+func FormatFile(ext string, e Entry) []byte {
+	switch ext {
+	case ExtDesktop:
+		return []byte("[Desktop Entry]\nType=Link\nName=" + escapeDesktop(e.Title) + "\nURL=" + escapeDesktop(e.URL) + "\n")
+	default:
+		return []byte("[InternetShortcut]\r\nURL=" + singleLine(e.URL) + "\r\n")
+	}
+}
+
+// ParseFile reads the title and URL from a bookmark file
+// `.url` files contain no title, in this case the title is empty.
+func ParseFile(ext string, data []byte) (string, string) {
+	section := ""
+	values := make(map[string]string)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, exists := values[section+"\n"+key]; !exists {
+			values[section+"\n"+key] = strings.TrimSpace(value)
+		}
+	}
+
+	switch ext {
+	case ExtDesktop:
+		return unescapeDesktop(values["desktop entry\nName"]), unescapeDesktop(values["desktop entry\nURL"])
+	default:
+		return "", values["internetshortcut\nURL"]
+	}
+}
+
+func singleLine(v string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(v)
+}
+
+// escapeDesktop escapes a string value of a desktop entry (see the freedesktop desktop-entry-spec)
+func escapeDesktop(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(v)
+	if strings.HasPrefix(v, " ") {
+		v = `\s` + v[1:]
+	}
+	return v
+}
+
+func unescapeDesktop(v string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 >= len(v) {
+			sb.WriteByte(v[i])
+			continue
+		}
+		i++
+		switch v[i] {
+		case 's':
+			sb.WriteByte(' ')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		default:
+			sb.WriteByte(v[i])
+		}
+	}
+	return sb.String()
+}
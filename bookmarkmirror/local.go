@@ -0,0 +1,565 @@
+package bookmarkmirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"ffsyncclient/models"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// State is the base state of the last run, it is stored in the mirror directory (see StateFileName)
+type State struct {
+	FolderID string                `json:"folder"`
+	Entries  map[string]StateEntry `json:"entries"`
+	Orders   map[string]StateOrder `json:"orders"`
+}
+
+type StateEntry struct {
+	Entry
+	Path  string `json:"path,omitempty"`  // the (slash-separated) path of the file or directory, relative to the mirror directory
+	Mtime int64  `json:"mtime,omitempty"` // the modification time of the file (in unix-nanoseconds) after it was written
+}
+
+type StateOrder struct {
+	Children []string `json:"children"`
+	Mtime    int64    `json:"mtime,omitempty"` // the modification time of the order file (in unix-nanoseconds) after it was written
+}
+
+func NewState(folderID string) State {
+	return State{
+		FolderID: folderID,
+		Entries:  make(map[string]StateEntry),
+		Orders:   make(map[string]StateOrder),
+	}
+}
+
+// LoadState reads the state file of the mirror directory, the second return value is false if it does not exist
+func LoadState(dir string) (State, bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, StateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, false, nil
+	}
+	if err != nil {
+		return State{}, false, err
+	}
+
+	state := NewState("")
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, false, err
+	}
+	return state, true, nil
+}
+
+func SaveState(dir string, state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, StateFileName), append(data, '\n'), 0644)
+}
+
+func (s State) Snapshot() Snapshot {
+	snap := NewSnapshot()
+	for id, e := range s.Entries {
+		snap.Entries[id] = e.Entry
+	}
+	for id, o := range s.Orders {
+		snap.Orders[id] = o.Children
+	}
+	return snap
+}
+
+// Local is the state of the mirror directory
+type Local struct {
+	Snapshot
+	Paths map[string]string // the (slash-separated) paths of the files and directories, relative to the mirror directory
+	Files []string          // all files and directories that are managed by the mirror (incl. the order files)
+
+	unlisted map[string]bool // the entries that are not listed in the order of their folder (and were appended)
+}
+
+// Scan reads the mirror directory, files and directories are mapped to the entries of the base state by their path
+//
+// Files whose modification time has not changed since the last run are not read again.
+// Files and directories that are not in the base state are new entries (with a temporary ID, see LocalIDPrefix),
+// unless they are a renamed (or moved) entry of the base state (see detectRenames).
+// Hidden files and files with other extensions are ignored.
+func Scan(dir string, state State) (Local, error) {
+	byPath := make(map[string]string, len(state.Entries))
+	for id, e := range state.Entries {
+		if e.Path != "" {
+			byPath[e.Path] = id
+		}
+	}
+
+	local := Local{
+		Snapshot: NewSnapshot(),
+		Paths:    make(map[string]string),
+		Files:    make([]string, 0),
+		unlisted: make(map[string]bool),
+	}
+
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		local.Orders[state.FolderID] = make([]string, 0)
+		return local, nil
+	}
+
+	err := scanDir(dir, "", state.FolderID, state, byPath, &local)
+	if err != nil {
+		return Local{}, err
+	}
+
+	detectRenames(state, &local)
+
+	return local, nil
+}
+
+// detectRenames gives renamed (or moved) files and directories the ID of their entry in the base state
+//
+// Otherwise a rename would delete the record and create a new one, which loses the tags, keyword, description and dateAdded of the bookmark
+// (and for .url files the title can only be changed by renaming the file).
+// New bookmarks are paired with deleted entries of the same type and URL, new folders with deleted folders that have the same children
+// (after their children were paired, so that renamed folders are detected bottom-up). Candidates with the same title are preferred.
+// The separators of a paired folder get the IDs of the deleted separators of the base folder (in order).
+func detectRenames(state State, local *Local) {
+	deleted := make([]string, 0)
+	for id, e := range state.Entries {
+		if _, ok := local.Entries[id]; !ok && e.Type != models.BookmarkTypeSeparator {
+			deleted = append(deleted, id)
+		}
+	}
+	if len(deleted) == 0 {
+		return
+	}
+	sort.Strings(deleted)
+
+	added := make([]string, 0)
+	for id, e := range local.Entries {
+		if IsLocalID(id) && e.Type != models.BookmarkTypeSeparator {
+			added = append(added, id)
+		}
+	}
+	sort.Strings(added)
+
+	ids := make(map[string]string) // local-id -> base-id
+	used := make(map[string]bool)
+
+	localChildren := func(folderID string) []string {
+		r := make([]string, 0)
+		for _, id := range local.Orders[folderID] {
+			if local.Entries[id].Type == models.BookmarkTypeSeparator {
+				continue
+			}
+			if v, ok := ids[id]; ok {
+				id = v
+			}
+			r = append(r, id)
+		}
+		sort.Strings(r)
+		return r
+	}
+
+	baseChildren := func(folderID string) []string {
+		r := make([]string, 0)
+		for _, id := range state.Orders[folderID].Children {
+			if state.Entries[id].Type != models.BookmarkTypeSeparator {
+				r = append(r, id)
+			}
+		}
+		sort.Strings(r)
+		return r
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, lid := range added {
+			if _, ok := ids[lid]; ok {
+				continue
+			}
+			le := local.Entries[lid]
+
+			best := ""
+			for _, bid := range deleted {
+				be := state.Entries[bid]
+				if used[bid] || be.Type != le.Type {
+					continue
+				}
+				if le.Type == models.BookmarkTypeFolder && !slices.Equal(localChildren(lid), baseChildren(bid)) {
+					continue
+				}
+				if le.Type != models.BookmarkTypeFolder && be.URL != le.URL {
+					continue
+				}
+				if best == "" || (be.Title == le.Title && state.Entries[best].Title != le.Title) {
+					best = bid
+				}
+			}
+
+			if best != "" {
+				ids[lid] = best
+				used[best] = true
+				changed = true
+			}
+		}
+	}
+
+	for lid, bid := range ids {
+		if local.Entries[lid].Type != models.BookmarkTypeFolder {
+			continue
+		}
+
+		separators := make([]string, 0)
+		for _, id := range state.Orders[bid].Children {
+			if _, ok := local.Entries[id]; !ok && state.Entries[id].Type == models.BookmarkTypeSeparator {
+				separators = append(separators, id)
+			}
+		}
+		for _, id := range local.Orders[lid] {
+			if len(separators) > 0 && IsLocalID(id) && local.Entries[id].Type == models.BookmarkTypeSeparator {
+				ids[id], separators = separators[0], separators[1:]
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	local.Snapshot = Rename(local.Snapshot, ids)
+
+	// the old order does not list the new names, so the renamed entries were appended - move them back to their position in the base order
+	reposition := make(map[string]bool)
+	for lid, bid := range ids {
+		if local.unlisted[lid] {
+			reposition[bid] = true
+		}
+	}
+	for folderID, order := range local.Orders {
+		if !slices.ContainsFunc(order, func(v string) bool { return reposition[v] }) {
+			continue
+		}
+
+		base := state.Orders[folderID].Children
+
+		result := slices.DeleteFunc(slices.Clone(order), func(v string) bool { return reposition[v] })
+		for i, id := range base {
+			if !reposition[id] || !slices.Contains(order, id) {
+				continue
+			}
+			pos := 0
+			for j := i - 1; j >= 0; j-- {
+				if k := slices.Index(result, base[j]); k >= 0 {
+					pos = k + 1
+					break
+				}
+			}
+			result = slices.Insert(result, pos, id)
+		}
+		for _, id := range order {
+			if !slices.Contains(result, id) {
+				result = append(result, id) // moved from another folder
+			}
+		}
+
+		local.Orders[folderID] = result
+	}
+
+	paths := make(map[string]string, len(local.Paths))
+	for id, p := range local.Paths {
+		if v, ok := ids[id]; ok {
+			id = v
+		}
+		paths[id] = p
+	}
+	local.Paths = paths
+}
+
+func scanDir(dir string, rel string, folderID string, state State, byPath map[string]string, local *Local) error {
+	items, err := os.ReadDir(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(items))
+	byName := make(map[string]string, len(items))
+
+	for _, item := range items {
+		name := item.Name()
+		ext := strings.ToLower(path.Ext(name))
+		if strings.HasPrefix(name, ".") || (!item.IsDir() && ext != ExtURL && ext != ExtDesktop) {
+			continue
+		}
+
+		p := path.Join(rel, name)
+
+		id, known := byPath[p]
+		base := state.Entries[id]
+		if known && (base.Type == models.BookmarkTypeFolder) != item.IsDir() {
+			known = false
+		}
+		if !known {
+			id = LocalIDPrefix + p
+		}
+
+		var e Entry
+		if item.IsDir() {
+			e = Entry{Type: models.BookmarkTypeFolder, Title: name}
+			if known {
+				e = base.Entry
+			}
+		} else {
+			info, err := item.Info()
+			if err != nil {
+				return err
+			}
+
+			if known && info.ModTime().UnixNano() == base.Mtime {
+				e = base.Entry
+			} else {
+				data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+				if err != nil {
+					return err
+				}
+				title, url := ParseFile(ext, data)
+				if known {
+					e = Entry{Type: base.Type, Title: base.Title, URL: url}
+				} else {
+					e = Entry{Type: models.BookmarkTypeBookmark, Title: strings.TrimSuffix(name, path.Ext(name)), URL: url}
+				}
+				if title != "" {
+					e.Title = title
+				}
+			}
+		}
+		e.Parent = folderID
+
+		local.Entries[id] = e
+		local.Paths[id] = p
+		local.Files = append(local.Files, p)
+
+		names = append(names, name)
+		byName[name] = id
+
+		if item.IsDir() {
+			if err := scanDir(dir, p, id, state, byPath, local); err != nil {
+				return err
+			}
+		}
+	}
+
+	order := make([]string, 0, len(names))
+	added := make(map[string]bool, len(names))
+
+	baseOrder := state.Orders[folderID]
+	orderPath := path.Join(rel, OrderFileName)
+
+	info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(orderPath)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		local.Files = append(local.Files, orderPath)
+	}
+
+	if err == nil && info.ModTime().UnixNano() != baseOrder.Mtime {
+		// the order file was changed, separators are mapped to the known separators of the folder (in order)
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(orderPath)))
+		if err != nil {
+			return err
+		}
+
+		separators := make([]string, 0)
+		for _, id := range baseOrder.Children {
+			if state.Entries[id].Type == models.BookmarkTypeSeparator {
+				separators = append(separators, id)
+			}
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimRight(line, "\r")
+			if line == SeparatorLine {
+				id := fmt.Sprintf("%s%s#%d", LocalIDPrefix, orderPath, len(order))
+				if len(separators) > 0 {
+					id, separators = separators[0], separators[1:]
+				}
+				local.Entries[id] = Entry{Type: models.BookmarkTypeSeparator, Parent: folderID}
+				order = append(order, id)
+				added[id] = true
+				continue
+			}
+			if id, ok := byName[line]; ok && !added[id] {
+				order = append(order, id)
+				added[id] = true
+			}
+		}
+	} else {
+		// no (or an unchanged) order file, the order of the last run is used
+		for _, id := range baseOrder.Children {
+			if e, ok := state.Entries[id]; ok && e.Type == models.BookmarkTypeSeparator {
+				local.Entries[id] = Entry{Type: models.BookmarkTypeSeparator, Parent: folderID}
+				order = append(order, id)
+				added[id] = true
+				continue
+			}
+			if e, ok := local.Entries[id]; ok && e.Parent == folderID && !added[id] {
+				order = append(order, id)
+				added[id] = true
+			}
+		}
+	}
+
+	// entries that are not listed in the order file are appended (sorted by name)
+	for _, name := range names {
+		if id := byName[name]; !added[id] {
+			order = append(order, id)
+			added[id] = true
+			local.unlisted[id] = true
+		}
+	}
+
+	local.Orders[folderID] = order
+
+	return nil
+}
+
+// Rendered is the content of the mirror directory for a snapshot
+type Rendered struct {
+	Files    map[string][]byte // the content of the files (incl. the order files), by path
+	Dirs     []string          // all directories (parents before their children)
+	Paths    map[string]string // the paths of the rendered files and directories, by entry-id
+	Rendered map[string]bool   // the IDs of all rendered entries (incl. separators)
+}
+
+// Render calculates the files and directories of the snapshot
+//
+// Skipped entries (and their descendants) are not rendered, `reserved` contains the paths that are not used for other entries.
+// New files get the extension `defaultExt`, existing files keep their extension (`exts`).
+func Render(rootID string, s Snapshot, skip func(id string) bool, reserved []string, exts map[string]string, defaultExt string) Rendered {
+	r := Rendered{
+		Files:    make(map[string][]byte),
+		Dirs:     make([]string, 0),
+		Paths:    make(map[string]string),
+		Rendered: make(map[string]bool),
+	}
+
+	renderDir(&r, "", rootID, s, skip, reserved, exts, defaultExt)
+
+	return r
+}
+
+func renderDir(r *Rendered, rel string, folderID string, s Snapshot, skip func(id string) bool, reserved []string, exts map[string]string, defaultExt string) {
+	used := map[string]bool{SeparatorLine: true}
+	for _, p := range reserved {
+		if dir := path.Dir(p); dir == rel || (dir == "." && rel == "") {
+			used[strings.ToLower(path.Base(p))] = true
+		}
+	}
+
+	lines := make([]string, 0, len(s.Orders[folderID]))
+
+	for _, id := range s.Orders[folderID] {
+		e, ok := s.Entries[id]
+		if !ok || skip(id) {
+			continue
+		}
+
+		switch e.Type {
+		case models.BookmarkTypeSeparator:
+			lines = append(lines, SeparatorLine)
+
+		case models.BookmarkTypeFolder:
+			name := uniqueName(SanitizeName(e.Title), "", used)
+			p := path.Join(rel, name)
+
+			r.Dirs = append(r.Dirs, p)
+			r.Paths[id] = p
+			lines = append(lines, name)
+
+			renderDir(r, p, id, s, skip, reserved, exts, defaultExt)
+
+		default:
+			ext := defaultExt
+			if v, ok := exts[id]; ok && (v == ExtURL || v == ExtDesktop) {
+				ext = v
+			}
+			name := uniqueName(SanitizeName(e.Title), ext, used)
+			p := path.Join(rel, name)
+
+			r.Files[p] = FormatFile(ext, e)
+			r.Paths[id] = p
+			lines = append(lines, name)
+		}
+
+		r.Rendered[id] = true
+	}
+
+	buf := bytes.Buffer{}
+	for _, line := range lines {
+		buf.WriteString(line + "\n")
+	}
+	r.Files[path.Join(rel, OrderFileName)] = buf.Bytes()
+}
+
+// NextState returns the base state for the next run, after the rendered files were written to the directory
+// Entries that were not rendered (conflicts) keep their old base state, so that they are reported again.
+func NextState(dir string, prev State, s Snapshot, r Rendered, skip func(id string) bool) (State, error) {
+	next := NewState(prev.FolderID)
+
+	for id, e := range prev.Entries {
+		if r.Rendered[id] {
+			continue
+		}
+		if _, ok := s.Entries[id]; ok || skip(id) {
+			next.Entries[id] = e
+		}
+	}
+
+	mtime := func(p string) (int64, error) {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(p)))
+		if err != nil {
+			return 0, err
+		}
+		return info.ModTime().UnixNano(), nil
+	}
+
+	for id := range r.Rendered {
+		e := StateEntry{Entry: s.Entries[id], Path: r.Paths[id]}
+		if e.Type != models.BookmarkTypeSeparator && e.Type != models.BookmarkTypeFolder {
+			v, err := mtime(e.Path)
+			if err != nil {
+				return State{}, err
+			}
+			e.Mtime = v
+		}
+		next.Entries[id] = e
+	}
+
+	for id, o := range prev.Orders {
+		if _, ok := s.Entries[id]; ok && !r.Rendered[id] {
+			next.Orders[id] = o
+		}
+	}
+
+	folders := map[string]string{prev.FolderID: ""}
+	for id := range r.Rendered {
+		if s.Entries[id].Type == models.BookmarkTypeFolder {
+			folders[id] = r.Paths[id]
+		}
+	}
+	for id, p := range folders {
+		v, err := mtime(path.Join(p, OrderFileName))
+		if err != nil {
+			return State{}, err
+		}
+		next.Orders[id] = StateOrder{Children: s.Orders[id], Mtime: v}
+	}
+
+	return next, nil
+}
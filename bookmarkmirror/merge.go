@@ -0,0 +1,325 @@
+package bookmarkmirror
+
+import (
+	"ffsyncclient/models"
+	"slices"
+	"sort"
+)
+
+// Entry is the mirrored state of a single folder, bookmark (or query) or separator
+type Entry struct {
+	Type   models.BookmarkType `json:"type"`
+	Parent string              `json:"parent"`
+	Title  string              `json:"title,omitempty"`
+	URL    string              `json:"url,omitempty"`
+}
+
+// Snapshot is the state of the mirrored tree on one side (or the common base of both sides)
+// The mirrored folder itself is not part of `Entries`, but its children are listed in `Orders`.
+type Snapshot struct {
+	Entries map[string]Entry
+	Orders  map[string][]string // the children of the folders (by folder-id)
+}
+
+func NewSnapshot() Snapshot {
+	return Snapshot{
+		Entries: make(map[string]Entry),
+		Orders:  make(map[string][]string),
+	}
+}
+
+// Remove deletes the entry and all of its descendants from the snapshot
+func (s Snapshot) Remove(id string) {
+	for _, child := range s.Orders[id] {
+		s.Remove(child)
+	}
+	for cid, e := range s.Entries {
+		if e.Parent == id {
+			s.Remove(cid)
+		}
+	}
+
+	if e, ok := s.Entries[id]; ok {
+		s.Orders[e.Parent] = slices.DeleteFunc(slices.Clone(s.Orders[e.Parent]), func(v string) bool { return v == id })
+	}
+	delete(s.Entries, id)
+	delete(s.Orders, id)
+}
+
+// Rename returns a copy of the snapshot with the entry IDs replaced according to `ids`
+func Rename(s Snapshot, ids map[string]string) Snapshot {
+	rn := func(id string) string {
+		if v, ok := ids[id]; ok {
+			return v
+		}
+		return id
+	}
+
+	r := NewSnapshot()
+	for id, e := range s.Entries {
+		e.Parent = rn(e.Parent)
+		r.Entries[rn(id)] = e
+	}
+	for id, order := range s.Orders {
+		children := make([]string, 0, len(order))
+		for _, v := range order {
+			children = append(children, rn(v))
+		}
+		r.Orders[rn(id)] = children
+	}
+	return r
+}
+
+type Prefer string
+
+const (
+	PreferNone   Prefer = ""       // conflicts are reported and not resolved
+	PreferLocal  Prefer = "local"  // conflicts are resolved with the state of the directory
+	PreferRemote Prefer = "remote" // conflicts are resolved with the state of the sync server
+)
+
+type Conflict struct {
+	ID     string
+	Reason string
+}
+
+type Result struct {
+	Final          Snapshot          // the merged state, that is written to both sides
+	Paired         map[string]string // new local entries that were matched to identical new remote entries (local-id -> remote-id)
+	Conflicts      []Conflict        // unresolved conflicts, these entries are not changed on either side
+	OrderConflicts []string          // folders whose order was changed on both sides (the remote order is used)
+}
+
+func (r Result) Skipped(id string) bool {
+	for _, v := range r.Conflicts {
+		if v.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge reconciles the local and remote state with a three-way merge against the base state of the last run
+//
+// Entries that were only changed on one side take the changed state, entries that were changed on both sides
+// (to different states) are conflicts and are resolved according to `prefer`.
+// New local entries get temporary IDs (see LocalIDPrefix), on the first run they are paired with identical new remote entries.
+func Merge(rootID string, base Snapshot, local Snapshot, remote Snapshot, prefer Prefer) Result {
+	paired := pair(rootID, base, local, remote)
+	local = Rename(local, paired)
+
+	final := NewSnapshot()
+	conflicts := make([]Conflict, 0)
+	orderConflicts := make([]string, 0)
+
+	for _, id := range unionIDs(base, local, remote) {
+		b, l, r := lookup(base, id), lookup(local, id), lookup(remote, id)
+
+		var f *Entry
+		switch {
+		case equal(l, b):
+			f = r
+		case equal(r, b):
+			f = l
+		case equal(l, r):
+			f = r
+		case prefer == PreferLocal:
+			f = l
+		case prefer == PreferRemote:
+			f = r
+		default:
+			f = r
+			conflicts = append(conflicts, Conflict{ID: id, Reason: conflictReason(l, r)})
+		}
+
+		if f != nil {
+			final.Entries[id] = *f
+		}
+	}
+
+	// entries in a folder that was deleted on the other side restore the folder (if possible)
+	for changed := true; changed; {
+		changed = false
+		for _, id := range sortedKeys(final.Entries) {
+			e, ok := final.Entries[id]
+			if !ok || e.Parent == rootID {
+				continue
+			}
+			if _, ok := final.Entries[e.Parent]; ok {
+				continue
+			}
+
+			if p := lookup(remote, e.Parent); p != nil {
+				final.Entries[e.Parent] = *p
+			} else if p := lookup(local, e.Parent); p != nil {
+				final.Entries[e.Parent] = *p
+			} else {
+				final.Remove(id)
+				if !slices.ContainsFunc(conflicts, func(v Conflict) bool { return v.ID == id }) {
+					conflicts = append(conflicts, Conflict{ID: id, Reason: "the parent folder was deleted"})
+				}
+			}
+			changed = true
+		}
+	}
+
+	children := make(map[string][]string)
+	folders := []string{rootID}
+	for _, id := range sortedKeys(final.Entries) {
+		e := final.Entries[id]
+		children[e.Parent] = append(children[e.Parent], id)
+		if e.Type == models.BookmarkTypeFolder {
+			folders = append(folders, id)
+		}
+	}
+
+	for _, folder := range folders {
+		kids := children[folder]
+		oB, oL, oR := base.Orders[folder], local.Orders[folder], remote.Orders[folder]
+
+		localChanged := !slices.Equal(common(oL, oB, kids), common(oB, oL, kids))
+		remoteChanged := !slices.Equal(common(oR, oB, kids), common(oB, oR, kids))
+
+		primary, secondary := oR, oL
+		if localChanged && (!remoteChanged || prefer == PreferLocal) {
+			primary, secondary = oL, oR
+		}
+		if localChanged && remoteChanged && prefer == PreferNone && !slices.Equal(common(oL, oR, kids), common(oR, oL, kids)) {
+			orderConflicts = append(orderConflicts, folder)
+		}
+
+		final.Orders[folder] = mergeOrder(kids, primary, secondary)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].ID < conflicts[j].ID })
+
+	return Result{
+		Final:          final,
+		Paired:         paired,
+		Conflicts:      conflicts,
+		OrderConflicts: orderConflicts,
+	}
+}
+
+// pair matches new local entries to identical new remote entries (same type, title, URL and parent)
+// The tree is walked top-down, so that the entries in paired folders can be paired too.
+func pair(rootID string, base Snapshot, local Snapshot, remote Snapshot) map[string]string {
+	paired := make(map[string]string)
+	used := make(map[string]bool)
+
+	queue := []string{rootID}
+	for len(queue) > 0 {
+		folder := queue[0]
+		queue = queue[1:]
+
+		for _, lid := range local.Orders[folder] {
+			le, ok := local.Entries[lid]
+			if !ok {
+				continue
+			}
+
+			if IsLocalID(lid) {
+				want := le
+				if v, ok := paired[le.Parent]; ok {
+					want.Parent = v
+				}
+				for _, rid := range remote.Orders[want.Parent] {
+					if _, inBase := base.Entries[rid]; inBase || used[rid] {
+						continue
+					}
+					if re, ok := remote.Entries[rid]; ok && re == want {
+						paired[lid] = rid
+						used[rid] = true
+						break
+					}
+				}
+			}
+
+			if le.Type == models.BookmarkTypeFolder {
+				queue = append(queue, lid)
+			}
+		}
+	}
+
+	return paired
+}
+
+// mergeOrder returns the children in the order of `primary`, children that are not listed there are appended in the order of `secondary`
+func mergeOrder(children []string, primary []string, secondary []string) []string {
+	isChild := make(map[string]bool, len(children))
+	for _, v := range children {
+		isChild[v] = true
+	}
+
+	result := make([]string, 0, len(children))
+	added := make(map[string]bool, len(children))
+	for _, order := range [][]string{primary, secondary, children} {
+		for _, v := range order {
+			if isChild[v] && !added[v] {
+				added[v] = true
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// common returns the entries of `order` that are also in `other` and in `children`
+func common(order []string, other []string, children []string) []string {
+	result := make([]string, 0, len(order))
+	for _, v := range order {
+		if slices.Contains(other, v) && slices.Contains(children, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func conflictReason(l *Entry, r *Entry) string {
+	switch {
+	case l == nil:
+		return "deleted locally, but changed remotely"
+	case r == nil:
+		return "changed locally, but deleted remotely"
+	default:
+		return "changed locally and remotely"
+	}
+}
+
+func lookup(s Snapshot, id string) *Entry {
+	if e, ok := s.Entries[id]; ok {
+		return &e
+	}
+	return nil
+}
+
+func equal(a *Entry, b *Entry) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func unionIDs(snapshots ...Snapshot) []string {
+	ids := make(map[string]bool)
+	for _, s := range snapshots {
+		for id := range s.Entries {
+			ids[id] = true
+		}
+	}
+	result := make([]string, 0, len(ids))
+	for id := range ids {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortedKeys(m map[string]Entry) []string {
+	result := make([]string, 0, len(m))
+	for id := range m {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
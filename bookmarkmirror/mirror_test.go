@@ -0,0 +1,357 @@
+package bookmarkmirror
+
+import (
+	"ffsyncclient/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func bm(parent string, title string, url string) Entry {
+	return Entry{Type: models.BookmarkTypeBookmark, Parent: parent, Title: title, URL: url}
+}
+
+func folder(parent string, title string) Entry {
+	return Entry{Type: models.BookmarkTypeFolder, Parent: parent, Title: title}
+}
+
+func testBase() Snapshot {
+	s := NewSnapshot()
+	s.Entries["a"] = bm("root", "Go", "https://go.dev")
+	s.Entries["b"] = bm("root", "Rust", "https://rust-lang.org")
+	s.Entries["f"] = folder("root", "Docs")
+	s.Entries["c"] = bm("f", "MDN", "https://developer.mozilla.org")
+	s.Entries["s"] = Entry{Type: models.BookmarkTypeSeparator, Parent: "root"}
+	s.Orders["root"] = []string{"a", "s", "b", "f"}
+	s.Orders["f"] = []string{"c"}
+	return s
+}
+
+func clone(s Snapshot) Snapshot {
+	return Rename(s, nil)
+}
+
+func TestSanitizeName(t *testing.T) {
+	cases := map[string]string{
+		"Go docs":                "Go docs",
+		"A/B: C?":                "A_B_ C_",
+		"  .hidden  ":            "hidden",
+		"dots...":                "dots",
+		"":                       "Untitled",
+		"line\nbreak":            "line_break",
+		strings.Repeat("ä", 150): strings.Repeat("ä", 100),
+	}
+
+	for input, expected := range cases {
+		if v := SanitizeName(input); v != expected {
+			t.Errorf("[%q] expected %q, got %q", input, expected, v)
+		}
+	}
+
+	used := map[string]bool{}
+	if v := uniqueName("Go", ".url", used); v != "Go.url" {
+		t.Errorf("unexpected name %q", v)
+	}
+	if v := uniqueName("go", ".url", used); v != "go (2).url" {
+		t.Errorf("unexpected name %q", v)
+	}
+}
+
+func TestFileFormats(t *testing.T) {
+	e := bm("root", " Go\\docs", "https://go.dev/doc/?q=a b")
+
+	title, url := ParseFile(ExtDesktop, FormatFile(ExtDesktop, e))
+	if title != e.Title || url != e.URL {
+		t.Errorf("desktop roundtrip failed: %q %q", title, url)
+	}
+
+	title, url = ParseFile(ExtURL, FormatFile(ExtURL, e))
+	if title != "" || url != e.URL {
+		t.Errorf("url roundtrip failed: %q %q", title, url)
+	}
+
+	_, url = ParseFile(ExtURL, []byte("[DEFAULT]\nBASEURL=https://other\n[InternetShortcut]\nIconIndex=0\nURL=https://example.com\n"))
+	if url != "https://example.com" {
+		t.Errorf("unexpected url %q", url)
+	}
+}
+
+func TestMergeOneSided(t *testing.T) {
+	base := testBase()
+
+	local := clone(base)
+	local.Entries["a"] = bm("root", "Go", "https://go.dev/doc")
+	local.Remove("c")
+	local.Entries["local:Docs/New.url"] = bm("f", "New", "https://example.com")
+	local.Orders["f"] = []string{"local:Docs/New.url"}
+
+	remote := clone(base)
+	remote.Entries["b"] = bm("root", "Rust lang", "https://rust-lang.org")
+	remote.Entries["d"] = bm("root", "Zig", "https://ziglang.org")
+	remote.Orders["root"] = []string{"d", "a", "s", "b", "f"}
+
+	res := Merge("root", base, local, remote, PreferNone)
+
+	if len(res.Conflicts) != 0 || len(res.OrderConflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v %v", res.Conflicts, res.OrderConflicts)
+	}
+	if res.Final.Entries["a"].URL != "https://go.dev/doc" {
+		t.Errorf("local change was not merged")
+	}
+	if res.Final.Entries["b"].Title != "Rust lang" {
+		t.Errorf("remote change was not merged")
+	}
+	if _, ok := res.Final.Entries["c"]; ok {
+		t.Errorf("local deletion was not merged")
+	}
+	if _, ok := res.Final.Entries["local:Docs/New.url"]; !ok {
+		t.Errorf("local creation was not merged")
+	}
+	if v := strings.Join(res.Final.Orders["root"], ","); v != "d,a,s,b,f" {
+		t.Errorf("unexpected root order %s", v)
+	}
+	if v := strings.Join(res.Final.Orders["f"], ","); v != "local:Docs/New.url" {
+		t.Errorf("unexpected folder order %s", v)
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	base := testBase()
+
+	local := clone(base)
+	local.Entries["a"] = bm("root", "Go", "https://go.dev/local")
+	local.Remove("b")
+	local.Orders["root"] = []string{"f", "a", "s"}
+
+	remote := clone(base)
+	remote.Entries["a"] = bm("root", "Go", "https://go.dev/remote")
+	remote.Entries["b"] = bm("root", "Rust", "https://rust-lang.org/remote")
+	remote.Orders["root"] = []string{"b", "a", "s", "f"}
+
+	res := Merge("root", base, local, remote, PreferNone)
+
+	if len(res.Conflicts) != 2 || !res.Skipped("a") || !res.Skipped("b") {
+		t.Fatalf("unexpected conflicts: %v", res.Conflicts)
+	}
+	if res.Final.Entries["a"].URL != "https://go.dev/remote" {
+		t.Errorf("unresolved conflict should keep the remote state")
+	}
+	if !slices.Equal(res.OrderConflicts, []string{"root"}) {
+		t.Errorf("unexpected order conflicts: %v", res.OrderConflicts)
+	}
+
+	res = Merge("root", base, local, remote, PreferLocal)
+
+	if len(res.Conflicts) != 0 || len(res.OrderConflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v %v", res.Conflicts, res.OrderConflicts)
+	}
+	if res.Final.Entries["a"].URL != "https://go.dev/local" {
+		t.Errorf("conflict was not resolved with the local state")
+	}
+	if _, ok := res.Final.Entries["b"]; ok {
+		t.Errorf("conflict was not resolved with the local state")
+	}
+	if v := strings.Join(res.Final.Orders["root"], ","); v != "f,a,s" {
+		t.Errorf("unexpected root order %s", v)
+	}
+}
+
+func TestMergeDeletedParent(t *testing.T) {
+	base := testBase()
+
+	local := clone(base)
+	local.Remove("f")
+
+	remote := clone(base)
+	remote.Entries["c"] = bm("f", "MDN Web Docs", "https://developer.mozilla.org")
+
+	res := Merge("root", base, local, remote, PreferRemote)
+
+	if _, ok := res.Final.Entries["f"]; !ok {
+		t.Errorf("the folder of the changed entry should be restored")
+	}
+	if res.Final.Entries["c"].Title != "MDN Web Docs" {
+		t.Errorf("the remote change should be kept")
+	}
+}
+
+func TestMergePairsFirstRun(t *testing.T) {
+	remote := testBase()
+
+	local := NewSnapshot()
+	local.Entries["local:Docs"] = folder("root", "Docs")
+	local.Entries["local:Docs/MDN.url"] = bm("local:Docs", "MDN", "https://developer.mozilla.org")
+	local.Entries["local:Go.url"] = bm("root", "Go", "https://go.dev")
+	local.Entries["local:Other.url"] = bm("root", "Other", "https://example.com")
+	local.Orders["root"] = []string{"local:Docs", "local:Go.url", "local:Other.url"}
+	local.Orders["local:Docs"] = []string{"local:Docs/MDN.url"}
+
+	res := Merge("root", NewSnapshot(), local, remote, PreferNone)
+
+	if res.Paired["local:Docs"] != "f" || res.Paired["local:Docs/MDN.url"] != "c" || res.Paired["local:Go.url"] != "a" {
+		t.Errorf("unexpected pairs: %v", res.Paired)
+	}
+	if _, ok := res.Paired["local:Other.url"]; ok {
+		t.Errorf("unexpected pairs: %v", res.Paired)
+	}
+	if len(res.Final.Entries) != len(remote.Entries)+1 || len(res.Conflicts) != 0 {
+		t.Errorf("unexpected result: %v %v", res.Final.Entries, res.Conflicts)
+	}
+}
+
+func TestRenderScanRoundtrip(t *testing.T) {
+	dir := t.TempDir()
+
+	final := testBase()
+	final.Entries["x"] = bm("root", "Go", "https://go.dev/doc")
+	final.Orders["root"] = append(final.Orders["root"], "x")
+
+	skip := func(id string) bool { return false }
+
+	rendered := Render("root", final, skip, nil, map[string]string{"b": ExtDesktop}, ExtURL)
+
+	if v := strings.Join([]string{rendered.Paths["a"], rendered.Paths["b"], rendered.Paths["c"], rendered.Paths["x"]}, "|"); v != "Go.url|Rust.desktop|Docs/MDN.url|Go (2).url" {
+		t.Fatalf("unexpected paths %s", v)
+	}
+	if v := string(rendered.Files[OrderFileName]); v != "Go.url\n---\nRust.desktop\nDocs\nGo (2).url\n" {
+		t.Fatalf("unexpected order file %q", v)
+	}
+
+	for _, p := range rendered.Dirs {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(p)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for p, data := range rendered.Files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(p)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state, err := NextState(dir, NewState("root"), final, rendered, skip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unchanged directory
+	local, err := Scan(dir, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := Merge("root", state.Snapshot(), local.Snapshot, final, PreferNone)
+	if len(res.Conflicts) != 0 || len(res.Final.Entries) != len(final.Entries) || !slices.Equal(res.Final.Orders["root"], final.Orders["root"]) {
+		t.Fatalf("unexpected changes after roundtrip: %v", res.Final)
+	}
+
+	// local changes: new file, edited file, changed order
+	later := time.Now().Add(time.Minute)
+	write := func(p string, data string) {
+		if err := os.WriteFile(filepath.Join(dir, p), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filepath.Join(dir, p), later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Docs/New.url", "[InternetShortcut]\nURL=https://example.com\n")
+	write("Rust.desktop", "[Desktop Entry]\nType=Link\nName=Rust lang\nURL=https://rust-lang.org\n")
+	write(OrderFileName, "Docs\nRust.desktop\n---\nGo.url\n")
+
+	local, err = Scan(dir, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res = Merge("root", state.Snapshot(), local.Snapshot, final, PreferNone)
+
+	if len(res.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", res.Conflicts)
+	}
+	if res.Final.Entries["b"].Title != "Rust lang" {
+		t.Errorf("title change was not detected")
+	}
+	if e, ok := res.Final.Entries["local:Docs/New.url"]; !ok || e.Parent != "f" || e.Title != "New" || e.URL != "https://example.com" {
+		t.Errorf("new file was not detected: %v", res.Final.Entries)
+	}
+	if v := strings.Join(res.Final.Orders["root"], ","); v != "f,b,s,a,x" {
+		t.Errorf("unexpected root order %s", v)
+	}
+}
+
+func TestScanRenames(t *testing.T) {
+	dir := t.TempDir()
+
+	final := testBase()
+	final.Entries["g"] = folder("f", "Web")
+	final.Entries["w"] = bm("g", "W3C", "https://w3.org")
+	final.Entries["t"] = Entry{Type: models.BookmarkTypeSeparator, Parent: "f"}
+	final.Orders["f"] = []string{"c", "t", "g"}
+	final.Orders["g"] = []string{"w"}
+
+	skip := func(id string) bool { return false }
+
+	rendered := Render("root", final, skip, nil, nil, ExtURL)
+	for _, p := range rendered.Dirs {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(p)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for p, data := range rendered.Files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(p)), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state, err := NextState(dir, NewState("root"), final, rendered, skip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rename a file (changes the title of the .url file) and a directory (with a sub-directory)
+	if err := os.Rename(filepath.Join(dir, "Go.url"), filepath.Join(dir, "Go docs.url")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "Docs"), filepath.Join(dir, "Reference")); err != nil {
+		t.Fatal(err)
+	}
+
+	local, err := Scan(dir, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for id := range local.Entries {
+		if IsLocalID(id) {
+			t.Errorf("renamed entry %s (%s) was not detected", id, local.Paths[id])
+		}
+	}
+	if local.Paths["a"] != "Go docs.url" || local.Paths["f"] != "Reference" || local.Paths["w"] != "Reference/Web/W3C.url" {
+		t.Errorf("unexpected paths: %v", local.Paths)
+	}
+
+	res := Merge("root", state.Snapshot(), local.Snapshot, final, PreferNone)
+
+	if len(res.Conflicts) != 0 || len(res.OrderConflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v %v", res.Conflicts, res.OrderConflicts)
+	}
+	if len(res.Final.Entries) != len(final.Entries) {
+		t.Errorf("renamed entries were deleted or created: %v", res.Final.Entries)
+	}
+	if e := res.Final.Entries["a"]; e.Title != "Go docs" || e.URL != "https://go.dev" {
+		t.Errorf("file rename was not merged as a title change: %v", e)
+	}
+	if e := res.Final.Entries["f"]; e.Title != "Reference" {
+		t.Errorf("directory rename was not merged as a title change: %v", e)
+	}
+	if res.Final.Entries["c"].Parent != "f" || res.Final.Entries["w"].Parent != "g" {
+		t.Errorf("the children of the renamed directory were moved")
+	}
+	if v := strings.Join(res.Final.Orders["root"], ","); v != "a,s,b,f" {
+		t.Errorf("unexpected root order %s", v)
+	}
+	if v := strings.Join(res.Final.Orders["f"], ","); v != "c,t,g" {
+		t.Errorf("unexpected folder order %s", v)
+	}
+}
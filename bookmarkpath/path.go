@@ -0,0 +1,136 @@
+package bookmarkpath
+
+import (
+	"errors"
+	"ffsyncclient/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Human-readable bookmark paths, e.g. `toolbar/Dev/Go docs`
+// The first segment is the root folder, all other segments are titles.
+// A `/` in a title is escaped as `\/`, a backslash as `\\`.
+
+var (
+	ErrAmbiguous = errors.New("ambiguous bookmark path")
+	ErrNotFolder = errors.New("path segment is not a folder")
+)
+
+// IsPath returns true if v contains an (unescaped) `/`
+func IsPath(v string) bool {
+	escaped := false
+	for _, c := range v {
+		if escaped {
+			escaped = false
+			continue
+		}
+		if c == '\\' {
+			escaped = true
+			continue
+		}
+		if c == '/' {
+			return true
+		}
+	}
+	return false
+}
+
+// Split splits a path into its (unescaped) segments, a trailing `/` is ignored
+func Split(v string) ([]string, error) {
+	segments := make([]string, 0)
+
+	current := strings.Builder{}
+	escaped := false
+	for _, c := range v {
+		if escaped {
+			if c != '/' && c != '\\' {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(c)
+			escaped = false
+			continue
+		}
+		if c == '\\' {
+			escaped = true
+			continue
+		}
+		if c == '/' {
+			segments = append(segments, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(c)
+	}
+	if escaped {
+		current.WriteRune('\\')
+	}
+	if current.Len() > 0 || len(segments) == 0 {
+		segments = append(segments, current.String())
+	}
+
+	for i, s := range segments {
+		if s == "" {
+			return nil, fmt.Errorf("empty segment at position %d in bookmark path '%s'", i, v)
+		}
+	}
+
+	return segments, nil
+}
+
+// Escape escapes a title to be used as a path segment
+func Escape(title string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(title, `\`, `\\`), `/`, `\/`)
+}
+
+// Join creates a path from (unescaped) segments
+func Join(segments ...string) string {
+	escaped := make([]string, 0, len(segments))
+	for _, v := range segments {
+		escaped = append(escaped, Escape(v))
+	}
+	return strings.Join(escaped, "/")
+}
+
+// Resolve walks the titles from the folder rootID downwards.
+// Returns the ID of the deepest found entry and the titles that were not found (empty if the full path exists).
+// Returns ErrAmbiguous if a folder contains multiple matching entries and ErrNotFolder if an intermediate entry is not a folder.
+func Resolve(bookmarks map[string]models.BookmarkRecord, rootID string, titles []string) (string, []string, error) {
+	current := rootID
+
+	for i, title := range titles {
+		last := i == len(titles)-1
+
+		parent, ok := bookmarks[current]
+		if !ok {
+			return current, titles[i:], nil
+		}
+		if parent.Type != models.BookmarkTypeFolder {
+			return "", nil, fmt.Errorf("%w: '%s' (%s)", ErrNotFolder, parent.Title, parent.ID)
+		}
+
+		matches := make([]string, 0, 1)
+		for _, cid := range parent.Children {
+			child, ok := bookmarks[cid]
+			if !ok || child.Deleted || child.Title != title {
+				continue
+			}
+			if !last && child.Type != models.BookmarkTypeFolder {
+				continue
+			}
+			matches = append(matches, cid)
+		}
+
+		if len(matches) == 0 {
+			return current, titles[i:], nil
+		}
+		if len(matches) > 1 {
+			sort.Strings(matches)
+			return "", nil, fmt.Errorf("%w: '%s' matches the records [%s]", ErrAmbiguous, title, strings.Join(matches, ", "))
+		}
+
+		current = matches[0]
+	}
+
+	return current, []string{}, nil
+}
@@ -0,0 +1,84 @@
+package bookmarkpath
+
+import (
+	"errors"
+	"ffsyncclient/models"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	cases := map[string]string{
+		`toolbar/Dev/Go docs`: `toolbar|Dev|Go docs`,
+		`menu/Work/`:          `menu|Work`,
+		`menu/A\/B`:           `menu|A/B`,
+		`menu/C:\\Temp\x`:     `menu|C:\Temp\x`,
+		`toolbar`:             `toolbar`,
+	}
+
+	for input, expected := range cases {
+		segments, err := Split(input)
+		if err != nil {
+			t.Errorf("[%s] unexpected error: %v", input, err)
+			continue
+		}
+		if strings.Join(segments, "|") != expected {
+			t.Errorf("[%s] expected %q, got %q", input, expected, strings.Join(segments, "|"))
+		}
+	}
+
+	if _, err := Split("menu//Work"); err == nil {
+		t.Error("Split should fail for empty segments")
+	}
+}
+
+func TestIsPathAndJoin(t *testing.T) {
+	if !IsPath("menu/Work") || IsPath("abcdefABCDEF") || IsPath(`A\/B`) {
+		t.Error("IsPath returned an unexpected result")
+	}
+
+	p := Join("menu", "A/B", `C\D`)
+	if p != `menu/A\/B/C\\D` {
+		t.Errorf("unexpected path: %s", p)
+	}
+
+	segments, err := Split(p)
+	if err != nil || strings.Join(segments, "|") != `menu|A/B|C\D` {
+		t.Errorf("Join/Split roundtrip failed: %v (%v)", segments, err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	bookmarks := map[string]models.BookmarkRecord{
+		"toolbar": {ID: "toolbar", Type: models.BookmarkTypeFolder, Children: []string{"dev", "bm1", "dup1", "dup2"}},
+		"dev":     {ID: "dev", Type: models.BookmarkTypeFolder, Title: "Dev", Children: []string{"godocs"}},
+		"godocs":  {ID: "godocs", Type: models.BookmarkTypeBookmark, Title: "Go docs"},
+		"bm1":     {ID: "bm1", Type: models.BookmarkTypeBookmark, Title: "Work"},
+		"dup1":    {ID: "dup1", Type: models.BookmarkTypeFolder, Title: "Dup"},
+		"dup2":    {ID: "dup2", Type: models.BookmarkTypeBookmark, Title: "Dup"},
+	}
+
+	id, rest, err := Resolve(bookmarks, "toolbar", []string{"Dev", "Go docs"})
+	if err != nil || id != "godocs" || len(rest) != 0 {
+		t.Errorf("unexpected result: %s %v %v", id, rest, err)
+	}
+
+	id, rest, err = Resolve(bookmarks, "toolbar", []string{"Work", "Projects"})
+	if err != nil || id != "toolbar" || strings.Join(rest, "|") != "Work|Projects" {
+		t.Errorf("bookmarks should not be used as intermediate folders: %s %v %v", id, rest, err)
+	}
+
+	id, rest, err = Resolve(bookmarks, "toolbar", []string{"Dup", "X"})
+	if err != nil || id != "dup1" || strings.Join(rest, "|") != "X" {
+		t.Errorf("unexpected result: %s %v %v", id, rest, err)
+	}
+
+	if _, _, err = Resolve(bookmarks, "toolbar", []string{"Dup"}); !errors.Is(err, ErrAmbiguous) {
+		t.Errorf("expected ErrAmbiguous, got %v", err)
+	}
+
+	id, rest, err = Resolve(bookmarks, "mobile", []string{"A"})
+	if err != nil || id != "mobile" || strings.Join(rest, "|") != "A" {
+		t.Errorf("unexpected result for a missing root: %s %v %v", id, rest, err)
+	}
+}
@@ -0,0 +1,210 @@
+package bookmarksearch
+
+import (
+	"errors"
+	"ffsyncclient/models"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type Mode string
+
+const (
+	ModeSubstring Mode = "substring"
+	ModeRegex     Mode = "regex"
+	ModeFuzzy     Mode = "fuzzy"
+)
+
+type Field string
+
+const (
+	FieldTitle       Field = "title"
+	FieldURL         Field = "url"
+	FieldDescription Field = "description"
+	FieldTags        Field = "tags"
+	FieldKeyword     Field = "keyword"
+)
+
+var AllFields = []Field{FieldTitle, FieldURL, FieldDescription, FieldTags, FieldKeyword}
+
+// matches in more relevant fields result in a higher score
+var fieldWeights = map[Field]float64{
+	FieldTitle:       1.0,
+	FieldKeyword:     1.0,
+	FieldTags:        0.8,
+	FieldURL:         0.6,
+	FieldDescription: 0.4,
+}
+
+type Matcher struct {
+	mode   Mode
+	query  string
+	regex  *regexp.Regexp
+	fields []Field
+}
+
+// NewMatcher creates a matcher for the query (substring and fuzzy matching are case-insensitive)
+// If fields is empty, all fields are searched.
+func NewMatcher(mode Mode, query string, fields []Field) (*Matcher, error) {
+	if query == "" {
+		return nil, errors.New("empty search query")
+	}
+
+	if len(fields) == 0 {
+		fields = AllFields
+	}
+	for _, f := range fields {
+		if _, ok := fieldWeights[f]; !ok {
+			return nil, fmt.Errorf("unknown search field '%s'", f)
+		}
+	}
+
+	m := &Matcher{mode: mode, query: strings.ToLower(query), fields: fields}
+
+	switch mode {
+	case ModeSubstring, ModeFuzzy:
+		// nothing to prepare
+	case ModeRegex:
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		m.regex = re
+	default:
+		return nil, fmt.Errorf("unknown search mode '%s'", mode)
+	}
+
+	return m, nil
+}
+
+// Match returns the relevance score of the bookmark (0 = no match) and the fields that matched
+func (m *Matcher) Match(bm models.BookmarkRecord) (float64, []Field) {
+	score := 0.0
+	matched := make([]Field, 0)
+
+	for _, f := range m.fields {
+		best := 0.0
+		for _, v := range fieldValues(bm, f) {
+			best = max(best, m.matchValue(v))
+		}
+		if best > 0 {
+			score += best * fieldWeights[f]
+			matched = append(matched, f)
+		}
+	}
+
+	return score, matched
+}
+
+func fieldValues(bm models.BookmarkRecord, f Field) []string {
+	switch f {
+	case FieldTitle:
+		return []string{bm.Title}
+	case FieldURL:
+		return []string{bm.URI}
+	case FieldDescription:
+		return []string{bm.Description}
+	case FieldTags:
+		return bm.Tags
+	case FieldKeyword:
+		return []string{bm.Keyword}
+	default:
+		return nil
+	}
+}
+
+// matchValue returns a score between 0 (no match) and 1 (exact match)
+func (m *Matcher) matchValue(v string) float64 {
+	if v == "" {
+		return 0
+	}
+
+	switch m.mode {
+	case ModeSubstring:
+		return substringScore(strings.ToLower(v), m.query)
+	case ModeRegex:
+		loc := m.regex.FindStringIndex(v)
+		if loc == nil {
+			return 0
+		}
+		if loc[0] == 0 && loc[1] == len(v) {
+			return 1
+		}
+		return 0.5
+	case ModeFuzzy:
+		return fuzzyScore([]rune(strings.ToLower(v)), []rune(m.query))
+	default:
+		return 0
+	}
+}
+
+func substringScore(v string, query string) float64 {
+	if v == query {
+		return 1
+	}
+
+	idx := strings.Index(v, query)
+	if idx < 0 {
+		return 0
+	}
+
+	if idx == 0 {
+		return 0.8
+	}
+	if isWordStart(v, idx) {
+		return 0.7
+	}
+	return 0.5
+}
+
+func isWordStart(v string, idx int) bool {
+	r := []rune(v[:idx])
+	return len(r) == 0 || !isAlnum(r[len(r)-1])
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// fuzzyScore matches the query as a subsequence of v, consecutive characters and characters at the start of words score higher
+func fuzzyScore(v []rune, query []rune) float64 {
+	const (
+		scoreChar        = 1.0
+		bonusConsecutive = 2.0
+		bonusWordStart   = 1.5
+	)
+
+	score := 0.0
+	qi := 0
+	lastMatch := -2
+
+	for i := 0; i < len(v) && qi < len(query); i++ {
+		if v[i] != query[qi] {
+			continue
+		}
+
+		score += scoreChar
+		if lastMatch == i-1 {
+			score += bonusConsecutive
+		}
+		if i == 0 || !isAlnum(v[i-1]) {
+			score += bonusWordStart
+		}
+
+		lastMatch = i
+		qi++
+	}
+
+	if qi < len(query) {
+		return 0
+	}
+
+	maxScore := float64(len(query)) * (scoreChar + bonusConsecutive + bonusWordStart)
+
+	// prefer short values (a match in `Go` is better than the same match in `Google Docs`)
+	coverage := float64(len(query)) / float64(len(v))
+
+	return min(1, 0.8*score/maxScore+0.2*coverage)
+}
@@ -0,0 +1,99 @@
+package bookmarksearch
+
+import (
+	"ffsyncclient/models"
+	"testing"
+)
+
+var (
+	goDocs  = models.BookmarkRecord{ID: "a", Title: "Go docs", URI: "https://go.dev/doc/", Tags: []string{"golang", "docs"}, Keyword: "go"}
+	google  = models.BookmarkRecord{ID: "b", Title: "Google", URI: "https://www.google.com/"}
+	mongo   = models.BookmarkRecord{ID: "c", Title: "MongoDB Manual", URI: "https://www.mongodb.com/docs/manual/", Description: "database docs"}
+	example = models.BookmarkRecord{ID: "d", Title: "Example", URI: "https://example.com/"}
+)
+
+func TestSubstring(t *testing.T) {
+	m, err := NewMatcher(ModeSubstring, "GO", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sGo, fields := m.Match(goDocs)
+	sGoogle, _ := m.Match(google)
+	sMongo, _ := m.Match(mongo)
+	sExample, _ := m.Match(example)
+
+	if sExample != 0 {
+		t.Errorf("example should not match (score %f)", sExample)
+	}
+	if !(sGo > sGoogle && sGoogle > sMongo && sMongo > 0) {
+		t.Errorf("unexpected ranking: go=%f google=%f mongo=%f", sGo, sGoogle, sMongo)
+	}
+	if len(fields) != 4 {
+		t.Errorf("expected title, url, tags and keyword to match, got %v", fields)
+	}
+}
+
+func TestFields(t *testing.T) {
+	m, err := NewMatcher(ModeSubstring, "docs", []Field{FieldDescription})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, _ := m.Match(goDocs); s != 0 {
+		t.Errorf("title should not be searched (score %f)", s)
+	}
+	if s, fields := m.Match(mongo); s == 0 || len(fields) != 1 || fields[0] != FieldDescription {
+		t.Errorf("description should match: %f %v", s, fields)
+	}
+
+	if _, err := NewMatcher(ModeSubstring, "x", []Field{"color"}); err == nil {
+		t.Error("unknown fields should be rejected")
+	}
+}
+
+func TestRegex(t *testing.T) {
+	m, err := NewMatcher(ModeRegex, `^https://(www\.)?go(ogle)?\.`, []Field{FieldURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, _ := m.Match(goDocs); s == 0 {
+		t.Error("go.dev should match")
+	}
+	if s, _ := m.Match(google); s == 0 {
+		t.Error("google.com should match")
+	}
+	if s, _ := m.Match(mongo); s != 0 {
+		t.Error("mongodb.com should not match")
+	}
+
+	if _, err := NewMatcher(ModeRegex, "(", nil); err == nil {
+		t.Error("invalid regex should be rejected")
+	}
+}
+
+func TestFuzzy(t *testing.T) {
+	m, err := NewMatcher(ModeFuzzy, "mgdb", []Field{FieldTitle})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, _ := m.Match(mongo); s == 0 {
+		t.Error("MongoDB Manual should match")
+	}
+	if s, _ := m.Match(google); s != 0 {
+		t.Error("Google should not match")
+	}
+
+	m, err = NewMatcher(ModeFuzzy, "gd", []Field{FieldTitle})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sGo, _ := m.Match(goDocs)
+	sMongo, _ := m.Match(mongo)
+	if sGo <= sMongo {
+		t.Errorf("word starts should rank higher: go=%f mongo=%f", sGo, sMongo)
+	}
+}
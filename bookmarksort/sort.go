@@ -0,0 +1,141 @@
+package bookmarksort
+
+import (
+	"ffsyncclient/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Key string
+
+const (
+	KeyTitle     Key = "title"
+	KeyURL       Key = "url"
+	KeyDateAdded Key = "dateAdded"
+)
+
+func ParseKey(v string) (Key, error) {
+	for _, k := range []Key{KeyTitle, KeyURL, KeyDateAdded} {
+		if strings.EqualFold(v, string(k)) {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort key '%s' (possible values: title, url, dateAdded)", v)
+}
+
+type Options struct {
+	By           Key
+	Reverse      bool
+	FoldersFirst bool
+}
+
+// Children returns the IDs of the children in sorted order
+//
+// Separators are section boundaries: they keep their position and only the entries between them are sorted.
+// Entries with an unknown type (e.g. children that do not exist) are kept at the end of their section.
+// Folders have no URL, with `url` they are sorted by their title (after all entries with an URL).
+// Entries without dateAdded come last (also with `reverse`), equal entries keep their relative order.
+func Children(children []models.BookmarkRecord, opt Options) []string {
+	result := make([]string, 0, len(children))
+
+	section := make([]models.BookmarkRecord, 0, len(children))
+	flush := func() {
+		sortSection(section, opt)
+		for _, v := range section {
+			result = append(result, v.ID)
+		}
+		section = section[:0]
+	}
+
+	for _, v := range children {
+		if v.Type == models.BookmarkTypeSeparator {
+			flush()
+			result = append(result, v.ID)
+			continue
+		}
+		section = append(section, v)
+	}
+	flush()
+
+	return result
+}
+
+func sortSection(entries []models.BookmarkRecord, opt Options) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+
+		if ka, kb := a.Type == "", b.Type == ""; ka != kb {
+			return kb
+		} else if ka {
+			return false
+		}
+
+		if opt.FoldersFirst {
+			if fa, fb := a.Type == models.BookmarkTypeFolder, b.Type == models.BookmarkTypeFolder; fa != fb {
+				return fa
+			}
+		}
+
+		c := compare(a, b, opt.By)
+		if c == 0 {
+			return false
+		}
+
+		// entries without a value are always last
+		if missing(a, opt.By) != missing(b, opt.By) {
+			return missing(b, opt.By)
+		}
+
+		if opt.Reverse {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+func missing(v models.BookmarkRecord, by Key) bool {
+	switch by {
+	case KeyURL:
+		return v.URI == ""
+	case KeyDateAdded:
+		return v.DateAdded == nil
+	default:
+		return false
+	}
+}
+
+func compare(a models.BookmarkRecord, b models.BookmarkRecord, by Key) int {
+	switch by {
+	case KeyURL:
+		if missing(a, by) != missing(b, by) {
+			return boolCompare(missing(a, by), missing(b, by))
+		}
+		if c := strings.Compare(strings.ToLower(a.URI), strings.ToLower(b.URI)); c != 0 {
+			return c
+		}
+	case KeyDateAdded:
+		if missing(a, by) != missing(b, by) {
+			return boolCompare(missing(a, by), missing(b, by))
+		}
+		if a.DateAdded != nil && !a.DateAdded.Equal(*b.DateAdded) {
+			return a.DateAdded.Compare(*b.DateAdded)
+		}
+		return 0
+	}
+
+	if c := strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Title, b.Title)
+}
+
+func boolCompare(a bool, b bool) int {
+	if a == b {
+		return 0
+	}
+	if a {
+		return 1
+	}
+	return -1
+}
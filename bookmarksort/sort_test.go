@@ -0,0 +1,55 @@
+package bookmarksort
+
+import (
+	"ffsyncclient/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(d int) *time.Time {
+	v := time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	return &v
+}
+
+func testChildren() []models.BookmarkRecord {
+	return []models.BookmarkRecord{
+		{ID: "c", Type: models.BookmarkTypeBookmark, Title: "charlie", URI: "https://a.example", DateAdded: date(3)},
+		{ID: "f", Type: models.BookmarkTypeFolder, Title: "Bravo", DateAdded: date(9)},
+		{ID: "a", Type: models.BookmarkTypeBookmark, Title: "Alpha", URI: "https://c.example"},
+		{ID: "s", Type: models.BookmarkTypeSeparator},
+		{ID: "x", Type: ""},
+		{ID: "e", Type: models.BookmarkTypeBookmark, Title: "echo", URI: "https://b.example", DateAdded: date(1)},
+		{ID: "d", Type: models.BookmarkTypeBookmark, Title: "Delta", URI: "https://a.example", DateAdded: date(2)},
+	}
+}
+
+func TestSortSections(t *testing.T) {
+	cases := []struct {
+		opt      Options
+		expected string
+	}{
+		{Options{By: KeyTitle}, "a,f,c,s,d,e,x"},
+		{Options{By: KeyTitle, Reverse: true}, "c,f,a,s,e,d,x"},
+		{Options{By: KeyTitle, FoldersFirst: true}, "f,a,c,s,d,e,x"},
+		{Options{By: KeyURL}, "c,a,f,s,d,e,x"},
+		{Options{By: KeyURL, Reverse: true}, "a,c,f,s,e,d,x"},
+		{Options{By: KeyDateAdded}, "c,f,a,s,e,d,x"},
+		{Options{By: KeyDateAdded, Reverse: true}, "f,c,a,s,d,e,x"},
+	}
+
+	for _, tc := range cases {
+		if v := strings.Join(Children(testChildren(), tc.opt), ","); v != tc.expected {
+			t.Errorf("[%+v] expected %s, got %s", tc.opt, tc.expected, v)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if k, err := ParseKey("dateadded"); err != nil || k != KeyDateAdded {
+		t.Errorf("unexpected result %v %v", k, err)
+	}
+	if _, err := ParseKey("visits"); err == nil {
+		t.Errorf("expected error")
+	}
+}
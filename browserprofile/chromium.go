@@ -0,0 +1,147 @@
+package browserprofile
+
+import (
+	"encoding/json"
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"ffsyncclient/sqlitefmt"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"os"
+	"strconv"
+)
+
+// chromiumRoots maps the root folders of the Bookmarks file to the sync-IDs
+var chromiumRoots = []struct {
+	Key    string
+	SyncID string
+}{
+	{"bookmark_bar", consts.BookmarkIDToolbar},
+	{"other", consts.BookmarkIDUnfiled},
+	{"synced", consts.BookmarkIDMobile},
+}
+
+type chromiumBookmarkNode struct {
+	Type      string                  `json:"type"`
+	Name      string                  `json:"name"`
+	URL       string                  `json:"url"`
+	DateAdded string                  `json:"date_added"` // microseconds since 1601-01-01, as string
+	Children  []*chromiumBookmarkNode `json:"children"`
+}
+
+type chromiumBookmarks struct {
+	Roots map[string]*chromiumBookmarkNode `json:"roots"`
+}
+
+// chromiumTransition converts the core type of a chromium page transition (the lowest byte) to the firefox transition type
+func chromiumTransition(v int64) models.HistoryTransitionType {
+	switch v & 0xFF {
+	case 1, 5, 9, 10: // TYPED, GENERATED, KEYWORD, KEYWORD_GENERATED
+		return models.HistoryTransitionTypeTyped
+	case 2: // AUTO_BOOKMARK
+		return models.HistoryTransitionTypeBookmark
+	case 3: // AUTO_SUBFRAME
+		return models.HistoryTransitionTypeEmbed
+	case 4: // MANUAL_SUBFRAME
+		return models.HistoryTransitionTypeFramedLink
+	case 8: // RELOAD
+		return models.HistoryTransitionTypeReload
+	default: // LINK, AUTO_TOPLEVEL, FORM_SUBMIT
+		return models.HistoryTransitionTypeLink
+	}
+}
+
+func readChromiumHistory(path string) ([]models.HistoryRecord, error) {
+	db, err := sqlitefmt.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	urls, err := db.Rows("urls")
+	if err != nil {
+		return nil, err
+	}
+	visits, err := db.Rows("visits")
+	if err != nil {
+		return nil, err
+	}
+
+	urlVisits := make(map[int64][]models.HistoryVisit)
+	for _, v := range visits {
+		urlVisits[v.Int("url")] = append(urlVisits[v.Int("url")], models.HistoryVisit{
+			TransitionType: chromiumTransition(v.Int("transition")),
+			VisitDate:      chromiumTime(v.Int("visit_time")),
+		})
+	}
+
+	result := make([]models.HistoryRecord, 0)
+	for _, u := range urls {
+		uri := u.String("url")
+		if len(urlVisits[u.Int("id")]) == 0 || !IsSyncableURL(uri) {
+			continue
+		}
+
+		result = append(result, models.HistoryRecord{
+			URI:    uri,
+			Title:  u.String("title"),
+			Visits: MergeVisits(nil, urlVisits[u.Int("id")]),
+		})
+	}
+
+	return result, nil
+}
+
+func readChromiumBookmarks(path string) ([]*models.BookmarkTreeRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file chromiumBookmarks
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	result := make([]*models.BookmarkTreeRecord, 0, len(chromiumRoots))
+	for _, root := range chromiumRoots {
+		node, ok := file.Roots[root.Key]
+		if !ok || node == nil {
+			continue
+		}
+
+		item := chromiumBookmarkItem(node)
+		item.ID = root.SyncID
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+func chromiumBookmarkItem(node *chromiumBookmarkNode) *models.BookmarkTreeRecord {
+	item := &models.BookmarkTreeRecord{
+		BookmarkRecord: models.BookmarkRecord{
+			Title: node.Name,
+		},
+		ResolvedChildren: make([]*models.BookmarkTreeRecord, 0),
+	}
+
+	if v, err := strconv.ParseInt(node.DateAdded, 10, 64); err == nil && v > 0 {
+		item.DateAdded = langext.Ptr(chromiumTime(v))
+	}
+
+	if node.Type == "folder" {
+		item.Type = models.BookmarkTypeFolder
+		for _, child := range node.Children {
+			if child != nil {
+				item.ResolvedChildren = append(item.ResolvedChildren, chromiumBookmarkItem(child))
+			}
+		}
+	} else {
+		item.Type = models.BookmarkTypeBookmark
+		item.URI = node.URL
+		item.Tags = make([]string, 0)
+	}
+
+	return item
+}
@@ -0,0 +1,208 @@
+package browserprofile
+
+import (
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"ffsyncclient/sqlitefmt"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"sort"
+	"time"
+)
+
+const (
+	firefoxTypeBookmark  = 1
+	firefoxTypeFolder    = 2
+	firefoxTypeSeparator = 3
+
+	firefoxGUIDTags = "tags________"
+)
+
+// firefoxRoots maps the places-GUIDs of the root folders to their sync-IDs
+var firefoxRoots = map[string]string{
+	"menu________": consts.BookmarkIDMenu,
+	"toolbar_____": consts.BookmarkIDToolbar,
+	"unfiled_____": consts.BookmarkIDUnfiled,
+	"mobile______": consts.BookmarkIDMobile,
+}
+
+func readFirefoxHistory(path string) ([]models.HistoryRecord, error) {
+	db, err := sqlitefmt.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	places, err := db.Rows("moz_places")
+	if err != nil {
+		return nil, err
+	}
+	visits, err := db.Rows("moz_historyvisits")
+	if err != nil {
+		return nil, err
+	}
+
+	placeVisits := make(map[int64][]models.HistoryVisit)
+	for _, v := range visits {
+		transition := models.HistoryTransitionType(v.Int("visit_type"))
+		if transition < models.HistoryTransitionTypeLink || transition > models.HistoryTransitionTypeReload {
+			transition = models.HistoryTransitionTypeLink
+		}
+		placeVisits[v.Int("place_id")] = append(placeVisits[v.Int("place_id")], models.HistoryVisit{
+			TransitionType: transition,
+			VisitDate:      time.UnixMicro(v.Int("visit_date")), // PRTime, microseconds
+		})
+	}
+
+	result := make([]models.HistoryRecord, 0)
+	for _, p := range places {
+		uri := p.String("url")
+		if len(placeVisits[p.Int("id")]) == 0 || !IsSyncableURL(uri) {
+			continue
+		}
+
+		id := p.String("guid")
+		if len(id) != 12 {
+			id = ""
+		}
+
+		result = append(result, models.HistoryRecord{
+			ID:     id,
+			URI:    uri,
+			Title:  p.String("title"),
+			Visits: MergeVisits(nil, placeVisits[p.Int("id")]),
+		})
+	}
+
+	return result, nil
+}
+
+// firefoxBookmarks contains the lookup tables to build the bookmark tree
+type firefoxBookmarks struct {
+	children        map[int64][]sqlitefmt.Row
+	urls            map[int64]string
+	tags            map[int64][]string
+	keywordsByPlace map[int64]string
+	keywordsByID    map[int64]string // old profiles (before firefox 39) reference the keyword from the bookmark (moz_bookmarks.keyword_id)
+	visited         map[int64]bool
+}
+
+func readFirefoxBookmarks(path string) ([]*models.BookmarkTreeRecord, error) {
+	db, err := sqlitefmt.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	bookmarks, err := db.Rows("moz_bookmarks")
+	if err != nil {
+		return nil, err
+	}
+	places, err := db.Rows("moz_places")
+	if err != nil {
+		return nil, err
+	}
+
+	fb := &firefoxBookmarks{
+		children:        make(map[int64][]sqlitefmt.Row),
+		urls:            make(map[int64]string, len(places)),
+		tags:            make(map[int64][]string),
+		keywordsByPlace: make(map[int64]string),
+		keywordsByID:    make(map[int64]string),
+		visited:         make(map[int64]bool),
+	}
+
+	for _, p := range places {
+		fb.urls[p.Int("id")] = p.String("url")
+	}
+
+	if db.HasTable("moz_keywords") {
+		keywords, err := db.Rows("moz_keywords")
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keywords {
+			if _, ok := k["place_id"]; ok {
+				fb.keywordsByPlace[k.Int("place_id")] = k.String("keyword")
+			}
+			fb.keywordsByID[k.Int("id")] = k.String("keyword")
+		}
+	}
+
+	for _, b := range bookmarks {
+		fb.children[b.Int("parent")] = append(fb.children[b.Int("parent")], b)
+	}
+	for _, c := range fb.children {
+		sort.SliceStable(c, func(i, j int) bool { return c[i].Int("position") < c[j].Int("position") })
+	}
+
+	// tags are folders in the tags root, with a (hidden) bookmark for every tagged url
+	roots := make(map[string]sqlitefmt.Row)
+	for _, b := range bookmarks {
+		if b.String("guid") == firefoxGUIDTags {
+			for _, tagFolder := range fb.children[b.Int("id")] {
+				for _, tagged := range fb.children[tagFolder.Int("id")] {
+					fb.tags[tagged.Int("fk")] = append(fb.tags[tagged.Int("fk")], tagFolder.String("title"))
+				}
+			}
+		}
+		if id, ok := firefoxRoots[b.String("guid")]; ok {
+			roots[id] = b
+		}
+	}
+
+	result := make([]*models.BookmarkTreeRecord, 0, len(roots))
+	for _, id := range consts.BookmarkRootIDs {
+		if root, ok := roots[id]; ok {
+			item := fb.node(root)
+			item.ID = id
+			result = append(result, item)
+		}
+	}
+
+	return result, nil
+}
+
+func (fb *firefoxBookmarks) node(row sqlitefmt.Row) *models.BookmarkTreeRecord {
+	fb.visited[row.Int("id")] = true
+
+	item := &models.BookmarkTreeRecord{
+		BookmarkRecord: models.BookmarkRecord{
+			Title: row.String("title"),
+		},
+		ResolvedChildren: make([]*models.BookmarkTreeRecord, 0),
+	}
+
+	if v := row.Int("dateAdded"); v > 0 {
+		item.DateAdded = langext.Ptr(time.UnixMicro(v)) // PRTime, microseconds
+	} else if v := row.Int("lastModified"); v > 0 {
+		item.DateAdded = langext.Ptr(time.UnixMicro(v))
+	}
+
+	switch row.Int("type") {
+
+	case firefoxTypeBookmark:
+		item.Type = models.BookmarkTypeBookmark
+		item.URI = fb.urls[row.Int("fk")]
+		if models.IsQueryURI(item.URI) {
+			item.Type = models.BookmarkTypeQuery
+			item.FolderName = models.QueryFolderName(item.URI)
+		}
+		item.Tags = langext.ForceArray(fb.tags[row.Int("fk")])
+		item.Keyword = fb.keywordsByPlace[row.Int("fk")]
+		if item.Keyword == "" && row.Int("keyword_id") > 0 {
+			item.Keyword = fb.keywordsByID[row.Int("keyword_id")]
+		}
+
+	case firefoxTypeFolder:
+		item.Type = models.BookmarkTypeFolder
+		for _, child := range fb.children[row.Int("id")] {
+			if !fb.visited[child.Int("id")] {
+				item.ResolvedChildren = append(item.ResolvedChildren, fb.node(child))
+			}
+		}
+
+	case firefoxTypeSeparator:
+		item.Type = models.BookmarkTypeSeparator
+	}
+
+	return item
+}
@@ -0,0 +1,139 @@
+package browserprofile
+
+import (
+	"errors"
+	"ffsyncclient/models"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reads the history and the bookmarks directly from the files of a (local) browser profile directory
+// Firefox:  places.sqlite (moz_places, moz_historyvisits, moz_bookmarks, moz_keywords)
+// Chromium: History (sqlite, urls and visits) and Bookmarks (json), also used by chrome, edge, brave, etc.
+
+type Browser string
+
+const (
+	BrowserFirefox  Browser = "firefox"
+	BrowserChromium Browser = "chromium"
+)
+
+const (
+	firefoxPlacesFile     = "places.sqlite"
+	chromiumHistoryFile   = "History"
+	chromiumBookmarksFile = "Bookmarks"
+)
+
+// MaxVisits is the max number of visits per history record (firefox also only syncs the 20 newest visits of a page)
+const MaxVisits = 20
+
+// Detect returns the browser of the profile directory
+func Detect(dir string) (Browser, error) {
+	if fileExists(filepath.Join(dir, firefoxPlacesFile)) {
+		return BrowserFirefox, nil
+	}
+	if fileExists(filepath.Join(dir, chromiumHistoryFile)) || fileExists(filepath.Join(dir, chromiumBookmarksFile)) {
+		return BrowserChromium, nil
+	}
+	if st, err := os.Stat(dir); err != nil {
+		return "", err
+	} else if !st.IsDir() {
+		return "", errors.New("not a directory: " + dir)
+	}
+	return "", errors.New("no firefox (places.sqlite) or chromium (History, Bookmarks) profile found in " + dir)
+}
+
+// ReadHistory returns all syncable (http, https and ftp) pages of the profile that have at least one visit
+// The visits of every page are sorted newest first. Firefox pages have their places-GUID as ID (which is also their sync ID), chromium pages have no ID.
+func ReadHistory(dir string) ([]models.HistoryRecord, error) {
+	browser, err := Detect(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	switch browser {
+	case BrowserFirefox:
+		return readFirefoxHistory(filepath.Join(dir, firefoxPlacesFile))
+	case BrowserChromium:
+		return readChromiumHistory(filepath.Join(dir, chromiumHistoryFile))
+	default:
+		return nil, errors.New("unknown browser")
+	}
+}
+
+// ReadBookmarks returns the root folders of the profile (with their sync-IDs, e.g. consts.BookmarkIDMenu)
+// All other entries have no ID and are contained in the ResolvedChildren of their folder (like firefoxjsonfmt.Parse).
+func ReadBookmarks(dir string) ([]*models.BookmarkTreeRecord, error) {
+	browser, err := Detect(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	switch browser {
+	case BrowserFirefox:
+		return readFirefoxBookmarks(filepath.Join(dir, firefoxPlacesFile))
+	case BrowserChromium:
+		return readChromiumBookmarks(filepath.Join(dir, chromiumBookmarksFile))
+	default:
+		return nil, errors.New("unknown browser")
+	}
+}
+
+// IsSyncableURL returns true for the URLs that firefox stores in the synced history
+func IsSyncableURL(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ftp":
+		return true
+	default:
+		return false
+	}
+}
+
+// MergeVisits returns the visits of both lists (without duplicates), newest first
+// The result contains the newest max(MaxVisits, len(existing)) visits, so a record never shrinks by merging.
+func MergeVisits(existing []models.HistoryVisit, visits []models.HistoryVisit) []models.HistoryVisit {
+	all := make([]models.HistoryVisit, 0, len(existing)+len(visits))
+	all = append(all, existing...)
+	all = append(all, visits...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].VisitDate.After(all[j].VisitDate) })
+
+	limit := max(MaxVisits, len(existing))
+
+	result := make([]models.HistoryVisit, 0, min(len(all), limit))
+	for _, v := range all {
+		dup := false
+		for _, r := range result {
+			if r.VisitDate.UnixMicro() == v.VisitDate.UnixMicro() && r.TransitionType == v.TransitionType {
+				dup = true
+				break
+			}
+		}
+		if dup {
+			continue
+		}
+		if len(result) == limit {
+			break
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+func fileExists(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && !st.IsDir()
+}
+
+// chromiumTime converts a chromium timestamp (microseconds since 1601-01-01 UTC) to a time
+func chromiumTime(v int64) time.Time {
+	const epochDelta = 11644473600 * 1000 * 1000 // microseconds between 1601-01-01 and 1970-01-01
+	return time.UnixMicro(v - epochDelta)
+}
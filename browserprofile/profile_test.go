@@ -0,0 +1,191 @@
+package browserprofile
+
+import (
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"ffsyncclient/sqlitefmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeDB(t *testing.T, path string, tables []sqlitefmt.Table) {
+	data, err := sqlitefmt.Write(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func cols(names ...string) []sqlitefmt.Column {
+	r := []sqlitefmt.Column{{Name: "id", PrimaryKey: true}}
+	for _, n := range names {
+		r = append(r, sqlitefmt.Column{Name: n})
+	}
+	return r
+}
+
+func firefoxProfile(t *testing.T) string {
+	dir := t.TempDir()
+
+	writeDB(t, filepath.Join(dir, "places.sqlite"), []sqlitefmt.Table{
+		{Name: "moz_places", Columns: cols("url", "title", "guid"), Rows: [][]any{
+			{1, "https://go.dev/", "Go", "aaaaaaaaaaaa"},
+			{2, "about:config", "Config", "bbbbbbbbbbbb"},
+			{3, "https://example.com/", "Example", "cccccccccccc"},
+			{4, "place:tag=go", nil, "dddddddddddd"},
+		}},
+		{Name: "moz_historyvisits", Columns: cols("from_visit", "place_id", "visit_date", "visit_type"), Rows: [][]any{
+			{1, 0, 1, int64(1700000000000000), 1},
+			{2, 0, 1, int64(1700000100000000), 2},
+			{3, 0, 2, int64(1700000000000000), 1},
+			{4, 0, 1, int64(1700000100000000), 2},
+		}},
+		{Name: "moz_bookmarks", Columns: cols("type", "fk", "parent", "position", "title", "keyword_id", "dateAdded", "lastModified", "guid"), Rows: [][]any{
+			{1, 2, nil, 0, 0, "", nil, 0, 0, "root________"},
+			{2, 2, nil, 1, 0, "menu", nil, 0, 0, "menu________"},
+			{3, 2, nil, 1, 1, "toolbar", nil, 0, 0, "toolbar_____"},
+			{4, 2, nil, 1, 2, "tags", nil, 0, 0, "tags________"},
+			{10, 1, 1, 3, 0, "Go", nil, int64(1600000000123456), 0, "eeeeeeeeeeee"},
+			{11, 3, nil, 2, 1, "", nil, 0, 0, "ffffffffffff"},
+			{12, 2, nil, 2, 0, "Sub", nil, 0, 0, "gggggggggggg"},
+			{13, 1, 4, 2, 2, "Go tag", nil, 0, 0, "hhhhhhhhhhhh"},
+			{14, 2, nil, 4, 0, "golang", nil, 0, 0, "iiiiiiiiiiii"},
+			{15, 1, 1, 14, 0, "", nil, 0, 0, "jjjjjjjjjjjj"},
+		}},
+		{Name: "moz_keywords", Columns: cols("keyword", "place_id"), Rows: [][]any{{1, "go", 1}}},
+	})
+
+	return dir
+}
+
+func TestFirefoxHistory(t *testing.T) {
+	records, err := ReadHistory(firefoxProfile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %v", records)
+	}
+	r := records[0]
+	if r.ID != "aaaaaaaaaaaa" || r.URI != "https://go.dev/" || r.Title != "Go" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if len(r.Visits) != 2 || r.Visits[0].TransitionType != models.HistoryTransitionTypeTyped || r.Visits[0].VisitDate.UnixMicro() != 1700000100000000 {
+		t.Errorf("unexpected visits %+v", r.Visits)
+	}
+}
+
+func TestFirefoxBookmarks(t *testing.T) {
+	roots, err := ReadBookmarks(firefoxProfile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(roots) != 2 || roots[0].ID != consts.BookmarkIDMenu || roots[1].ID != consts.BookmarkIDToolbar {
+		t.Fatalf("unexpected roots %v", roots)
+	}
+
+	menu := roots[0].ResolvedChildren
+	if len(menu) != 3 || menu[0].Type != models.BookmarkTypeFolder || menu[1].Type != models.BookmarkTypeSeparator || menu[2].Type != models.BookmarkTypeQuery {
+		t.Fatalf("unexpected menu %v", menu)
+	}
+	if menu[2].FolderName != "go" {
+		t.Errorf("unexpected query %+v", menu[2].BookmarkRecord)
+	}
+
+	bm := roots[1].ResolvedChildren[0]
+	if bm.Type != models.BookmarkTypeBookmark || bm.URI != "https://go.dev/" || bm.Keyword != "go" || strings.Join(bm.Tags, ",") != "golang" {
+		t.Errorf("unexpected bookmark %+v", bm.BookmarkRecord)
+	}
+	if bm.DateAdded == nil || bm.DateAdded.UnixMicro() != 1600000000123456 {
+		t.Errorf("unexpected dateAdded %v", bm.DateAdded)
+	}
+}
+
+func TestChromium(t *testing.T) {
+	dir := t.TempDir()
+
+	const epoch = 11644473600 * 1000 * 1000
+
+	writeDB(t, filepath.Join(dir, "History"), []sqlitefmt.Table{
+		{Name: "urls", Columns: cols("url", "title", "visit_count"), Rows: [][]any{
+			{1, "https://go.dev/", "Go", 2},
+			{2, "chrome://settings/", "Settings", 1},
+		}},
+		{Name: "visits", Columns: cols("url", "visit_time", "from_visit", "transition"), Rows: [][]any{
+			{1, 1, int64(1700000000000000 + epoch), 0, int64(0x30000001)},
+			{2, 1, int64(1700000100000000 + epoch), 0, int64(0x08)},
+			{3, 2, int64(1700000100000000 + epoch), 0, int64(0x01)},
+		}},
+	})
+
+	bookmarks := `{"roots": {
+		"bookmark_bar": {"type": "folder", "name": "Bookmarks bar", "children": [
+			{"type": "url", "name": "Go", "url": "https://go.dev/", "date_added": "13345000000000000"},
+			{"type": "folder", "name": "Sub", "children": []}
+		]},
+		"other": {"type": "folder", "name": "Other bookmarks", "children": []}
+	}, "version": 1}`
+	if err := os.WriteFile(filepath.Join(dir, "Bookmarks"), []byte(bookmarks), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := Detect(dir); err != nil || b != BrowserChromium {
+		t.Fatalf("unexpected browser %v (%v)", b, err)
+	}
+
+	records, err := ReadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].ID != "" || len(records[0].Visits) != 2 {
+		t.Fatalf("unexpected records %v", records)
+	}
+	if v := records[0].Visits; v[0].TransitionType != models.HistoryTransitionTypeReload || v[1].TransitionType != models.HistoryTransitionTypeTyped || v[1].VisitDate.UnixMicro() != 1700000000000000 {
+		t.Errorf("unexpected visits %+v", v)
+	}
+
+	roots, err := ReadBookmarks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 2 || roots[0].ID != consts.BookmarkIDToolbar || roots[1].ID != consts.BookmarkIDUnfiled || len(roots[0].ResolvedChildren) != 2 {
+		t.Fatalf("unexpected roots %v", roots)
+	}
+	if bm := roots[0].ResolvedChildren[0]; bm.URI != "https://go.dev/" || bm.DateAdded == nil || bm.DateAdded.Unix() != 13345000000-11644473600 {
+		t.Errorf("unexpected bookmark %+v", bm.BookmarkRecord)
+	}
+
+	if _, err := Detect(t.TempDir()); err == nil {
+		t.Errorf("expected an error for an empty directory")
+	}
+}
+
+func TestMergeVisits(t *testing.T) {
+	visit := func(sec int64, tt models.HistoryTransitionType) models.HistoryVisit {
+		return models.HistoryVisit{TransitionType: tt, VisitDate: time.Unix(sec, 0)}
+	}
+
+	existing := []models.HistoryVisit{visit(10, 1), visit(5, 2)}
+	merged := MergeVisits(existing, []models.HistoryVisit{visit(5, 2), visit(7, 1), visit(5, 1)})
+	if len(merged) != 4 || merged[0].VisitDate.Unix() != 10 || merged[1].VisitDate.Unix() != 7 {
+		t.Errorf("unexpected result %v", merged)
+	}
+
+	many := make([]models.HistoryVisit, 0)
+	for i := int64(0); i < 50; i++ {
+		many = append(many, visit(i, 1))
+	}
+	if merged := MergeVisits(nil, many); len(merged) != MaxVisits || merged[0].VisitDate.Unix() != 49 {
+		t.Errorf("unexpected result %v", merged)
+	}
+	if merged := MergeVisits(many, []models.HistoryVisit{visit(100, 1)}); len(merged) != 50 || merged[0].VisitDate.Unix() != 100 {
+		t.Errorf("unexpected result (%d visits)", len(merged))
+	}
+}
@@ -36,6 +36,14 @@ func (c FFSContext) PrintPrimaryOutput(msg string) {
 	c.printPrimaryRaw(msg + "\n")
 }
 
+func (c FFSContext) PrintPrimaryOutputBinary(data []byte) {
+	if c.Opt.Quiet {
+		return
+	}
+
+	c.printPrimaryRaw(string(data))
+}
+
 func (c FFSContext) PrintPrimaryOutputJSON(data any) {
 	if c.Opt.Quiet {
 		return
@@ -19,20 +19,30 @@ var __OutputFormatValues = []OutputFormat{
 	OutputFormatText,
 	OutputFormatJson,
 	OutputFormatNetscape,
+	OutputFormatFirefoxJson,
 	OutputFormatXML,
 	OutputFormatTable,
 	OutputFormatTSV,
 	OutputFormatCSV,
+	OutputFormatMarkdown,
+	OutputFormatOPML,
+	OutputFormatOrg,
+	OutputFormatSQLite,
 }
 
 var __OutputFormatVarnames = map[OutputFormat]string{
-	OutputFormatText:     "OutputFormatText",
-	OutputFormatJson:     "OutputFormatJson",
-	OutputFormatNetscape: "OutputFormatNetscape",
-	OutputFormatXML:      "OutputFormatXML",
-	OutputFormatTable:    "OutputFormatTable",
-	OutputFormatTSV:      "OutputFormatTSV",
-	OutputFormatCSV:      "OutputFormatCSV",
+	OutputFormatText:        "OutputFormatText",
+	OutputFormatJson:        "OutputFormatJson",
+	OutputFormatNetscape:    "OutputFormatNetscape",
+	OutputFormatFirefoxJson: "OutputFormatFirefoxJson",
+	OutputFormatXML:         "OutputFormatXML",
+	OutputFormatTable:       "OutputFormatTable",
+	OutputFormatTSV:         "OutputFormatTSV",
+	OutputFormatCSV:         "OutputFormatCSV",
+	OutputFormatMarkdown:    "OutputFormatMarkdown",
+	OutputFormatOPML:        "OutputFormatOPML",
+	OutputFormatOrg:         "OutputFormatOrg",
+	OutputFormatSQLite:      "OutputFormatSQLite",
 }
 
 func (e OutputFormat) Valid() bool {
@@ -92,10 +102,15 @@ func OutputFormatValuesMeta() []enums.EnumMetaValue {
 		OutputFormatText.Meta(),
 		OutputFormatJson.Meta(),
 		OutputFormatNetscape.Meta(),
+		OutputFormatFirefoxJson.Meta(),
 		OutputFormatXML.Meta(),
 		OutputFormatTable.Meta(),
 		OutputFormatTSV.Meta(),
 		OutputFormatCSV.Meta(),
+		OutputFormatMarkdown.Meta(),
+		OutputFormatOPML.Meta(),
+		OutputFormatOrg.Meta(),
+		OutputFormatSQLite.Meta(),
 	}
 }
 
@@ -130,21 +145,48 @@ var __ModeValues = []Mode{
 	ModeBookmarksCreateBookmark,
 	ModeBookmarksCreateFolder,
 	ModeBookmarksCreateSeparator,
+	ModeBookmarksCreateQuery,
+	ModeBookmarksCreateLivemark,
 	ModeBookmarksUpdate,
+	ModeBookmarksImport,
+	ModeBookmarksMove,
+	ModeBookmarksCopy,
+	ModeBookmarksCheck,
+	ModeBookmarksSearch,
+	ModeBookmarksDedupe,
+	ModeBookmarksResolve,
+	ModeBookmarksMirror,
+	ModeBookmarksSort,
+	ModeBookmarksTagsBase,
+	ModeBookmarksTagsList,
+	ModeBookmarksTagsAdd,
+	ModeBookmarksTagsRename,
+	ModeBookmarksTagsRemove,
 	ModePasswordsBase,
 	ModePasswordsList,
 	ModePasswordsGet,
 	ModePasswordsCreate,
 	ModePasswordsUpdate,
 	ModePasswordsDelete,
+	ModePasswordsDedupe,
+	ModeDockerCredentialHelper,
+	ModeExec,
 	ModeFormsBase,
 	ModeFormsList,
 	ModeFormsGet,
 	ModeFormsCreate,
 	ModeFormsDelete,
+	ModeFormsUpdate,
+	ModeFormsPurge,
+	ModeFormsScan,
 	ModeHistoryBase,
 	ModeHistoryList,
+	ModeHistorySearch,
+	ModeHistoryStats,
+	ModeHistoryVisits,
 	ModeHistoryDelete,
+	ModeHistoryPurge,
+	ModeHistoryImport,
 	ModeTabsBase,
 	ModeTabsList,
 }
@@ -172,21 +214,48 @@ var __ModeVarnames = map[Mode]string{
 	ModeBookmarksCreateBookmark:  "ModeBookmarksCreateBookmark",
 	ModeBookmarksCreateFolder:    "ModeBookmarksCreateFolder",
 	ModeBookmarksCreateSeparator: "ModeBookmarksCreateSeparator",
+	ModeBookmarksCreateQuery:     "ModeBookmarksCreateQuery",
+	ModeBookmarksCreateLivemark:  "ModeBookmarksCreateLivemark",
 	ModeBookmarksUpdate:          "ModeBookmarksUpdate",
+	ModeBookmarksImport:          "ModeBookmarksImport",
+	ModeBookmarksMove:            "ModeBookmarksMove",
+	ModeBookmarksCopy:            "ModeBookmarksCopy",
+	ModeBookmarksCheck:           "ModeBookmarksCheck",
+	ModeBookmarksSearch:          "ModeBookmarksSearch",
+	ModeBookmarksDedupe:          "ModeBookmarksDedupe",
+	ModeBookmarksResolve:         "ModeBookmarksResolve",
+	ModeBookmarksMirror:          "ModeBookmarksMirror",
+	ModeBookmarksSort:            "ModeBookmarksSort",
+	ModeBookmarksTagsBase:        "ModeBookmarksTagsBase",
+	ModeBookmarksTagsList:        "ModeBookmarksTagsList",
+	ModeBookmarksTagsAdd:         "ModeBookmarksTagsAdd",
+	ModeBookmarksTagsRename:      "ModeBookmarksTagsRename",
+	ModeBookmarksTagsRemove:      "ModeBookmarksTagsRemove",
 	ModePasswordsBase:            "ModePasswordsBase",
 	ModePasswordsList:            "ModePasswordsList",
 	ModePasswordsGet:             "ModePasswordsGet",
 	ModePasswordsCreate:          "ModePasswordsCreate",
 	ModePasswordsUpdate:          "ModePasswordsUpdate",
 	ModePasswordsDelete:          "ModePasswordsDelete",
+	ModePasswordsDedupe:          "ModePasswordsDedupe",
+	ModeDockerCredentialHelper:   "ModeDockerCredentialHelper",
+	ModeExec:                     "ModeExec",
 	ModeFormsBase:                "ModeFormsBase",
 	ModeFormsList:                "ModeFormsList",
 	ModeFormsGet:                 "ModeFormsGet",
 	ModeFormsCreate:              "ModeFormsCreate",
 	ModeFormsDelete:              "ModeFormsDelete",
+	ModeFormsUpdate:              "ModeFormsUpdate",
+	ModeFormsPurge:               "ModeFormsPurge",
+	ModeFormsScan:                "ModeFormsScan",
 	ModeHistoryBase:              "ModeHistoryBase",
 	ModeHistoryList:              "ModeHistoryList",
+	ModeHistorySearch:            "ModeHistorySearch",
+	ModeHistoryStats:             "ModeHistoryStats",
+	ModeHistoryVisits:            "ModeHistoryVisits",
 	ModeHistoryDelete:            "ModeHistoryDelete",
+	ModeHistoryPurge:             "ModeHistoryPurge",
+	ModeHistoryImport:            "ModeHistoryImport",
 	ModeTabsBase:                 "ModeTabsBase",
 	ModeTabsList:                 "ModeTabsList",
 }
@@ -267,21 +336,48 @@ func ModeValuesMeta() []enums.EnumMetaValue {
 		ModeBookmarksCreateBookmark.Meta(),
 		ModeBookmarksCreateFolder.Meta(),
 		ModeBookmarksCreateSeparator.Meta(),
+		ModeBookmarksCreateQuery.Meta(),
+		ModeBookmarksCreateLivemark.Meta(),
 		ModeBookmarksUpdate.Meta(),
+		ModeBookmarksImport.Meta(),
+		ModeBookmarksMove.Meta(),
+		ModeBookmarksCopy.Meta(),
+		ModeBookmarksCheck.Meta(),
+		ModeBookmarksSearch.Meta(),
+		ModeBookmarksDedupe.Meta(),
+		ModeBookmarksResolve.Meta(),
+		ModeBookmarksMirror.Meta(),
+		ModeBookmarksSort.Meta(),
+		ModeBookmarksTagsBase.Meta(),
+		ModeBookmarksTagsList.Meta(),
+		ModeBookmarksTagsAdd.Meta(),
+		ModeBookmarksTagsRename.Meta(),
+		ModeBookmarksTagsRemove.Meta(),
 		ModePasswordsBase.Meta(),
 		ModePasswordsList.Meta(),
 		ModePasswordsGet.Meta(),
 		ModePasswordsCreate.Meta(),
 		ModePasswordsUpdate.Meta(),
 		ModePasswordsDelete.Meta(),
+		ModePasswordsDedupe.Meta(),
+		ModeDockerCredentialHelper.Meta(),
+		ModeExec.Meta(),
 		ModeFormsBase.Meta(),
 		ModeFormsList.Meta(),
 		ModeFormsGet.Meta(),
 		ModeFormsCreate.Meta(),
 		ModeFormsDelete.Meta(),
+		ModeFormsUpdate.Meta(),
+		ModeFormsPurge.Meta(),
+		ModeFormsScan.Meta(),
 		ModeHistoryBase.Meta(),
 		ModeHistoryList.Meta(),
+		ModeHistorySearch.Meta(),
+		ModeHistoryStats.Meta(),
+		ModeHistoryVisits.Meta(),
 		ModeHistoryDelete.Meta(),
+		ModeHistoryPurge.Meta(),
+		ModeHistoryImport.Meta(),
 		ModeTabsBase.Meta(),
 		ModeTabsList.Meta(),
 	}
@@ -7,13 +7,18 @@ import (
 type OutputFormat string //@enum:type
 
 const (
-	OutputFormatText     OutputFormat = "text"
-	OutputFormatJson     OutputFormat = "json"
-	OutputFormatNetscape OutputFormat = "netscape"
-	OutputFormatXML      OutputFormat = "xml"
-	OutputFormatTable    OutputFormat = "table"
-	OutputFormatTSV      OutputFormat = "tsv"
-	OutputFormatCSV      OutputFormat = "csv"
+	OutputFormatText        OutputFormat = "text"
+	OutputFormatJson        OutputFormat = "json"
+	OutputFormatNetscape    OutputFormat = "netscape"
+	OutputFormatFirefoxJson OutputFormat = "firefox-json"
+	OutputFormatXML         OutputFormat = "xml"
+	OutputFormatTable       OutputFormat = "table"
+	OutputFormatTSV         OutputFormat = "tsv"
+	OutputFormatCSV         OutputFormat = "csv"
+	OutputFormatMarkdown    OutputFormat = "markdown"
+	OutputFormatOPML        OutputFormat = "opml"
+	OutputFormatOrg         OutputFormat = "org"
+	OutputFormatSQLite      OutputFormat = "sqlite"
 )
 
 func GetOutputFormat(v string) (OutputFormat, bool) {
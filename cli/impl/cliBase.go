@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"github.com/joomcode/errorx"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"golang.org/x/term"
 	"os"
+	"strings"
 )
 
 type CLIArgumentsBaseUtil struct{}
@@ -214,3 +216,17 @@ func (a *CLIArgumentsBaseUtil) SyncLogin(ctx *cli.FFSContext, client *syncclient
 
 	return sessionCrypto, nil
 }
+
+func (a *CLIArgumentsBaseUtil) confirm(question string) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fferr.NewDirectOutput(consts.ExitcodeError, "Cannot ask for confirmation (stdin is not a terminal), use --yes or --dry-run")
+	}
+
+	_, _ = fmt.Fprint(os.Stderr, question+" [y/N]: ")
+
+	var answer string
+	_, _ = fmt.Scanln(&answer)
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -1,6 +1,9 @@
 package impl
 
 import (
+	"encoding/json"
+	"errors"
+	"ffsyncclient/bookmarkpath"
 	"ffsyncclient/cli"
 	"ffsyncclient/consts"
 	"ffsyncclient/fferr"
@@ -9,7 +12,10 @@ import (
 	"fmt"
 	"github.com/joomcode/errorx"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 )
 
 type CLIArgumentsBookmarksBase struct {
@@ -118,7 +124,7 @@ func (a *CLIArgumentsBookmarksUtil) calculateTree(ctx *cli.FFSContext, bookmarks
 				continue
 			}
 
-			if v.ParentID == "" || v.ParentID == "places" || (langext.InArray(v.ID, extraRoots) && !idExists(v.ParentID)) {
+			if v.ParentID == "" || v.ParentID == consts.BookmarkIDPlaces || langext.InArray(v.ID, consts.BookmarkRootIDs) || (langext.InArray(v.ID, extraRoots) && !idExists(v.ParentID)) {
 				record := models.BookmarkTreeRecord{BookmarkRecord: v, ResolvedChildren: make([]*models.BookmarkTreeRecord, 0)}
 				roots = append(roots, &record)
 				processedOkay[v.ID] = &record
@@ -151,6 +157,15 @@ func (a *CLIArgumentsBookmarksUtil) calculateTree(ctx *cli.FFSContext, bookmarks
 	}
 	ctx.PrintVerbose(fmt.Sprintf("Build boookmark-tree after %d iterations (Processed %d/%d with %d roots)", i, len(processedOkay), len(bookmarks), len(roots)))
 
+	// the places roots come first (in the firefox order), then all other roots
+	rootOrder := func(v *models.BookmarkTreeRecord) int {
+		if idx := langext.ArrFirstIndex(consts.BookmarkRootIDs, v.ID); idx >= 0 {
+			return idx
+		}
+		return len(consts.BookmarkRootIDs)
+	}
+	sort.SliceStable(roots, func(i1, i2 int) bool { return rootOrder(roots[i1]) < rootOrder(roots[i2]) })
+
 	missing := make(map[string]bool)
 
 	// properly sort children
@@ -171,7 +186,7 @@ func (a *CLIArgumentsBookmarksUtil) calculateTree(ctx *cli.FFSContext, bookmarks
 	}
 
 	for _, record := range roots {
-		if record.ParentID != "" {
+		if record.ParentID != "" && record.ParentID != consts.BookmarkIDPlaces {
 			if langext.InArray(record.ID, extraRoots) {
 				continue // extraRoots are allowed to have missing parents
 			}
@@ -220,10 +235,49 @@ func (a *CLIArgumentsBookmarksUtil) newBookmarkID() string {
 	return langext.RandBase62(12)
 }
 
+// resolveParentAlias maps the names of the root folders (case-insensitive, and `other` for the "Other Bookmarks") to their record-id
+func (a *CLIArgumentsBookmarksUtil) resolveParentAlias(v string) string {
+	for _, id := range consts.BookmarkRootIDs {
+		if strings.EqualFold(v, id) {
+			return id
+		}
+	}
+	if strings.EqualFold(v, "other") {
+		return consts.BookmarkIDUnfiled
+	}
+	return v
+}
+
+// newRootRecord creates an (unsaved) record for a missing root folder
+// Firefox only creates some roots (e.g. `mobile`) on the server once they contain entries
+func (a *CLIArgumentsBookmarksUtil) newRootRecord(id string) (models.Record, error) {
+	bso := models.BookmarkCreatePayloadSchema{
+		ID:         id,
+		Type:       string(models.BookmarkTypeFolder),
+		DateAdded:  time.Now().UnixMilli(),
+		ParentID:   consts.BookmarkIDPlaces,
+		ParentName: "",
+
+		Title:    langext.Ptr(id),
+		Children: langext.Ptr(make([]string, 0)),
+	}
+
+	plainPayload, err := json.Marshal(bso)
+	if err != nil {
+		return models.Record{}, errorx.Decorate(err, "failed to marshal BSO json")
+	}
+
+	return models.Record{ID: id, DecodedData: plainPayload}, nil
+}
+
 func (a *CLIArgumentsBookmarksUtil) calculateParent(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, newid string, parentid string, pos int) (models.BookmarkRecord, string, int, error) {
 	ctx.PrintVerbose("Query parent by ID")
 
 	record, err := client.GetRecord(ctx, session, consts.CollectionBookmarks, parentid, true)
+	if err != nil && errorx.IsOfType(err, fferr.Request404) && langext.InArray(parentid, consts.BookmarkRootIDs) {
+		ctx.PrintVerbose(fmt.Sprintf("Root folder '%s' does not exist yet, it will be created", parentid))
+		record, err = a.newRootRecord(parentid)
+	}
 	if err != nil && errorx.IsOfType(err, fferr.Request404) {
 		return models.BookmarkRecord{}, "", 0, fferr.DirectOutput.Wrap(err, fmt.Sprintf("parent-record with ID '%s' not found", parentid)).WithProperty(fferr.Exitcode, consts.ExitcodeRecordNotFound)
 	}
@@ -280,11 +334,329 @@ func (a *CLIArgumentsBookmarksUtil) moveChild(ctx *cli.FFSContext, record models
 
 	ctx.PrintVerboseKV("Parent<new>.children", strings.Join(children, ", "))
 
-	newPlainPayload, err := langext.PatchJson(record.DecodedData, "children", children)
+	newPlainPayload, err := a.patchChildren(record, children)
 	if err != nil {
-		return models.BookmarkRecord{}, "", 0, errorx.Decorate(err, "failed to patch parent-record data").WithProperty(fferr.Exitcode, consts.ExitcodeError)
+		return models.BookmarkRecord{}, "", 0, err
 	}
 	bmrec.Children = children
 
-	return bmrec, string(newPlainPayload), normpos, nil
+	return bmrec, newPlainPayload, normpos, nil
+}
+
+// patchChildren returns the data of the folder record with the `children` replaced (all other fields are kept as-is)
+func (a *CLIArgumentsBookmarksUtil) patchChildren(record models.Record, children []string) (string, error) {
+	newPlainPayload, err := langext.PatchJson(record.DecodedData, "children", children)
+	if err != nil {
+		return "", errorx.Decorate(err, "failed to patch parent-record data").WithProperty(fferr.Exitcode, consts.ExitcodeError)
+	}
+	return string(newPlainPayload), nil
+}
+
+type bookmarkCollection struct {
+	records      map[string]models.Record
+	bookmarks    map[string]models.BookmarkRecord
+	deleted      []string // the IDs of the tombstones
+	lastModified float64 // the newest `modified` timestamp of the collection (for X-If-Unmodified-Since)
+}
+
+type bookmarkPayload struct {
+	ID    string
+	Plain []byte
+}
+
+// loadBookmarkCollection queries all bookmark records (deleted records are only listed in `deleted`)
+func (a *CLIArgumentsBookmarksUtil) loadBookmarkCollection(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession) (*bookmarkCollection, error) {
+	records, err := client.ListRecords(ctx, session, consts.CollectionBookmarks, nil, nil, false, true, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	coll := &bookmarkCollection{
+		records:   make(map[string]models.Record, len(records)),
+		bookmarks: make(map[string]models.BookmarkRecord, len(records)),
+		deleted:   make([]string, 0),
+	}
+
+	for _, rec := range records {
+		coll.lastModified = max(coll.lastModified, rec.ModifiedUnix)
+
+		bmrec, err := models.UnmarshalBookmark(ctx, rec)
+		if err != nil {
+			ctx.PrintVerbose(fmt.Sprintf("Skip record %s (failed to decode)", rec.ID))
+			continue
+		}
+		if bmrec.Deleted {
+			coll.deleted = append(coll.deleted, rec.ID)
+			continue
+		}
+		coll.records[rec.ID] = rec
+		coll.bookmarks[rec.ID] = bmrec
+	}
+
+	return coll, nil
+}
+
+// isAncestor returns true if `ancestor` is `id` or one of its (transitive) parents
+func (c *bookmarkCollection) isAncestor(ancestor string, id string) bool {
+	visited := make(map[string]bool)
+	for id != "" && !visited[id] {
+		if id == ancestor {
+			return true
+		}
+		visited[id] = true
+		bm, ok := c.bookmarks[id]
+		if !ok {
+			return false
+		}
+		id = bm.ParentID
+	}
+	return false
+}
+
+// path returns the human-readable path of the entry (e.g. `toolbar/Dev/Go docs`), see bookmarkpath
+// Entries that are not (transitively) in a root folder get the path of their outermost known parent.
+func (c *bookmarkCollection) path(id string) string {
+	titles := make([]string, 0)
+	visited := make(map[string]bool)
+
+	for id != "" && !visited[id] {
+		visited[id] = true
+
+		if langext.InArray(id, consts.BookmarkRootIDs) {
+			titles = append(titles, id)
+			break
+		}
+
+		bm, ok := c.bookmarks[id]
+		if !ok {
+			break
+		}
+		titles = append(titles, bm.Title)
+		id = bm.ParentID
+	}
+
+	slices.Reverse(titles)
+
+	return bookmarkpath.Join(titles...)
+}
+
+// postBookmarkPayloads encrypts and writes the records with a single batch upload (see FxAClient.PostRecordsBatched)
+// The upload is rejected if the collection was modified after `ifUnmodifiedSince`, no record is written in that case.
+func (a *CLIArgumentsBookmarksUtil) postBookmarkPayloads(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, payloads []bookmarkPayload, ifUnmodifiedSince *float64) error {
+	updates := make([]models.RecordUpdate, 0, len(payloads))
+	for _, v := range payloads {
+		payload, err := client.EncryptPayload(ctx, session, consts.CollectionBookmarks, string(v.Plain))
+		if err != nil {
+			return err
+		}
+		updates = append(updates, models.RecordUpdate{ID: v.ID, Payload: langext.Ptr(payload)})
+	}
+
+	_, err := client.PostRecordsBatched(ctx, session, consts.CollectionBookmarks, updates, ifUnmodifiedSince)
+	if err != nil && errorx.IsOfType(err, fferr.Request412) {
+		return fferr.WrapDirectOutput(err, consts.ExitcodeConcurrentModification, "The bookmarks were modified by another client in the meantime, please try again")
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// findTreeNode searches the (sub)trees for the entry with the specified ID
+func (a *CLIArgumentsBookmarksUtil) findTreeNode(roots []*models.BookmarkTreeRecord, id string) (*models.BookmarkTreeRecord, bool) {
+	for _, v := range roots {
+		if v.ID == id {
+			return v, true
+		}
+		if r, ok := a.findTreeNode(v.ResolvedChildren, id); ok {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// flattenTree returns the node and all its descendants (pre-order)
+func (a *CLIArgumentsBookmarksUtil) flattenTree(node *models.BookmarkTreeRecord) []*models.BookmarkTreeRecord {
+	result := []*models.BookmarkTreeRecord{node}
+	for _, v := range node.ResolvedChildren {
+		result = append(result, a.flattenTree(v)...)
+	}
+	return result
+}
+
+// subtree builds the tree below the entry with the specified ID
+func (a *CLIArgumentsBookmarksUtil) subtree(ctx *cli.FFSContext, coll *bookmarkCollection, id string) (*models.BookmarkTreeRecord, bool) {
+	bookmarks := make([]models.BookmarkRecord, 0, len(coll.bookmarks))
+	for _, v := range coll.bookmarks {
+		bookmarks = append(bookmarks, v)
+	}
+
+	roots, _, _ := a.calculateTree(ctx, bookmarks, []string{id})
+
+	return a.findTreeNode(roots, id)
+}
+
+// resolveBookmarkRef resolves a record-id, a root folder (see resolveParentAlias) or a path (e.g. `toolbar/Dev/Go docs`) to a record-id
+// If mkdirs is true, the missing folders of the path are created.
+func (a *CLIArgumentsBookmarksUtil) resolveBookmarkRef(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, ref string, mkdirs bool) (string, error) {
+	if !bookmarkpath.IsPath(ref) {
+		return a.resolveParentAlias(ref), nil
+	}
+
+	ctx.PrintVerbose(fmt.Sprintf("Resolve bookmark path '%s'", ref))
+
+	segments, err := bookmarkpath.Split(ref)
+	if err != nil {
+		return "", fferr.NewDirectOutput(consts.ExitcodeError, err.Error())
+	}
+
+	rootID := a.resolveParentAlias(segments[0])
+	if !langext.InArray(rootID, consts.BookmarkRootIDs) {
+		return "", fferr.NewDirectOutput(consts.ExitcodeError, fmt.Sprintf("The bookmark path '%s' must start with a root folder (menu, toolbar, unfiled, mobile)", ref))
+	}
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return "", errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	id, missing, err := bookmarkpath.Resolve(coll.bookmarks, rootID, segments[1:])
+	if err != nil && errors.Is(err, bookmarkpath.ErrAmbiguous) {
+		return "", fferr.WrapDirectOutput(err, consts.ExitcodeBookmarkPathAmbiguous, fmt.Sprintf("The bookmark path '%s' is ambiguous (%s)", ref, err.Error()))
+	}
+	if err != nil && errors.Is(err, bookmarkpath.ErrNotFolder) {
+		return "", fferr.WrapDirectOutput(err, consts.ExitcodeParentNotAFolder, err.Error())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(missing) == 0 {
+		ctx.PrintVerbose(fmt.Sprintf("Resolved bookmark path '%s' to %s", ref, id))
+		return id, nil
+	}
+
+	if !mkdirs {
+		return "", fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, fmt.Sprintf("The bookmark path '%s' does not exist ('%s' not found)", ref, missing[0]))
+	}
+
+	return a.createFolderPath(ctx, client, session, coll, id, missing)
+}
+
+// createFolderPath creates nested folders (with the specified titles) in the folder parentID and returns the ID of the innermost folder
+func (a *CLIArgumentsBookmarksUtil) createFolderPath(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, coll *bookmarkCollection, parentID string, titles []string) (string, error) {
+	parentRecord, ok := coll.records[parentID]
+	if !ok {
+		ctx.PrintVerbose(fmt.Sprintf("Root folder '%s' does not exist yet, it will be created", parentID))
+		var err error
+		parentRecord, err = a.newRootRecord(parentID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	parent, err := models.UnmarshalBookmark(ctx, parentRecord)
+	if err != nil {
+		return "", errorx.Decorate(err, "failed to decode parent-record")
+	}
+
+	ids := make([]string, 0, len(titles))
+	for range titles {
+		ids = append(ids, a.newBookmarkID())
+	}
+
+	parent, plainParent, _, err := a.moveChild(ctx, parentRecord, parent, ids[0], -1)
+	if err != nil {
+		return "", err
+	}
+
+	payloads := make([]bookmarkPayload, 0, len(titles)+1)
+
+	parentID, parentName := parent.ID, parent.Title
+	for i, title := range titles {
+		ctx.PrintVerbose(fmt.Sprintf("Create folder '%s' (%s)", title, ids[i]))
+
+		children := make([]string, 0, 1)
+		if i+1 < len(ids) {
+			children = append(children, ids[i+1])
+		}
+
+		bso := models.BookmarkCreatePayloadSchema{
+			ID:         ids[i],
+			Type:       string(models.BookmarkTypeFolder),
+			DateAdded:  time.Now().UnixMilli(),
+			ParentID:   parentID,
+			ParentName: parentName,
+
+			Title:    langext.Ptr(title),
+			Children: langext.Ptr(children),
+		}
+
+		plain, err := json.Marshal(bso)
+		if err != nil {
+			return "", errorx.Decorate(err, "failed to marshal BSO json")
+		}
+
+		payloads = append(payloads, bookmarkPayload{ID: ids[i], Plain: plain})
+		parentID, parentName = ids[i], title
+	}
+
+	// the parent is written last, so the new folders only become visible once they all exist
+	payloads = append(payloads, bookmarkPayload{ID: parent.ID, Plain: []byte(plainParent)})
+
+	err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+	if err != nil {
+		return "", err
+	}
+
+	return ids[len(ids)-1], nil
+}
+
+// hasTags returns true for the entry types that can have tags
+func (a *CLIArgumentsBookmarksUtil) hasTags(bm models.BookmarkRecord) bool {
+	return bm.Type == models.BookmarkTypeBookmark || bm.Type == models.BookmarkTypeMicroSummary || bm.Type == models.BookmarkTypeQuery
+}
+
+// updateTags calls `fn` for every entry with tags and writes the changed tag lists back (all other fields of the payload are kept)
+// Returns the IDs of the changed entries, with dryRun nothing is written.
+func (a *CLIArgumentsBookmarksUtil) updateTags(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, coll *bookmarkCollection, dryRun bool, fn func(bm models.BookmarkRecord) ([]string, bool)) ([]string, error) {
+	ids := langext.MapKeyArr(coll.bookmarks)
+	sort.Strings(ids)
+
+	changed := make([]string, 0)
+	payloads := make([]bookmarkPayload, 0)
+
+	for _, id := range ids {
+		bm := coll.bookmarks[id]
+		if !a.hasTags(bm) {
+			continue
+		}
+
+		newTags, ok := fn(bm)
+		if !ok {
+			continue
+		}
+
+		ctx.PrintVerbose(fmt.Sprintf("Update tags of %s: [%s] -> [%s]", id, strings.Join(bm.Tags, ", "), strings.Join(newTags, ", ")))
+
+		plain, err := langext.PatchJson(coll.records[id].DecodedData, "tags", newTags)
+		if err != nil {
+			return nil, errorx.Decorate(err, "failed to patch data of record")
+		}
+
+		changed = append(changed, id)
+		payloads = append(payloads, bookmarkPayload{ID: id, Plain: plain})
+	}
+
+	if dryRun || len(payloads) == 0 {
+		return changed, nil
+	}
+
+	err := a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
 }
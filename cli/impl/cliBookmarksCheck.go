@@ -0,0 +1,253 @@
+package impl
+
+import (
+	"ffsyncclient/bookmarkcheck"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"github.com/joomcode/errorx"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"sort"
+)
+
+type CLIArgumentsBookmarksCheck struct {
+	Fix bool
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksCheck() *CLIArgumentsBookmarksCheck {
+	return &CLIArgumentsBookmarksCheck{
+		Fix: false,
+	}
+}
+
+func (a *CLIArgumentsBookmarksCheck) Mode() cli.Mode {
+	return cli.ModeBookmarksCheck
+}
+
+func (a *CLIArgumentsBookmarksCheck) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsBookmarksCheck) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatText, cli.OutputFormatJson}
+}
+
+func (a *CLIArgumentsBookmarksCheck) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks check", "Validate the structure of the bookmark tree"},
+		{"          [--fix]", "Repair the found problems"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksCheck) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks check [--fix]",
+		"",
+		"Validate the bookmark tree, similar to the bookmark validator of firefox",
+		"",
+		"Reports the following problems:",
+		"  * orphan              The parent of the record does not exist (or is deleted)",
+		"  * parent-not-folder   The parent of the record is not a folder",
+		"  * parent-mismatch     The `parentid` of the record does not match the folder that lists it as a child",
+		"  * multiple-parents    The record is listed in the children of multiple folders",
+		"  * duplicate-child     A folder lists the same child multiple times",
+		"  * missing-child       A folder lists a child that does not exist",
+		"  * deleted-child       A folder lists a child that is deleted",
+		"  * parent-name         The `parentName` of the record is not the title of its parent",
+		"  * cycle               The record is (transitively) its own parent",
+		"",
+		"If --fix is specified the problems are repaired:",
+		"Orphans (and records in a cycle) are moved to `unfiled`, the `children` of all folders and the `parentid`/`parentName` of all records are rewritten to be consistent.",
+		"All changed records are written in a single batch upload, the request fails if the bookmarks were modified by another client in the meantime.",
+		"",
+		"Returns with exitcode 88 if problems were found (and not fixed).",
+	}
+}
+
+func (a *CLIArgumentsBookmarksCheck) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if arg.Key == "fix" && arg.Value == nil {
+			a.Fix = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksCheck) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Check Bookmarks]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Fix", a.Fix)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query bookmarks")
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	bookmarks := make([]models.BookmarkRecord, 0, len(coll.bookmarks)+len(coll.deleted))
+	for _, v := range coll.bookmarks {
+		bookmarks = append(bookmarks, v)
+	}
+	for _, v := range coll.deleted {
+		bookmarks = append(bookmarks, models.BookmarkRecord{ID: v, Deleted: true})
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Validate tree")
+
+	result := bookmarkcheck.Check(bookmarks)
+
+	ctx.PrintVerbose(fmt.Sprintf("Found %d problems, %d records need to be changed", len(result.Problems), len(result.Patches)))
+
+	// ========================================================================
+
+	updated := make([]string, 0)
+
+	if a.Fix && len(result.Patches) > 0 {
+		ctx.PrintVerboseHeader("[3] Repair tree")
+
+		payloads, err := a.applyPatches(ctx, coll, result.Patches)
+		if err != nil {
+			return err
+		}
+
+		err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+		if err != nil {
+			return err
+		}
+
+		for _, v := range payloads {
+			updated = append(updated, v.ID)
+		}
+	}
+
+	// ========================================================================
+
+	return a.printOutput(ctx, result.Problems, updated)
+}
+
+func (a *CLIArgumentsBookmarksCheck) applyPatches(ctx *cli.FFSContext, coll *bookmarkCollection, patches map[string]*bookmarkcheck.Patch) ([]bookmarkPayload, error) {
+	ids := make([]string, 0, len(patches))
+	for id := range patches {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	payloads := make([]bookmarkPayload, 0, len(ids))
+
+	for _, id := range ids {
+		patch := patches[id]
+
+		var record models.Record
+		var err error
+
+		if patch.Create {
+			ctx.PrintVerbose(fmt.Sprintf("Create missing root folder '%s'", id))
+			record, err = a.newRootRecord(id)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			record = coll.records[id]
+		}
+
+		plain := record.DecodedData
+
+		if patch.ParentID != nil {
+			ctx.PrintVerbose(fmt.Sprintf("Patch field [parentid] of %s to \"%s\"", id, *patch.ParentID))
+			plain, err = langext.PatchJson(plain, "parentid", *patch.ParentID)
+			if err != nil {
+				return nil, errorx.Decorate(err, "failed to patch data of record")
+			}
+		}
+
+		if patch.ParentName != nil {
+			ctx.PrintVerbose(fmt.Sprintf("Patch field [parentName] of %s to \"%s\"", id, *patch.ParentName))
+			plain, err = langext.PatchJson(plain, "parentName", *patch.ParentName)
+			if err != nil {
+				return nil, errorx.Decorate(err, "failed to patch data of record")
+			}
+		}
+
+		if patch.Children != nil {
+			ctx.PrintVerbose(fmt.Sprintf("Patch field [children] of %s to %v", id, *patch.Children))
+			plain, err = langext.PatchJson(plain, "children", *patch.Children)
+			if err != nil {
+				return nil, errorx.Decorate(err, "failed to patch data of record")
+			}
+		}
+
+		payloads = append(payloads, bookmarkPayload{ID: id, Plain: plain})
+	}
+
+	return payloads, nil
+}
+
+func (a *CLIArgumentsBookmarksCheck) printOutput(ctx *cli.FFSContext, problems []bookmarkcheck.Problem, updated []string) error {
+	var exitErr error = nil
+	if len(problems) > 0 && !a.Fix {
+		exitErr = fferr.NewEmpty(consts.ExitcodeBookmarkTreeInvalid)
+	}
+
+	switch langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable) {
+
+	case cli.OutputFormatTable:
+		if len(problems) > 0 {
+			table := make([][]string, 0, len(problems)+1)
+			table = append(table, []string{"PROBLEM", "RECORD", "MESSAGE"})
+			for _, v := range problems {
+				table = append(table, []string{string(v.Type), v.RecordID, v.Message})
+			}
+			ctx.PrintPrimaryOutputTable(table)
+			ctx.PrintPrimaryOutput("")
+		}
+		a.printSummary(ctx, problems, updated)
+		return exitErr
+
+	case cli.OutputFormatText:
+		for _, v := range problems {
+			ctx.PrintPrimaryOutput(fmt.Sprintf("[%s] %s: %s", v.Type, v.RecordID, v.Message))
+		}
+		a.printSummary(ctx, problems, updated)
+		return exitErr
+
+	case cli.OutputFormatJson:
+		arr := langext.A{}
+		for _, v := range problems {
+			arr = append(arr, langext.H{"type": v.Type, "id": v.RecordID, "message": v.Message})
+		}
+		ctx.PrintPrimaryOutputJSON(langext.H{"valid": len(problems) == 0, "problems": arr, "updated": updated})
+		return exitErr
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
+
+func (a *CLIArgumentsBookmarksCheck) printSummary(ctx *cli.FFSContext, problems []bookmarkcheck.Problem, updated []string) {
+	if len(problems) == 0 {
+		ctx.PrintPrimaryOutput("Okay.")
+	} else if a.Fix {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Found %d problems, updated %d records.", len(problems), len(updated)))
+	} else {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Found %d problems (use --fix to repair them).", len(problems)))
+	}
+}
@@ -0,0 +1,261 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"github.com/joomcode/errorx"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"strconv"
+	"time"
+)
+
+type CLIArgumentsBookmarksCopy struct {
+	RecordID string
+	ParentID string
+	Mkdirs   bool
+	Position int
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksCopy() *CLIArgumentsBookmarksCopy {
+	return &CLIArgumentsBookmarksCopy{
+		ParentID: "",
+		Mkdirs:   false,
+		Position: -1,
+	}
+}
+
+func (a *CLIArgumentsBookmarksCopy) Mode() cli.Mode {
+	return cli.ModeBookmarksCopy
+}
+
+func (a *CLIArgumentsBookmarksCopy) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsBookmarksCopy) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksCopy) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks copy <id|path> --parent <id|path>", "Copy a bookmark (or a folder with all its entries) into another folder"},
+		{"          [--mkdirs]", "Create the missing folders of the parent path"},
+		{"          [--position=<idx>]", "The position of the copy in the parent (0 = first, default is last). Can use negative indizes."},
+	}
+}
+
+func (a *CLIArgumentsBookmarksCopy) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks copy <id|path> --parent <id|path> [--mkdirs] [--position <idx>]",
+		"",
+		"Duplicate an existing bookmark entry (folders are copied with all their descendants) into the folder <parent>",
+		"",
+		"All copied entries get new record-IDs.",
+		"Instead of an ID you can also use the root folders `menu`, `toolbar`, `unfiled` (or `other`) and `mobile` as the parent.",
+		"Both the entry and the parent can also be specified as a path of titles, starting with a root folder (e.g. `toolbar/Dev/Go docs`). Use `\\/` for a `/` in a title.",
+		"With --mkdirs the missing folders of the parent path are created.",
+		"With --position you can specify the position in the parent folder. The left-most position is 0 and the last position is len(folder.children). You can also use negative indizes: -1 is the last position and -2 the second-last etc. An invalid position throws an error.",
+		"If the position is negative you _have_ to use the --position=XX syntax. (Writing `--position XX` will result in a parser error)",
+		"",
+		"Outputs the RecordID of the copy on success.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksCopy) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.RecordID = positionalArgs[0]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "parent" && arg.Value != nil {
+			a.ParentID = a.resolveParentAlias(*arg.Value)
+			continue
+		}
+		if arg.Key == "mkdirs" && arg.Value == nil {
+			a.Mkdirs = true
+			continue
+		}
+		if arg.Key == "position" && arg.Value != nil {
+			if v, err := strconv.ParseInt(*arg.Value, 10, 32); err == nil {
+				a.Position = int(v)
+				continue
+			}
+			return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse number argument '--%s': '%s'", arg.Key, *arg.Value))
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.ParentID == "" {
+		return fferr.DirectOutput.New("Missing required argument: --parent")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksCopy) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Copy Bookmark]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("RecordID", a.RecordID)
+	ctx.PrintVerboseKV("ParentID", a.ParentID)
+	ctx.PrintVerboseKV("Position", a.Position)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.RecordID, err = a.resolveBookmarkRef(ctx, client, session, a.RecordID, false)
+	if err != nil {
+		return err
+	}
+
+	a.ParentID, err = a.resolveBookmarkRef(ctx, client, session, a.ParentID, a.Mkdirs)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query bookmarks")
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	bmrec, ok := coll.bookmarks[a.RecordID]
+	if !ok {
+		return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, "Record not found")
+	}
+
+	if a.RecordID == consts.BookmarkIDPlaces || langext.InArray(a.RecordID, consts.BookmarkRootIDs) {
+		return fferr.NewDirectOutput(consts.ExitcodeError, fmt.Sprintf("The root folder '%s' cannot be copied", a.RecordID))
+	}
+
+	node, ok := a.subtree(ctx, coll, a.RecordID)
+	if !ok {
+		ctx.PrintVerbose(fmt.Sprintf("[Warn] Record %s is not part of a valid tree, only the record itself is copied", a.RecordID))
+		node = &models.BookmarkTreeRecord{BookmarkRecord: bmrec, ResolvedChildren: make([]*models.BookmarkTreeRecord, 0)}
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Find parent")
+
+	parentRecord, ok := coll.records[a.ParentID]
+	if !ok && langext.InArray(a.ParentID, consts.BookmarkRootIDs) {
+		ctx.PrintVerbose(fmt.Sprintf("Root folder '%s' does not exist yet, it will be created", a.ParentID))
+		parentRecord, err = a.newRootRecord(a.ParentID)
+		if err != nil {
+			return err
+		}
+	} else if !ok {
+		return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, fmt.Sprintf("parent-record with ID '%s' not found", a.ParentID))
+	}
+
+	parent, err := models.UnmarshalBookmark(ctx, parentRecord)
+	if err != nil {
+		return errorx.Decorate(err, "failed to decode parent-record")
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[3] Copy entries")
+
+	entries := a.flattenTree(node)
+
+	newIDs := make(map[string]string, len(entries))
+	for _, v := range entries {
+		newIDs[v.ID] = a.newBookmarkID()
+		ctx.PrintVerbose(fmt.Sprintf("Copy %s -> %s", v.ID, newIDs[v.ID]))
+	}
+
+	newParentIDs := make(map[string]string, len(entries))
+	for _, v := range entries {
+		for _, child := range v.ResolvedChildren {
+			newParentIDs[child.ID] = newIDs[v.ID]
+		}
+	}
+
+	newRootID := newIDs[a.RecordID]
+
+	parent, plainParent, normpos, err := a.moveChild(ctx, parentRecord, parent, newRootID, a.Position)
+	if err != nil {
+		return err
+	}
+
+	payloads := make([]bookmarkPayload, 0, len(entries)+1)
+
+	for _, v := range entries {
+		plain, err := a.copyPayload(coll.records[v.ID], v, newIDs, newParentIDs, parent, normpos)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, bookmarkPayload{ID: newIDs[v.ID], Plain: plain})
+	}
+
+	// the parent is written last, so the copy only becomes visible once all entries exist
+	payloads = append(payloads, bookmarkPayload{ID: parent.ID, Plain: []byte(plainParent)})
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[4] Write records")
+
+	err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	ctx.PrintPrimaryOutput(newRootID)
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksCopy) copyPayload(record models.Record, node *models.BookmarkTreeRecord, newIDs map[string]string, newParentIDs map[string]string, parent models.BookmarkRecord, normpos int) ([]byte, error) {
+	var err error
+
+	plain := record.DecodedData
+
+	patch := func(key string, value any) {
+		if err == nil {
+			plain, err = langext.PatchJson(plain, key, value)
+		}
+	}
+
+	patch("id", newIDs[node.ID])
+	patch("dateAdded", time.Now().UnixMilli())
+
+	if node.ID == a.RecordID {
+		patch("parentid", parent.ID)
+		patch("parentName", parent.Title)
+		if node.Type == models.BookmarkTypeSeparator {
+			patch("pos", normpos)
+		}
+	} else {
+		patch("parentid", newParentIDs[node.ID])
+	}
+
+	if node.Type == models.BookmarkTypeFolder || node.Type == models.BookmarkTypeLivemark {
+		children := make([]string, 0, len(node.ResolvedChildren))
+		for _, v := range node.ResolvedChildren {
+			children = append(children, newIDs[v.ID])
+		}
+		patch("children", children)
+	}
+
+	if err != nil {
+		return nil, errorx.Decorate(err, "failed to patch data of copied record")
+	}
+
+	return plain, nil
+}
@@ -32,8 +32,8 @@ func (a *CLIArgumentsBookmarksCreateBase) ShortHelp() [][]string {
 
 func (a *CLIArgumentsBookmarksCreateBase) FullHelp() []string {
 	r := []string{
-		"$> ffsclient bookmarks create (folder|bookmark|separator)",
-		"=========================================================",
+		"$> ffsclient bookmarks create (folder|bookmark|separator|query|livemark)",
+		"========================================================================",
 		"",
 		"",
 	}
@@ -48,7 +48,7 @@ func (a *CLIArgumentsBookmarksCreateBase) FullHelp() []string {
 }
 
 func (a *CLIArgumentsBookmarksCreateBase) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
-	return fferr.DirectOutput.New("ffsclient bookmarks create must be called with a specific type (eg `ffsclient bookmarks create folder`), possible types are [bookmark | folder | separator]")
+	return fferr.DirectOutput.New("ffsclient bookmarks create must be called with a specific type (eg `ffsclient bookmarks create folder`), possible types are [bookmark | folder | separator | query | livemark]")
 }
 
 func (a *CLIArgumentsBookmarksCreateBase) Execute(ctx *cli.FFSContext) error {
@@ -16,6 +16,7 @@ import (
 type CLIArgumentsBookmarksCreateFolder struct {
 	Title    string
 	ParentID string
+	Mkdirs   bool
 	Position int
 
 	CLIArgumentsBookmarksUtil
@@ -23,7 +24,8 @@ type CLIArgumentsBookmarksCreateFolder struct {
 
 func NewCLIArgumentsBookmarksCreateFolder() *CLIArgumentsBookmarksCreateFolder {
 	return &CLIArgumentsBookmarksCreateFolder{
-		ParentID: "unfiled",
+		ParentID: consts.BookmarkIDUnfiled,
+		Mkdirs:   false,
 		Position: -1,
 	}
 }
@@ -43,19 +45,23 @@ func (a *CLIArgumentsBookmarksCreateFolder) AvailableOutputFormats() []cli.Outpu
 func (a *CLIArgumentsBookmarksCreateFolder) ShortHelp() [][]string {
 	return [][]string{
 		{"ffsclient bookmarks create folder <title>", "Insert a new bookmark-folder"},
-		{"          [--parent <id>]", "Specify the ID of the parent folder (if not specified the entry lives under `unfiled`)"},
+		{"          [--parent <id|path>]", "Specify the ID or the path (e.g. `toolbar/Dev`) of the parent folder, or one of the roots menu|toolbar|unfiled|mobile (if not specified the entry lives under `unfiled`)"},
+		{"          [--mkdirs]", "Create the missing folders of the parent path"},
 		{"          [--position=<idx>]", "The position of the entry in the parent (0 = first, default is last). Can use negative indizes."},
 	}
 }
 
 func (a *CLIArgumentsBookmarksCreateFolder) FullHelp() []string {
 	return []string{
-		"$> ffsclient bookmarks create folder <title> [--parent <id>] [--position <idx>]",
+		"$> ffsclient bookmarks create folder <title> [--parent <id|path>] [--mkdirs] [--position <idx>]",
 		"",
 		"Create a new bookmark with the type [folder]",
 		"",
 		"The field <title> must be specified.",
 		"With --parent you can specify the ID of the parent folder. Throws an error if the parent does not exist or is not an folder. The default value is `unfiled`",
+		"Instead of an ID you can also use the root folders `menu`, `toolbar`, `unfiled` (or `other`) and `mobile`, they are created if they do not exist yet.",
+		"The parent can also be specified as a path of folder titles, starting with a root folder (e.g. `toolbar/Dev/Go`). Use `\\/` for a `/` in a title.",
+		"With --mkdirs the missing folders of the parent path are created.",
 		"With --position you can specify the position in the parent folder. The left-most position is 0 and the last position is len(folder.children). You can also use negative indizes: -1 is the last position and -2 the second-last etc. An invalid position throws an error.",
 		"If the position is negative you _have_ to use the --position=XX syntax. (Writing `--position XX` will result in a parser error)",
 		"",
@@ -68,7 +74,11 @@ func (a *CLIArgumentsBookmarksCreateFolder) Init(positionalArgs []string, option
 
 	for _, arg := range optionArgs {
 		if arg.Key == "parent" && arg.Value != nil {
-			a.ParentID = *arg.Value
+			a.ParentID = a.resolveParentAlias(*arg.Value)
+			continue
+		}
+		if arg.Key == "mkdirs" && arg.Value == nil {
+			a.Mkdirs = true
 			continue
 		}
 		if arg.Key == "position" && arg.Value != nil {
@@ -103,7 +113,12 @@ func (a *CLIArgumentsBookmarksCreateFolder) Execute(ctx *cli.FFSContext) error {
 
 	ctx.PrintVerboseHeader("[1] Search for parent")
 
-	parent, newParentPayload, _, err := a.calculateParent(ctx, client, session, recordID, a.ParentID, a.Position)
+	parentID, err := a.resolveBookmarkRef(ctx, client, session, a.ParentID, a.Mkdirs)
+	if err != nil {
+		return err
+	}
+
+	parent, newParentPayload, _, err := a.calculateParent(ctx, client, session, recordID, parentID, a.Position)
 	if err != nil {
 		return errorx.Decorate(err, "failed to find+calculate parent")
 	}
@@ -0,0 +1,199 @@
+package impl
+
+import (
+	"encoding/json"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"github.com/joomcode/errorx"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"strconv"
+	"time"
+)
+
+type CLIArgumentsBookmarksCreateLivemark struct {
+	Title    string
+	FeedURL  string
+	SiteURL  string
+	ParentID string
+	Mkdirs   bool
+	Position int
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksCreateLivemark() *CLIArgumentsBookmarksCreateLivemark {
+	return &CLIArgumentsBookmarksCreateLivemark{
+		SiteURL:  "",
+		ParentID: consts.BookmarkIDUnfiled,
+		Mkdirs:   false,
+		Position: -1,
+	}
+}
+
+func (a *CLIArgumentsBookmarksCreateLivemark) Mode() cli.Mode {
+	return cli.ModeBookmarksCreateLivemark
+}
+
+func (a *CLIArgumentsBookmarksCreateLivemark) PositionArgCount() (*int, *int) {
+	return langext.Ptr(2), langext.Ptr(2)
+}
+
+func (a *CLIArgumentsBookmarksCreateLivemark) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksCreateLivemark) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks create livemark <title> <feed-url>", "Insert a new livemark (RSS/Atom feed folder)"},
+		{"          [--site-url <url>]", "Specify the URL of the website of the feed"},
+		{"          [--parent <id|path>]", "Specify the ID or the path (e.g. `toolbar/Dev`) of the parent folder, or one of the roots menu|toolbar|unfiled|mobile (if not specified the entry lives under `unfiled`)"},
+		{"          [--mkdirs]", "Create the missing folders of the parent path"},
+		{"          [--position=<idx>]", "The position of the entry in the parent (0 = first, default is last). Can use negative indizes."},
+	}
+}
+
+func (a *CLIArgumentsBookmarksCreateLivemark) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks create livemark <title> <feed-url> [--site-url <url>] [--parent <id|path>] [--mkdirs] [--position <idx>]",
+		"",
+		"Create a new bookmark with the type [livemark]",
+		"",
+		"The fields <title> and <feed-url> must be specified.",
+		"Livemarks are folders whose entries are loaded from the feed, they are created without children.",
+		"Note that current firefox versions do not support livemarks anymore.",
+		"With --parent you can specify the ID of the parent folder. Throws an error if the parent does not exist or is not an folder. The default value is `unfiled`",
+		"Instead of an ID you can also use the root folders `menu`, `toolbar`, `unfiled` (or `other`) and `mobile`, they are created if they do not exist yet.",
+		"The parent can also be specified as a path of folder titles, starting with a root folder (e.g. `toolbar/Dev/Go`). Use `\\/` for a `/` in a title.",
+		"With --mkdirs the missing folders of the parent path are created.",
+		"With --position you can specify the position in the parent folder. The left-most position is 0 and the last position is len(folder.children). You can also use negative indizes: -1 is the last position and -2 the second-last etc. An invalid position throws an error.",
+		"If the position is negative you _have_ to use the --position=XX syntax. (Writing `--position XX` will result in a parser error)",
+		"",
+		"Outputs the RecordID of the newly created entry on success.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksCreateLivemark) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Title = positionalArgs[0]
+	a.FeedURL = positionalArgs[1]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "site-url" && arg.Value != nil {
+			a.SiteURL = *arg.Value
+			continue
+		}
+		if arg.Key == "parent" && arg.Value != nil {
+			a.ParentID = a.resolveParentAlias(*arg.Value)
+			continue
+		}
+		if arg.Key == "mkdirs" && arg.Value == nil {
+			a.Mkdirs = true
+			continue
+		}
+		if arg.Key == "position" && arg.Value != nil {
+			if v, err := strconv.ParseInt(*arg.Value, 10, 32); err == nil {
+				a.Position = int(v)
+				continue
+			}
+			return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse number argument '--%s': '%s'", arg.Key, *arg.Value))
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksCreateLivemark) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Create Bookmark<Livemark>]")
+	ctx.PrintVerbose("")
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	recordID := a.newBookmarkID()
+
+	now := time.Now()
+
+	ctx.PrintVerboseHeader("[1] Search for parent")
+
+	parentID, err := a.resolveBookmarkRef(ctx, client, session, a.ParentID, a.Mkdirs)
+	if err != nil {
+		return err
+	}
+
+	parent, newParentPayload, _, err := a.calculateParent(ctx, client, session, recordID, parentID, a.Position)
+	if err != nil {
+		return errorx.Decorate(err, "failed to find+calculate parent")
+	}
+
+	ctx.PrintVerbose("Found Record Parent record: '" + parent.ID + "'")
+
+	ctx.PrintVerboseHeader("[2] Create new record")
+
+	bso := models.BookmarkCreatePayloadSchema{
+		ID:         recordID,
+		Type:       string(models.BookmarkTypeLivemark),
+		DateAdded:  now.UnixMilli(),
+		ParentID:   parent.ID,
+		ParentName: parent.Title,
+
+		Title:    langext.Ptr(a.Title),
+		Children: langext.Ptr([]string{}),
+		FeedURI:  langext.Ptr(a.FeedURL),
+		SiteURI:  langext.Ptr(a.SiteURL),
+	}
+
+	plainPayload, err := json.Marshal(bso)
+	if err != nil {
+		return errorx.Decorate(err, "failed to marshal BSO json")
+	}
+
+	payloadNewRecord, err := client.EncryptPayload(ctx, session, consts.CollectionBookmarks, string(plainPayload))
+	if err != nil {
+		return err
+	}
+
+	update := models.RecordUpdate{
+		ID:      recordID,
+		Payload: langext.Ptr(payloadNewRecord),
+	}
+
+	err = client.PutRecord(ctx, session, consts.CollectionBookmarks, update, true, false)
+	if err != nil {
+		return err
+	}
+
+	ctx.PrintVerboseHeader("[3] Update parent record")
+
+	payloadParent, err := client.EncryptPayload(ctx, session, consts.CollectionBookmarks, newParentPayload)
+	if err != nil {
+		return err
+	}
+
+	updateParent := models.RecordUpdate{
+		ID:      parent.ID,
+		Payload: langext.Ptr(payloadParent),
+	}
+
+	err = client.PutRecord(ctx, session, consts.CollectionBookmarks, updateParent, false, false)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	ctx.PrintPrimaryOutput(recordID)
+	return nil
+}
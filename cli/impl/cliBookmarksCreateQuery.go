@@ -0,0 +1,244 @@
+package impl
+
+import (
+	"encoding/json"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"github.com/joomcode/errorx"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"strconv"
+	"time"
+)
+
+type CLIArgumentsBookmarksCreateQuery struct {
+	Title         string
+	URL           string
+	Description   string
+	LoadInSidebar bool
+	Tags          []string
+	Keyword       string
+	FolderName    *string
+	QueryID       string
+	ParentID      string
+	Mkdirs        bool
+	Position      int
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksCreateQuery() *CLIArgumentsBookmarksCreateQuery {
+	return &CLIArgumentsBookmarksCreateQuery{
+		Description:   "",
+		LoadInSidebar: false,
+		Tags:          make([]string, 0),
+		Keyword:       "",
+		FolderName:    nil,
+		QueryID:       "",
+		ParentID:      consts.BookmarkIDUnfiled,
+		Mkdirs:        false,
+		Position:      -1,
+	}
+}
+
+func (a *CLIArgumentsBookmarksCreateQuery) Mode() cli.Mode {
+	return cli.ModeBookmarksCreateQuery
+}
+
+func (a *CLIArgumentsBookmarksCreateQuery) PositionArgCount() (*int, *int) {
+	return langext.Ptr(2), langext.Ptr(2)
+}
+
+func (a *CLIArgumentsBookmarksCreateQuery) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksCreateQuery) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks create query <title> <query>", "Insert a new query (smart bookmark, e.g. `place:sort=8&maxResults=10`)"},
+		{"          [--description <desc>]", "Specify the bookmark description"},
+		{"          [--load-in-sidebar]", "If specified the `LoadInSidebar` field is set to true (default is false)"},
+		{"          [--tag <tag>]", "Add a tag to the bookmark, specify multiple times to add multiple tags"},
+		{"          [--keyword <kw>]", "Specify the keyword (to activate the bookmark from the location bar)"},
+		{"          [--folder-name <name>]", "Specify the `folderName` (default is the tag of a tag-query)"},
+		{"          [--query-id <id>]", "Specify the `queryId` (used by the smart bookmarks of older firefox versions)"},
+		{"          [--parent <id|path>]", "Specify the ID or the path (e.g. `toolbar/Dev`) of the parent folder, or one of the roots menu|toolbar|unfiled|mobile (if not specified the entry lives under `unfiled`)"},
+		{"          [--mkdirs]", "Create the missing folders of the parent path"},
+		{"          [--position=<idx>]", "The position of the entry in the parent (0 = first, default is last). Can use negative indizes."},
+	}
+}
+
+func (a *CLIArgumentsBookmarksCreateQuery) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks create query <title> <query> [--description <desc>] [--load-in-sidebar] [--tag <tag>] [--keyword <kw>] [--folder-name <name>] [--query-id <id>] [--parent <id|path>] [--mkdirs] [--position <idx>]",
+		"",
+		"Create a new bookmark with the type [query]",
+		"",
+		"The fields <title> and <query> must be specified, the query must be a `place:` URI (e.g. `place:sort=8&maxResults=10` or `place:tag=go`).",
+		"For tag-queries (`place:tag=<tag>`) the folderName is set to the tag, you can override it with --folder-name.",
+		"If --load-in-sidebar is not specified the default value of false is used.",
+		"You can specify one or more tags by supplying multiple --tag parameter.",
+		"With --keyword you can specify an alias to activate the bookmark from the location bar.",
+		"With --parent you can specify the ID of the parent folder. Throws an error if the parent does not exist or is not an folder. The default value is `unfiled`",
+		"Instead of an ID you can also use the root folders `menu`, `toolbar`, `unfiled` (or `other`) and `mobile`, they are created if they do not exist yet.",
+		"The parent can also be specified as a path of folder titles, starting with a root folder (e.g. `toolbar/Dev/Go`). Use `\\/` for a `/` in a title.",
+		"With --mkdirs the missing folders of the parent path are created.",
+		"With --position you can specify the position in the parent folder. The left-most position is 0 and the last position is len(folder.children). You can also use negative indizes: -1 is the last position and -2 the second-last etc. An invalid position throws an error.",
+		"If the position is negative you _have_ to use the --position=XX syntax. (Writing `--position XX` will result in a parser error)",
+		"",
+		"Outputs the RecordID of the newly created entry on success.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksCreateQuery) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Title = positionalArgs[0]
+	a.URL = positionalArgs[1]
+
+	if !models.IsQueryURI(a.URL) {
+		return fferr.DirectOutput.New("The query must be a `place:` URI (e.g. `place:sort=8&maxResults=10`)")
+	}
+
+	for _, arg := range optionArgs {
+		if arg.Key == "description" && arg.Value != nil {
+			a.Description = *arg.Value
+			continue
+		}
+		if arg.Key == "load-in-sidebar" && arg.Value == nil {
+			a.LoadInSidebar = true
+			continue
+		}
+		if arg.Key == "tag" && arg.Value != nil {
+			a.Tags = append(a.Tags, *arg.Value)
+			continue
+		}
+		if arg.Key == "keyword" && arg.Value != nil {
+			a.Keyword = *arg.Value
+			continue
+		}
+		if arg.Key == "folder-name" && arg.Value != nil {
+			a.FolderName = langext.Ptr(*arg.Value)
+			continue
+		}
+		if arg.Key == "query-id" && arg.Value != nil {
+			a.QueryID = *arg.Value
+			continue
+		}
+		if arg.Key == "parent" && arg.Value != nil {
+			a.ParentID = a.resolveParentAlias(*arg.Value)
+			continue
+		}
+		if arg.Key == "mkdirs" && arg.Value == nil {
+			a.Mkdirs = true
+			continue
+		}
+		if arg.Key == "position" && arg.Value != nil {
+			if v, err := strconv.ParseInt(*arg.Value, 10, 32); err == nil {
+				a.Position = int(v)
+				continue
+			}
+			return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse number argument '--%s': '%s'", arg.Key, *arg.Value))
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksCreateQuery) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Create Bookmark<Query>]")
+	ctx.PrintVerbose("")
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	recordID := a.newBookmarkID()
+
+	now := time.Now()
+
+	ctx.PrintVerboseHeader("[1] Search for parent")
+
+	parentID, err := a.resolveBookmarkRef(ctx, client, session, a.ParentID, a.Mkdirs)
+	if err != nil {
+		return err
+	}
+
+	parent, newParentPayload, _, err := a.calculateParent(ctx, client, session, recordID, parentID, a.Position)
+	if err != nil {
+		return errorx.Decorate(err, "failed to find+calculate parent")
+	}
+
+	ctx.PrintVerbose("Found Record Parent record: '" + parent.ID + "'")
+
+	ctx.PrintVerboseHeader("[2] Create new record")
+
+	bso := models.BookmarkCreatePayloadSchema{
+		ID:         recordID,
+		Type:       string(models.BookmarkTypeQuery),
+		DateAdded:  now.UnixMilli(),
+		ParentID:   parent.ID,
+		ParentName: parent.Title,
+
+		Title:         langext.Ptr(a.Title),
+		URI:           langext.Ptr(a.URL),
+		Description:   langext.Ptr(a.Description),
+		LoadInSidebar: langext.Ptr(a.LoadInSidebar),
+		Tags:          langext.Ptr(a.Tags),
+		Keyword:       langext.Ptr(a.Keyword),
+		FolderName:    langext.Ptr(langext.Coalesce(a.FolderName, models.QueryFolderName(a.URL))),
+		QueryID:       langext.Ptr(a.QueryID),
+	}
+
+	plainPayload, err := json.Marshal(bso)
+	if err != nil {
+		return errorx.Decorate(err, "failed to marshal BSO json")
+	}
+
+	payloadNewRecord, err := client.EncryptPayload(ctx, session, consts.CollectionBookmarks, string(plainPayload))
+	if err != nil {
+		return err
+	}
+
+	update := models.RecordUpdate{
+		ID:      recordID,
+		Payload: langext.Ptr(payloadNewRecord),
+	}
+
+	err = client.PutRecord(ctx, session, consts.CollectionBookmarks, update, true, false)
+	if err != nil {
+		return err
+	}
+
+	ctx.PrintVerboseHeader("[3] Update parent record")
+
+	payloadParent, err := client.EncryptPayload(ctx, session, consts.CollectionBookmarks, newParentPayload)
+	if err != nil {
+		return err
+	}
+
+	updateParent := models.RecordUpdate{
+		ID:      parent.ID,
+		Payload: langext.Ptr(payloadParent),
+	}
+
+	err = client.PutRecord(ctx, session, consts.CollectionBookmarks, updateParent, false, false)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	ctx.PrintPrimaryOutput(recordID)
+	return nil
+}
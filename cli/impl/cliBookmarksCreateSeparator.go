@@ -15,6 +15,7 @@ import (
 
 type CLIArgumentsBookmarksCreateSeparator struct {
 	ParentID string
+	Mkdirs   bool
 	Position int
 
 	CLIArgumentsBookmarksUtil
@@ -22,7 +23,8 @@ type CLIArgumentsBookmarksCreateSeparator struct {
 
 func NewCLIArgumentsBookmarksCreateSeparator() *CLIArgumentsBookmarksCreateSeparator {
 	return &CLIArgumentsBookmarksCreateSeparator{
-		ParentID: "unfiled",
+		ParentID: consts.BookmarkIDUnfiled,
+		Mkdirs:   false,
 		Position: -1,
 	}
 }
@@ -42,18 +44,22 @@ func (a *CLIArgumentsBookmarksCreateSeparator) AvailableOutputFormats() []cli.Ou
 func (a *CLIArgumentsBookmarksCreateSeparator) ShortHelp() [][]string {
 	return [][]string{
 		{"ffsclient bookmarks create separator", "Insert a new bookmark-separator"},
-		{"          [--parent <id>]", "Specify the ID of the parent folder (if not specified the entry lives under `unfiled`)"},
+		{"          [--parent <id|path>]", "Specify the ID or the path (e.g. `toolbar/Dev`) of the parent folder, or one of the roots menu|toolbar|unfiled|mobile (if not specified the entry lives under `unfiled`)"},
+		{"          [--mkdirs]", "Create the missing folders of the parent path"},
 		{"          [--position=<idx>]", "The position of the entry in the parent (0 = first, default is last). Can use negative indizes."},
 	}
 }
 
 func (a *CLIArgumentsBookmarksCreateSeparator) FullHelp() []string {
 	return []string{
-		"$> ffsclient bookmarks create separator [--parent <id>] [--position <idx>]",
+		"$> ffsclient bookmarks create separator [--parent <id|path>] [--mkdirs] [--position <idx>]",
 		"",
 		"Create a new bookmark with the type [separator]",
 		"",
 		"With --parent you can specify the ID of the parent folder. Throws an error if the parent does not exist or is not an folder. The default value is `unfiled`",
+		"Instead of an ID you can also use the root folders `menu`, `toolbar`, `unfiled` (or `other`) and `mobile`, they are created if they do not exist yet.",
+		"The parent can also be specified as a path of folder titles, starting with a root folder (e.g. `toolbar/Dev/Go`). Use `\\/` for a `/` in a title.",
+		"With --mkdirs the missing folders of the parent path are created.",
 		"With --position you can specify the position in the parent folder. The left-most position is 0 and the last position is len(folder.children). You can also use negative indizes: -1 is the last position and -2 the second-last etc. An invalid position throws an error.",
 		"If the position is negative you _have_ to use the --position=XX syntax. (Writing `--position XX` will result in a parser error)",
 		"",
@@ -64,7 +70,11 @@ func (a *CLIArgumentsBookmarksCreateSeparator) FullHelp() []string {
 func (a *CLIArgumentsBookmarksCreateSeparator) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
 	for _, arg := range optionArgs {
 		if arg.Key == "parent" && arg.Value != nil {
-			a.ParentID = *arg.Value
+			a.ParentID = a.resolveParentAlias(*arg.Value)
+			continue
+		}
+		if arg.Key == "mkdirs" && arg.Value == nil {
+			a.Mkdirs = true
 			continue
 		}
 		if arg.Key == "position" && arg.Value != nil {
@@ -99,7 +109,12 @@ func (a *CLIArgumentsBookmarksCreateSeparator) Execute(ctx *cli.FFSContext) erro
 
 	ctx.PrintVerboseHeader("[1] Search for parent")
 
-	parent, newParentPayload, realChildPos, err := a.calculateParent(ctx, client, session, recordID, a.ParentID, a.Position)
+	parentID, err := a.resolveBookmarkRef(ctx, client, session, a.ParentID, a.Mkdirs)
+	if err != nil {
+		return err
+	}
+
+	parent, newParentPayload, realChildPos, err := a.calculateParent(ctx, client, session, recordID, parentID, a.Position)
 	if err != nil {
 		return errorx.Decorate(err, "failed to find+calculate parent")
 	}
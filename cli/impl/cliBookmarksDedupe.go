@@ -0,0 +1,290 @@
+package impl
+
+import (
+	"encoding/json"
+	"ffsyncclient/bookmarkdedupe"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"slices"
+)
+
+type CLIArgumentsBookmarksDedupe struct {
+	PerFolder bool
+	Keep      bookmarkdedupe.KeepStrategy
+	DryRun    bool
+	Yes       bool
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksDedupe() *CLIArgumentsBookmarksDedupe {
+	return &CLIArgumentsBookmarksDedupe{
+		PerFolder: false,
+		Keep:      bookmarkdedupe.KeepOldest,
+		DryRun:    false,
+		Yes:       false,
+	}
+}
+
+func (a *CLIArgumentsBookmarksDedupe) Mode() cli.Mode {
+	return cli.ModeBookmarksDedupe
+}
+
+func (a *CLIArgumentsBookmarksDedupe) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsBookmarksDedupe) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksDedupe) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks dedupe", "Find and merge duplicate bookmarks (same normalized URL)"},
+		{"          [--per-folder]", "Only treat bookmarks in the same folder as duplicates"},
+		{"          [--keep <oldest|newest|first-in-tree>]", "Which bookmark of a group is kept (default: oldest)"},
+		{"          [--dry-run]", "Only show the duplicates, do not change anything"},
+		{"          [--yes]", "Do not ask for confirmation"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksDedupe) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks dedupe [--per-folder] [--keep <oldest|newest|first-in-tree>] [--dry-run] [--yes]",
+		"",
+		"Find and merge duplicate bookmarks",
+		"",
+		"Bookmarks are grouped by their normalized URL (lowercase scheme and host, without default port and trailing slash).",
+		"By default duplicates are searched in all folders, with --per-folder only bookmarks in the same folder are grouped.",
+		"",
+		"Of every group one bookmark is kept:",
+		"  - oldest:        the bookmark with the earliest `dateAdded` (default)",
+		"  - newest:        the bookmark with the latest `dateAdded`",
+		"  - first-in-tree: the first bookmark in the tree (menu, toolbar, unfiled, mobile)",
+		"The tags of all bookmarks in the group are merged onto the kept bookmark, its keyword is taken from the others if it has none.",
+		"All other bookmarks of the group are deleted (only marked with {deleted:true} as a tombstone) and removed from the `children` of their parents.",
+		"",
+		"If --dry-run is specified nothing is changed.",
+		"Before changing anything you are asked for confirmation, this can be skipped with --yes.",
+		"All changed records are written in a single batch upload, the request fails if the bookmarks were modified by another client in the meantime.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksDedupe) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if arg.Key == "per-folder" && arg.Value == nil {
+			a.PerFolder = true
+			continue
+		}
+		if arg.Key == "keep" && arg.Value != nil {
+			keep, err := bookmarkdedupe.ParseKeepStrategy(*arg.Value)
+			if err != nil {
+				return fferr.DirectOutput.New(err.Error())
+			}
+			a.Keep = keep
+			continue
+		}
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		if arg.Key == "yes" && arg.Value == nil {
+			a.Yes = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksDedupe) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Dedupe Bookmarks]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("PerFolder", a.PerFolder)
+	ctx.PrintVerboseKV("Keep", a.Keep)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query bookmarks")
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Find duplicates")
+
+	bookmarks := make([]models.BookmarkRecord, 0, len(coll.bookmarks))
+	for _, v := range coll.bookmarks {
+		bookmarks = append(bookmarks, v)
+	}
+
+	order := make(map[string]int, len(bookmarks))
+	roots, _, _ := a.calculateTree(ctx, bookmarks, nil)
+	for _, root := range roots {
+		for _, v := range a.flattenTree(root) {
+			order[v.ID] = len(order)
+		}
+	}
+
+	groups := bookmarkdedupe.Find(bookmarks, order, a.PerFolder, a.Keep)
+
+	if len(groups) == 0 {
+		ctx.PrintPrimaryOutput("No duplicate bookmarks found.")
+		return nil
+	}
+
+	deleteCount := 0
+	for i, grp := range groups {
+		a.printGroup(ctx, coll, i+1, grp)
+		deleteCount += len(grp.Entries) - 1
+	}
+
+	if a.DryRun {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Found %d groups of duplicates (%d bookmarks would be deleted), no changes were made (--dry-run).", len(groups), deleteCount))
+		return nil
+	}
+
+	if !a.Yes {
+		ok, err := a.confirm(fmt.Sprintf("Merge %d groups of duplicates and delete %d bookmarks?", len(groups), deleteCount))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			ctx.PrintPrimaryOutput("Aborted.")
+			return nil
+		}
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[3] Merge duplicates")
+
+	payloads, err := a.calculatePayloads(ctx, coll, groups)
+	if err != nil {
+		return err
+	}
+
+	err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+	if err != nil {
+		return err
+	}
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Merged %d groups of duplicates, deleted %d bookmarks.", len(groups), deleteCount))
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksDedupe) printGroup(ctx *cli.FFSContext, coll *bookmarkCollection, num int, grp bookmarkdedupe.Group) {
+	ctx.PrintPrimaryOutput(fmt.Sprintf("[%d] %s - %d bookmarks", num, grp.URL, len(grp.Entries)))
+
+	for i, v := range grp.Entries {
+		action := "delete"
+		if i == grp.Keep {
+			action = "keep  "
+		}
+		ctx.PrintPrimaryOutput(fmt.Sprintf("    %s  %s  %s  added:%s", action, v.ID, coll.path(v.ID), fmtOptDate(ctx, v.DateAdded)))
+	}
+
+	ctx.PrintPrimaryOutput("")
+}
+
+// calculatePayloads returns the patched survivors, the patched parents and the tombstones of the deleted bookmarks
+func (a *CLIArgumentsBookmarksDedupe) calculatePayloads(ctx *cli.FFSContext, coll *bookmarkCollection, groups []bookmarkdedupe.Group) ([]bookmarkPayload, error) {
+	var err error
+
+	payloads := make([]bookmarkPayload, 0)
+
+	removed := make(map[string]bool)
+	parentIDs := make([]string, 0)
+
+	for _, grp := range groups {
+		keep := grp.Entries[grp.Keep]
+		tags, keyword := bookmarkdedupe.Merge(grp)
+
+		plain := coll.records[keep.ID].DecodedData
+
+		if !slices.Equal(tags, keep.Tags) {
+			plain, err = langext.PatchJson(plain, "tags", tags)
+			if err != nil {
+				return nil, errorx.Decorate(err, "failed to patch data of record")
+			}
+		}
+		if keyword != keep.Keyword {
+			plain, err = langext.PatchJson(plain, "keyword", keyword)
+			if err != nil {
+				return nil, errorx.Decorate(err, "failed to patch data of record")
+			}
+		}
+
+		if string(plain) != string(coll.records[keep.ID].DecodedData) {
+			ctx.PrintVerbose("Update Record " + keep.ID)
+			payloads = append(payloads, bookmarkPayload{ID: keep.ID, Plain: plain})
+		}
+
+		for i, v := range grp.Entries {
+			if i == grp.Keep {
+				continue
+			}
+			removed[v.ID] = true
+			if !langext.InArray(v.ParentID, parentIDs) {
+				parentIDs = append(parentIDs, v.ParentID)
+			}
+		}
+	}
+
+	for _, parentID := range parentIDs {
+		parent, ok := coll.bookmarks[parentID]
+		if !ok {
+			ctx.PrintVerbose(fmt.Sprintf("Parent not found (parent-id := %s)", parentID))
+			continue
+		}
+
+		children := langext.ArrFilter(parent.Children, func(v string) bool { return !removed[v] })
+
+		plain, err := langext.PatchJson(coll.records[parentID].DecodedData, "children", children)
+		if err != nil {
+			return nil, errorx.Decorate(err, "failed to patch payload of parent")
+		}
+
+		ctx.PrintVerbose(fmt.Sprintf("Update Parent %s (remove %d children)", parentID, len(parent.Children)-len(children)))
+		payloads = append(payloads, bookmarkPayload{ID: parentID, Plain: plain})
+	}
+
+	for _, grp := range groups {
+		for i, v := range grp.Entries {
+			if i == grp.Keep {
+				continue
+			}
+
+			plain, err := json.Marshal(models.BookmarkTombstonePayloadSchema{ID: v.ID, Deleted: true})
+			if err != nil {
+				return nil, errorx.Decorate(err, "failed to marshal tombstone")
+			}
+
+			ctx.PrintVerbose("Delete Record " + v.ID)
+			payloads = append(payloads, bookmarkPayload{ID: v.ID, Plain: plain})
+		}
+	}
+
+	return payloads, nil
+}
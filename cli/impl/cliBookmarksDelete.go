@@ -1,23 +1,30 @@
 package impl
 
 import (
+	"encoding/json"
 	"ffsyncclient/cli"
 	"ffsyncclient/consts"
 	"ffsyncclient/fferr"
 	"ffsyncclient/models"
+	"ffsyncclient/syncclient"
 	"fmt"
 	"github.com/joomcode/errorx"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
 )
 
 type CLIArgumentsBookmarksDelete struct {
-	RecordID string
+	RecordID  string
+	Hard      bool
+	Recursive bool
 
 	CLIArgumentsBookmarksUtil
 }
 
 func NewCLIArgumentsBookmarksDelete() *CLIArgumentsBookmarksDelete {
-	return &CLIArgumentsBookmarksDelete{}
+	return &CLIArgumentsBookmarksDelete{
+		Hard:      false,
+		Recursive: false,
+	}
 }
 
 func (a *CLIArgumentsBookmarksDelete) Mode() cli.Mode {
@@ -34,16 +41,22 @@ func (a *CLIArgumentsBookmarksDelete) AvailableOutputFormats() []cli.OutputForma
 
 func (a *CLIArgumentsBookmarksDelete) ShortHelp() [][]string {
 	return [][]string{
-		{"ffsclient bookmarks delete <id>", "Delete the specified bookmark"},
+		{"ffsclient bookmarks delete <id|path>", "Delete the specified bookmark"},
+		{"          [--recursive]", "Also delete all entries in the folder (and its subfolders)"},
+		{"          [--hard]", "Completely delete the records instead of leaving tombstones"},
 	}
 }
 
 func (a *CLIArgumentsBookmarksDelete) FullHelp() []string {
 	return []string{
-		"$> ffsclient bookmarks delete <id> [--hard]",
+		"$> ffsclient bookmarks delete <id|path> [--recursive] [--hard]",
 		"",
 		"Delete the specific bookmark from the server",
 		"(Also modified the parent record and removes the <id> from its children)",
+		"The entry can also be specified as a path of titles, starting with a root folder (e.g. `toolbar/Dev/Go docs`). Use `\\/` for a `/` in a title.",
+		"",
+		"If --recursive is specified and the entry is a folder, all its descendants are deleted too (otherwise they are left as orphans on the server).",
+		"If --hard is specified we delete the records, otherwise we only add {deleted:true} to mark them as tombstones",
 	}
 }
 
@@ -51,6 +64,14 @@ func (a *CLIArgumentsBookmarksDelete) Init(positionalArgs []string, optionArgs [
 	a.RecordID = positionalArgs[0]
 
 	for _, arg := range optionArgs {
+		if arg.Key == "hard" && arg.Value == nil {
+			a.Hard = true
+			continue
+		}
+		if arg.Key == "recursive" && arg.Value == nil {
+			a.Recursive = true
+			continue
+		}
 		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
 	}
 
@@ -69,6 +90,17 @@ func (a *CLIArgumentsBookmarksDelete) Execute(ctx *cli.FFSContext) error {
 		return err
 	}
 
+	a.RecordID, err = a.resolveBookmarkRef(ctx, client, session, a.RecordID, false)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	if a.Recursive {
+		return a.executeRecursive(ctx, client, session)
+	}
+
 	// ========================================================================
 
 	ctx.PrintVerboseHeader("[0] Find bookmark")
@@ -103,9 +135,16 @@ func (a *CLIArgumentsBookmarksDelete) Execute(ctx *cli.FFSContext) error {
 		}
 	}
 
-	err = client.SoftDeleteRecord(ctx, session, consts.CollectionBookmarks, record.ID)
-	if err != nil {
-		return err
+	if a.Hard {
+		err = client.DeleteRecord(ctx, session, consts.CollectionBookmarks, record.ID)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = client.SoftDeleteRecord(ctx, session, consts.CollectionBookmarks, record.ID)
+		if err != nil {
+			return err
+		}
 	}
 
 	if parentFound {
@@ -147,6 +186,110 @@ func (a *CLIArgumentsBookmarksDelete) Execute(ctx *cli.FFSContext) error {
 		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
 	}
 
-	ctx.PrintPrimaryOutput("Bookmark " + a.RecordID + " marked as deleted")
+	if a.Hard {
+		ctx.PrintPrimaryOutput("Bookmark " + a.RecordID + " deleted")
+	} else {
+		ctx.PrintPrimaryOutput("Bookmark " + a.RecordID + " marked as deleted")
+	}
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksDelete) executeRecursive(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession) error {
+
+	if a.RecordID == consts.BookmarkIDPlaces || langext.InArray(a.RecordID, consts.BookmarkRootIDs) {
+		return fferr.NewDirectOutput(consts.ExitcodeError, fmt.Sprintf("The root folder '%s' cannot be deleted recursively", a.RecordID))
+	}
+
+	ctx.PrintVerboseHeader("[0] Query bookmarks")
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	bmrec, ok := coll.bookmarks[a.RecordID]
+	if !ok {
+		return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, "Record not found")
+	}
+
+	ctx.PrintVerboseHeader("[1] Calculate subtree")
+
+	node, ok := a.subtree(ctx, coll, a.RecordID)
+	if !ok {
+		ctx.PrintVerbose(fmt.Sprintf("[Warn] Record %s is not part of a valid tree, only the record itself is deleted", a.RecordID))
+		node = &models.BookmarkTreeRecord{BookmarkRecord: bmrec, ResolvedChildren: make([]*models.BookmarkTreeRecord, 0)}
+	}
+
+	ids := make([]string, 0)
+	for _, v := range a.flattenTree(node) {
+		ids = append(ids, v.ID)
+	}
+
+	ctx.PrintVerbose(fmt.Sprintf("Delete %d records", len(ids)))
+
+	ctx.PrintVerboseHeader("[2] Delete records")
+
+	payloads := make([]bookmarkPayload, 0, len(ids)+1)
+
+	if parentRecord, ok := coll.records[bmrec.ParentID]; ok {
+		parent := coll.bookmarks[bmrec.ParentID]
+
+		newChildren := make([]string, 0, len(parent.Children))
+		for _, v := range parent.Children {
+			if v != a.RecordID {
+				newChildren = append(newChildren, v)
+			} else {
+				ctx.PrintVerbose("Remove child-entry: " + v)
+			}
+		}
+
+		plainpayload, err := langext.PatchJson(parentRecord.DecodedData, "children", newChildren)
+		if err != nil {
+			return fferr.DirectOutput.Wrap(err, "failed to patch payload of parent")
+		}
+
+		payloads = append(payloads, bookmarkPayload{ID: parentRecord.ID, Plain: plainpayload})
+	} else {
+		ctx.PrintVerbose(fmt.Sprintf("No parent found (parent-id := %s)", bmrec.ParentID))
+	}
+
+	if a.Hard {
+		if len(payloads) > 0 {
+			err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+			if err != nil {
+				return err
+			}
+		}
+
+		err = client.DeleteRecords(ctx, session, consts.CollectionBookmarks, ids)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, id := range ids {
+			tombstone, err := json.Marshal(models.BookmarkTombstonePayloadSchema{ID: id, Deleted: true})
+			if err != nil {
+				return errorx.Decorate(err, "failed to marshal tombstone")
+			}
+			payloads = append(payloads, bookmarkPayload{ID: id, Plain: tombstone})
+		}
+
+		err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+		if err != nil {
+			return err
+		}
+	}
+
+	// ========================================================================
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	if a.Hard {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Bookmark %s and %d descendants deleted", a.RecordID, len(ids)-1))
+	} else {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Bookmark %s and %d descendants marked as deleted", a.RecordID, len(ids)-1))
+	}
 	return nil
 }
@@ -0,0 +1,467 @@
+package impl
+
+import (
+	"encoding/json"
+	"ffsyncclient/browserprofile"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/firefoxjsonfmt"
+	"ffsyncclient/models"
+	"ffsyncclient/mozlz4"
+	"ffsyncclient/netscapefmt"
+	"ffsyncclient/syncclient"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+type CLIArgumentsBookmarksImport struct {
+	File       *string
+	ProfileDir *string
+	ParentID   *string
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksImport() *CLIArgumentsBookmarksImport {
+	return &CLIArgumentsBookmarksImport{
+		File:       nil,
+		ProfileDir: nil,
+		ParentID:   nil,
+	}
+}
+
+func (a *CLIArgumentsBookmarksImport) Mode() cli.Mode {
+	return cli.ModeBookmarksImport
+}
+
+func (a *CLIArgumentsBookmarksImport) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsBookmarksImport) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksImport) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks import <file>", "Import bookmarks from a netscape bookmark file (bookmarks.html) or a firefox bookmark backup (json/jsonlz4)"},
+		{"ffsclient bookmarks import --from-profile <dir>", "Import the bookmarks of a local firefox or chromium profile"},
+		{"          [--parent <id>]", "Import everything into this folder (default: the matching root folders)"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksImport) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks import <file> [--parent <id>]",
+		"$> ffsclient bookmarks import --from-profile <dir> [--parent <id>]",
+		"",
+		"Import bookmarks from a file, the following formats are detected automatically:",
+		"  * netscape bookmark file (the bookmarks.html format that firefox, chrome, edge, etc can export)",
+		"  * firefox bookmark backup (json, or mozLz4 compressed jsonlz4 like the files in the firefox bookmarkbackups directory)",
+		"",
+		"Use `-` as <file> to read the file from stdin.",
+		"Without --parent the entries are imported into the bookmarks menu, the content of the bookmarks toolbar (PERSONAL_TOOLBAR_FOLDER) is imported into the toolbar.",
+		"The roots of a firefox backup (menu, toolbar, unfiled, mobile) are imported into the corresponding root folders.",
+		"",
+		"With --from-profile the bookmarks are read directly from a local browser profile directory instead of a file:",
+		"  * firefox: the places.sqlite database (e.g. ~/.mozilla/firefox/<profile>/)",
+		"  * chromium (chrome, edge, brave, etc): the Bookmarks file (e.g. ~/.config/chromium/Default/)",
+		"The browser should be closed, otherwise the newest changes may not yet be written to disk.",
+		"The bookmark bar of chromium is imported into the toolbar, the other bookmarks into the unfiled bookmarks.",
+		"",
+		"With --parent you can specify the ID of a folder that receives all entries (the roots are imported as normal folders).",
+		"",
+		"Folders with the same title in the same parent are merged, bookmarks with an URL that already exists in the same folder are skipped.",
+		"This makes it safe to import the same file multiple times.",
+		"The import fails if the bookmarks were modified by another client in the meantime.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksImport) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	if len(positionalArgs) > 0 {
+		a.File = langext.Ptr(positionalArgs[0])
+	}
+
+	for _, arg := range optionArgs {
+		if arg.Key == "parent" && arg.Value != nil {
+			a.ParentID = langext.Ptr(a.resolveParentAlias(*arg.Value))
+			continue
+		}
+		if arg.Key == "from-profile" && arg.Value != nil {
+			a.ProfileDir = langext.Ptr(*arg.Value)
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.File == nil && a.ProfileDir == nil {
+		return fferr.DirectOutput.New("Missing required argument: <file> or --from-profile")
+	}
+	if a.File != nil && a.ProfileDir != nil {
+		return fferr.DirectOutput.New("Must specify either <file> or --from-profile, not both")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksImport) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Import Bookmarks]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("File", a.File)
+	ctx.PrintVerboseKV("ProfileDir", a.ProfileDir)
+	ctx.PrintVerboseKV("Parent", a.ParentID)
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	var items []*models.BookmarkTreeRecord
+	var err error
+
+	if a.ProfileDir != nil {
+
+		ctx.PrintVerboseHeader("[1] Read profile")
+
+		items, err = browserprofile.ReadBookmarks(*a.ProfileDir)
+		if err != nil {
+			return fferr.WrapDirectOutput(err, consts.ExitcodeError, "Failed to read bookmarks from profile '"+*a.ProfileDir+"'")
+		}
+
+	} else {
+
+		ctx.PrintVerboseHeader("[1] Parse file")
+
+		var data []byte
+		if *a.File == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(*a.File)
+		}
+		if err != nil {
+			return fferr.WrapDirectOutput(err, consts.ExitcodeError, "Failed to read file '"+*a.File+"'")
+		}
+
+		items, err = a.parseBookmarkFile(ctx, data)
+		if err != nil {
+			return fferr.WrapDirectOutput(err, consts.ExitcodeError, "Failed to parse bookmark file")
+		}
+
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Import bookmarks")
+
+	stats, err := a.importBookmarkTree(ctx, client, session, items, a.ParentID)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Imported %d entries (%d bookmarks skipped as duplicates)", stats.Created, stats.Skipped))
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksImport) parseBookmarkFile(ctx *cli.FFSContext, data []byte) ([]*models.BookmarkTreeRecord, error) {
+	if mozlz4.IsMozLz4(data) {
+		ctx.PrintVerbose("Detected mozLz4 compressed file")
+
+		var err error
+		data, err = mozlz4.Decompress(data)
+		if err != nil {
+			return nil, errorx.Decorate(err, "failed to decompress mozLz4 file")
+		}
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		ctx.PrintVerbose("Detected firefox bookmark backup (json)")
+		return firefoxjsonfmt.Parse(data)
+	}
+
+	ctx.PrintVerbose("Detected netscape bookmark file")
+	return netscapefmt.Parse(data)
+}
+
+type bookmarkImportStats struct {
+	Created int
+	Skipped int
+}
+
+type bookmarkImporter struct {
+	records   map[string]models.Record
+	bookmarks map[string]models.BookmarkRecord
+
+	newRecords     []string // ordered IDs of new records
+	changedParents []string // ordered IDs of existing folders with new children
+
+	stats        bookmarkImportStats
+	now          time.Time
+	lastModified float64 // the newest `modified` timestamp of the collection (for X-If-Unmodified-Since)
+}
+
+// importBookmarkTree creates the entries of `items` (without IDs) under parentID
+// If parentID is nil, the top-level entries go into the bookmarks menu and root folders (with the ID of a root) into the corresponding root
+func (a *CLIArgumentsBookmarksUtil) importBookmarkTree(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, items []*models.BookmarkTreeRecord, parentID *string) (bookmarkImportStats, error) {
+	records, err := client.ListRecords(ctx, session, consts.CollectionBookmarks, nil, nil, false, true, nil, nil)
+	if err != nil {
+		return bookmarkImportStats{}, err
+	}
+
+	imp := &bookmarkImporter{
+		records:        make(map[string]models.Record, len(records)),
+		bookmarks:      make(map[string]models.BookmarkRecord, len(records)),
+		newRecords:     make([]string, 0),
+		changedParents: make([]string, 0),
+		now:            time.Now(),
+	}
+
+	for _, rec := range records {
+		imp.lastModified = max(imp.lastModified, rec.ModifiedUnix)
+
+		bmrec, err := models.UnmarshalBookmark(ctx, rec)
+		if err != nil {
+			ctx.PrintVerbose(fmt.Sprintf("Skip record %s (failed to decode)", rec.ID))
+			continue
+		}
+		if bmrec.Deleted {
+			continue
+		}
+		imp.records[rec.ID] = rec
+		imp.bookmarks[rec.ID] = bmrec
+	}
+
+	if parentID != nil {
+		err = a.importInto(ctx, imp, *parentID, items, false)
+	} else {
+		err = a.importInto(ctx, imp, consts.BookmarkIDMenu, items, true)
+	}
+	if err != nil {
+		return bookmarkImportStats{}, err
+	}
+
+	ctx.PrintVerboseKV("New records", len(imp.newRecords))
+	ctx.PrintVerboseKV("Changed parents", len(imp.changedParents))
+
+	payloads := make([]bookmarkPayload, 0, len(imp.newRecords)+len(imp.changedParents))
+
+	for _, id := range imp.newRecords {
+		bmrec := imp.bookmarks[id]
+
+		bso := models.BookmarkCreatePayloadSchema{
+			ID:         bmrec.ID,
+			Type:       string(bmrec.Type),
+			DateAdded:  langext.Coalesce(bmrec.DateAdded, imp.now).UnixMilli(),
+			ParentID:   bmrec.ParentID,
+			ParentName: bmrec.ParentName,
+		}
+
+		switch bmrec.Type {
+		case models.BookmarkTypeBookmark:
+			bso.Title = langext.Ptr(bmrec.Title)
+			bso.URI = langext.Ptr(bmrec.URI)
+			bso.Description = langext.Ptr(bmrec.Description)
+			bso.LoadInSidebar = langext.Ptr(bmrec.LoadInSidebar)
+			bso.Tags = langext.Ptr(langext.ForceArray(bmrec.Tags))
+			bso.Keyword = langext.Ptr(bmrec.Keyword)
+		case models.BookmarkTypeQuery:
+			bso.Title = langext.Ptr(bmrec.Title)
+			bso.URI = langext.Ptr(bmrec.URI)
+			bso.Description = langext.Ptr(bmrec.Description)
+			bso.LoadInSidebar = langext.Ptr(bmrec.LoadInSidebar)
+			bso.Tags = langext.Ptr(langext.ForceArray(bmrec.Tags))
+			bso.Keyword = langext.Ptr(bmrec.Keyword)
+			bso.FolderName = langext.Ptr(bmrec.FolderName)
+			bso.QueryID = langext.Ptr(bmrec.QueryID)
+		case models.BookmarkTypeFolder:
+			bso.Title = langext.Ptr(bmrec.Title)
+			bso.Children = langext.Ptr(langext.ForceArray(bmrec.Children))
+		case models.BookmarkTypeLivemark:
+			bso.Title = langext.Ptr(bmrec.Title)
+			bso.Children = langext.Ptr(langext.ForceArray(bmrec.Children))
+			bso.FeedURI = langext.Ptr(bmrec.FeedURI)
+			bso.SiteURI = langext.Ptr(bmrec.SiteURI)
+		case models.BookmarkTypeSeparator:
+			bso.SeparatorPosition = langext.Ptr(bmrec.SeparatorPosition)
+		}
+
+		plainPayload, err := json.Marshal(bso)
+		if err != nil {
+			return bookmarkImportStats{}, errorx.Decorate(err, "failed to marshal BSO json")
+		}
+
+		payloads = append(payloads, bookmarkPayload{ID: id, Plain: plainPayload})
+	}
+
+	for _, id := range imp.changedParents {
+		plainPayload, err := langext.PatchJson(imp.records[id].DecodedData, "children", imp.bookmarks[id].Children)
+		if err != nil {
+			return bookmarkImportStats{}, errorx.Decorate(err, "failed to patch parent-record data")
+		}
+
+		payloads = append(payloads, bookmarkPayload{ID: id, Plain: []byte(plainPayload)})
+	}
+
+	err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(imp.lastModified))
+	if err != nil {
+		return bookmarkImportStats{}, err
+	}
+
+	return imp.stats, nil
+}
+
+func (a *CLIArgumentsBookmarksUtil) importInto(ctx *cli.FFSContext, imp *bookmarkImporter, folderID string, items []*models.BookmarkTreeRecord, mapRoots bool) error {
+	if _, ok := imp.bookmarks[folderID]; !ok && langext.InArray(folderID, consts.BookmarkRootIDs) {
+		ctx.PrintVerbose(fmt.Sprintf("Root folder '%s' does not exist yet, it will be created", folderID))
+
+		record, err := a.newRootRecord(folderID)
+		if err != nil {
+			return err
+		}
+		bmrec, err := models.UnmarshalBookmark(ctx, record)
+		if err != nil {
+			return err
+		}
+		imp.records[folderID] = record
+		imp.bookmarks[folderID] = bmrec
+	}
+
+	folder, ok := imp.bookmarks[folderID]
+	if !ok {
+		return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, fmt.Sprintf("parent-record with ID '%s' not found", folderID))
+	}
+	if folder.Type != models.BookmarkTypeFolder {
+		return fferr.NewDirectOutput(consts.ExitcodeParentNotAFolder, fmt.Sprintf("The parent record '%s' must be a folder", folderID))
+	}
+
+	knownURLs := make(map[string]bool)
+	knownFolders := make(map[string]string)
+	for _, cid := range folder.Children {
+		if child, ok := imp.bookmarks[cid]; ok {
+			if child.Type == models.BookmarkTypeBookmark || child.Type == models.BookmarkTypeQuery {
+				knownURLs[child.URI] = true
+			}
+			if child.Type == models.BookmarkTypeLivemark {
+				knownURLs[child.FeedURI] = true
+			}
+			if _, ok := knownFolders[child.Title]; child.Type == models.BookmarkTypeFolder && !ok {
+				knownFolders[child.Title] = cid
+			}
+		}
+	}
+
+	children := langext.ForceArray(folder.Children)
+	childrenChanged := false
+
+	for _, item := range items {
+		switch item.Type {
+
+		case models.BookmarkTypeBookmark, models.BookmarkTypeQuery:
+			if knownURLs[item.URI] {
+				ctx.PrintVerbose(fmt.Sprintf("Skip bookmark '%s' (url already exists in folder %s)", item.URI, folderID))
+				imp.stats.Skipped++
+				continue
+			}
+			knownURLs[item.URI] = true
+
+			id := a.addImportedRecord(imp, folder, item.BookmarkRecord)
+			children = append(children, id)
+			childrenChanged = true
+
+		case models.BookmarkTypeLivemark:
+			if knownURLs[item.FeedURI] {
+				ctx.PrintVerbose(fmt.Sprintf("Skip livemark '%s' (feed already exists in folder %s)", item.FeedURI, folderID))
+				imp.stats.Skipped++
+				continue
+			}
+			knownURLs[item.FeedURI] = true
+
+			rec := item.BookmarkRecord
+			rec.Children = make([]string, 0)
+
+			id := a.addImportedRecord(imp, folder, rec)
+			children = append(children, id)
+			childrenChanged = true
+
+		case models.BookmarkTypeSeparator:
+			rec := item.BookmarkRecord
+			rec.SeparatorPosition = len(children)
+
+			id := a.addImportedRecord(imp, folder, rec)
+			children = append(children, id)
+			childrenChanged = true
+
+		case models.BookmarkTypeFolder:
+			if mapRoots && langext.InArray(item.ID, consts.BookmarkRootIDs) {
+				err := a.importInto(ctx, imp, item.ID, item.ResolvedChildren, false)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if id, ok := knownFolders[item.Title]; ok {
+				ctx.PrintVerbose(fmt.Sprintf("Merge folder '%s' into existing folder %s", item.Title, id))
+				err := a.importInto(ctx, imp, id, item.ResolvedChildren, false)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			rec := item.BookmarkRecord
+			rec.Children = make([]string, 0)
+
+			id := a.addImportedRecord(imp, folder, rec)
+			children = append(children, id)
+			childrenChanged = true
+			knownFolders[item.Title] = id
+
+			err := a.importInto(ctx, imp, id, item.ResolvedChildren, false)
+			if err != nil {
+				return err
+			}
+
+		default:
+			ctx.PrintVerbose(fmt.Sprintf("[WARN] skip item of type %v in import", item.Type))
+		}
+	}
+
+	if childrenChanged {
+		folder.Children = children
+		imp.bookmarks[folderID] = folder
+
+		if _, isExisting := imp.records[folderID]; isExisting && !langext.InArray(folderID, imp.changedParents) {
+			imp.changedParents = append(imp.changedParents, folderID)
+		}
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksUtil) addImportedRecord(imp *bookmarkImporter, parent models.BookmarkRecord, rec models.BookmarkRecord) string {
+	rec.ID = a.newBookmarkID()
+	rec.ParentID = parent.ID
+	rec.ParentName = parent.Title
+
+	imp.bookmarks[rec.ID] = rec
+	imp.newRecords = append(imp.newRecords, rec.ID)
+	imp.stats.Created++
+
+	return rec.ID
+}
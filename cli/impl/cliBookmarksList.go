@@ -5,10 +5,14 @@ import (
 	"ffsyncclient/cli"
 	"ffsyncclient/consts"
 	"ffsyncclient/fferr"
+	"ffsyncclient/firefoxjsonfmt"
 	"ffsyncclient/models"
+	"ffsyncclient/mozlz4"
 	"ffsyncclient/netscapefmt"
+	"ffsyncclient/outlinefmt"
 	"fmt"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +29,7 @@ type CLIArgumentsBookmarksList struct {
 	TypeFilter         *[]models.BookmarkType
 	ParentFilter       *[]string
 	LinearOutput       bool
+	MozLz4             bool
 
 	CLIArgumentsBookmarksUtil
 }
@@ -40,6 +45,7 @@ func NewCLIArgumentsBookmarksList() *CLIArgumentsBookmarksList {
 		OnlyDeleted:        false,
 		TypeFilter:         nil,
 		LinearOutput:       false,
+		MozLz4:             false,
 	}
 }
 
@@ -52,7 +58,7 @@ func (a *CLIArgumentsBookmarksList) PositionArgCount() (*int, *int) {
 }
 
 func (a *CLIArgumentsBookmarksList) AvailableOutputFormats() []cli.OutputFormat {
-	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatText, cli.OutputFormatJson, cli.OutputFormatXML, cli.OutputFormatNetscape, cli.OutputFormatTSV, cli.OutputFormatCSV}
+	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatText, cli.OutputFormatJson, cli.OutputFormatXML, cli.OutputFormatNetscape, cli.OutputFormatFirefoxJson, cli.OutputFormatTSV, cli.OutputFormatCSV, cli.OutputFormatMarkdown, cli.OutputFormatOPML, cli.OutputFormatOrg}
 }
 
 func (a *CLIArgumentsBookmarksList) ShortHelp() [][]string {
@@ -66,8 +72,9 @@ func (a *CLIArgumentsBookmarksList) ShortHelp() [][]string {
 		{"          [--include-deleted]", "Show deleted entries"},
 		{"          [--only-deleted]", "Show only deleted entries"},
 		{"          [--type <folder|separator|bookmark|...>]", "Show only entries with the specified type"},
-		{"          [--parent <id>]", "Show only entries with the specified parent (by record-id), can be specified multiple times"},
+		{"          [--parent <id|path>]", "Show only entries with the specified parent (by record-id or path), can be specified multiple times"},
 		{"          [--linear", "Do not output the folder hierachy"},
+		{"          [--mozlz4]", "Compress the output with mozLz4 (only with --format firefox-json)"},
 	}
 }
 
@@ -83,6 +90,7 @@ func (a *CLIArgumentsBookmarksList) FullHelp() []string {
 		"The --limit and --offset parameter can be used to get a subset of the result and paginate through it.",
 		"By default we skip entries with {deleted:true}, this can be changed with --include-deleted and --only-deleted.",
 		"If --linear is not supplied the output will (depending on the format) print the bookmarks in their folder hierachy, wiith --linear the data is printed as a flat array",
+		"The text output is split into one section per root folder (Bookmarks Menu, Bookmarks Toolbar, Other Bookmarks, Mobile Bookmarks), unless --linear, a filter, --include-deleted or --only-deleted is specified.",
 		"",
 		"The following --format output-formats are possible:",
 		"  * [--format text]     Simple text output",
@@ -90,6 +98,13 @@ func (a *CLIArgumentsBookmarksList) FullHelp() []string {
 		"  * [--format json]     Output bookmark data as json",
 		"  * [--format netscape] Output bookmark data as netscape bookmarks html (same as the firefox bookmarks.html format)",
 		"  * [--format xml]      Output bookmark data as XML",
+		"  * [--format firefox-json] Output bookmark data as firefox bookmark backup (can be restored in firefox with `Import and Backup > Restore`)",
+		"  * [--format markdown] Output bookmark data as markdown document (folders as headings, bookmarks as links)",
+		"  * [--format opml]     Output bookmark data as OPML outline",
+		"  * [--format org]      Output bookmark data as org-mode document (folders as headlines, bookmarks as links)",
+		"",
+		"With --mozlz4 the firefox-json output is compressed into the mozLz4 format (same as the firefox bookmarkbackups/*.jsonlz4 files).",
+		"This is also done automatically if the --output file has the extension .jsonlz4",
 		"",
 		"You can filter the returned bookmark types with --type, the following types are possible:",
 		"(Specify multiple types by having multiple --type parameter)",
@@ -99,7 +114,7 @@ func (a *CLIArgumentsBookmarksList) FullHelp() []string {
 		"  * [--type folder]",
 		"  * [--type livemark]",
 		"  * [--type separator]",
-		"You can also filter the returned bookmarks by their parent with --parent (needs a record-id).",
+		"You can also filter the returned bookmarks by their parent with --parent (a record-id, a root folder or a path like `toolbar/Dev`).",
 		"This can also be used specify multiple parents with multiple --parent arguments.",
 	}
 }
@@ -122,6 +137,10 @@ func (a *CLIArgumentsBookmarksList) Init(positionalArgs []string, optionArgs []c
 			a.LinearOutput = true
 			continue
 		}
+		if arg.Key == "mozlz4" && arg.Value == nil {
+			a.MozLz4 = true
+			continue
+		}
 		if arg.Key == "type" && arg.Value != nil {
 			if a.TypeFilter == nil {
 				a.TypeFilter = &[]models.BookmarkType{models.BookmarkType(*arg.Value)}
@@ -193,6 +212,18 @@ func (a *CLIArgumentsBookmarksList) Execute(ctx *cli.FFSContext) error {
 		return err
 	}
 
+	if a.ParentFilter != nil {
+		parents := make([]string, 0, len(*a.ParentFilter))
+		for _, v := range *a.ParentFilter {
+			id, err := a.resolveBookmarkRef(ctx, client, session, v, false)
+			if err != nil {
+				return err
+			}
+			parents = append(parents, id)
+		}
+		a.ParentFilter = &parents
+	}
+
 	// ========================================================================
 
 	records, err := client.ListRecords(ctx, session, consts.CollectionBookmarks, a.After, a.Sort, false, true, a.Limit, a.Offset)
@@ -215,7 +246,7 @@ func (a *CLIArgumentsBookmarksList) printOutput(ctx *cli.FFSContext, bookmarks [
 	parentFilter := a.ParentFilter
 	ofmt := langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable)
 
-	includeParent := parentFilter != nil && !a.LinearOutput && (ofmt == cli.OutputFormatJson || ofmt == cli.OutputFormatXML)
+	includeParent := parentFilter != nil && ((!a.LinearOutput && (ofmt == cli.OutputFormatJson || ofmt == cli.OutputFormatXML)) || ofmt == cli.OutputFormatMarkdown || ofmt == cli.OutputFormatOPML || ofmt == cli.OutputFormatOrg)
 
 	bookmarks = a.filterDeleted(ctx, bookmarks, a.IncludeDeleted, a.OnlyDeleted, a.TypeFilter, parentFilter, includeParent)
 
@@ -243,35 +274,33 @@ func (a *CLIArgumentsBookmarksList) printOutput(ctx *cli.FFSContext, bookmarks [
 		return nil
 
 	case cli.OutputFormatText:
-		for _, v := range bookmarks {
-			ctx.PrintPrimaryOutput("ID:          " + v.ID)
-			if v.Deleted {
-				ctx.PrintPrimaryOutput("Deleted:     true")
-			}
-			ctx.PrintPrimaryOutput("Type:        " + string(v.Type))
-			ctx.PrintPrimaryOutput("Title:       " + v.Title)
-			if v.Description != "" {
-				ctx.PrintPrimaryOutput("Description: " + strings.ReplaceAll(strings.ReplaceAll(v.Description, "\r", ""), "\n", " "))
-			}
-			if v.URI != "" {
-				ctx.PrintPrimaryOutput("URI:         " + v.URI)
-			}
-			if v.SiteURI != "" {
-				ctx.PrintPrimaryOutput("SiteURI:     " + v.URI)
+		if a.LinearOutput || a.IncludeDeleted || a.OnlyDeleted || parentFilter != nil || a.TypeFilter != nil || a.Limit != nil || a.Offset != nil || a.After != nil {
+			for _, v := range bookmarks {
+				a.printTextEntry(ctx, v)
 			}
-			if v.FeedURI != "" {
-				ctx.PrintPrimaryOutput("FeedURI:     " + v.URI)
+			return nil
+		} else {
+			roots, unreferenced, _ := a.calculateTree(ctx, bookmarks, nil)
+			for _, root := range roots {
+				title, ok := consts.BookmarkRootTitles[root.ID]
+				if !ok && root.Title != "" {
+					title = root.Title
+				} else if !ok {
+					title = root.ID
+				}
+				ctx.PrintPrimaryOutput("===== " + title + " (" + root.ID + ") =====")
+				ctx.PrintPrimaryOutput("")
+				a.printTextTree(ctx, root)
 			}
-			if v.Type == models.BookmarkTypeFolder || v.Type == models.BookmarkTypeLivemark {
-				if len(v.Children) > 0 {
-					ctx.PrintPrimaryOutput("Children:    " + "['" + strings.Join(v.Children, "', '") + "']")
-				} else {
-					ctx.PrintPrimaryOutput("Children:    " + "[]")
+			if len(unreferenced) > 0 {
+				ctx.PrintPrimaryOutput("===== Unreferenced =====")
+				ctx.PrintPrimaryOutput("")
+				for _, v := range unreferenced {
+					a.printTextEntry(ctx, *v)
 				}
 			}
-			ctx.PrintPrimaryOutput("")
+			return nil
 		}
-		return nil
 
 	case cli.OutputFormatJson:
 		if a.LinearOutput {
@@ -343,6 +372,38 @@ func (a *CLIArgumentsBookmarksList) printOutput(ctx *cli.FFSContext, bookmarks [
 		ctx.PrintPrimaryOutput(nc)
 		return nil
 
+	case cli.OutputFormatFirefoxJson:
+		roots, _, _ := a.calculateTree(ctx, bookmarks, nil)
+		data, err := firefoxjsonfmt.Format(ctx, roots)
+		if err != nil {
+			return errorx.Decorate(err, "failed to format bookmarks")
+		}
+		if a.MozLz4 || (ctx.Opt.OutputFile != nil && strings.HasSuffix(strings.ToLower(*ctx.Opt.OutputFile), ".jsonlz4")) {
+			ctx.PrintPrimaryOutputBinary(mozlz4.Compress(data))
+		} else {
+			ctx.PrintPrimaryOutput(string(data))
+		}
+		return nil
+
+	case cli.OutputFormatMarkdown:
+		roots, _, _ := a.calculateTree(ctx, bookmarks, langext.Coalesce(parentFilter, nil))
+		ctx.PrintPrimaryOutput(outlinefmt.FormatMarkdown(ctx, roots))
+		return nil
+
+	case cli.OutputFormatOrg:
+		roots, _, _ := a.calculateTree(ctx, bookmarks, langext.Coalesce(parentFilter, nil))
+		ctx.PrintPrimaryOutput(outlinefmt.FormatOrg(ctx, roots))
+		return nil
+
+	case cli.OutputFormatOPML:
+		roots, _, _ := a.calculateTree(ctx, bookmarks, langext.Coalesce(parentFilter, nil))
+		data, err := outlinefmt.FormatOPML(ctx, roots)
+		if err != nil {
+			return errorx.Decorate(err, "failed to format bookmarks")
+		}
+		ctx.PrintPrimaryOutput(data)
+		return nil
+
 	case cli.OutputFormatTSV:
 		fallthrough
 	case cli.OutputFormatCSV:
@@ -371,3 +432,39 @@ func (a *CLIArgumentsBookmarksList) printOutput(ctx *cli.FFSContext, bookmarks [
 		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
 	}
 }
+
+func (a *CLIArgumentsBookmarksList) printTextTree(ctx *cli.FFSContext, record *models.BookmarkTreeRecord) {
+	a.printTextEntry(ctx, record.BookmarkRecord)
+	for _, child := range record.ResolvedChildren {
+		a.printTextTree(ctx, child)
+	}
+}
+
+func (a *CLIArgumentsBookmarksList) printTextEntry(ctx *cli.FFSContext, v models.BookmarkRecord) {
+	ctx.PrintPrimaryOutput("ID:          " + v.ID)
+	if v.Deleted {
+		ctx.PrintPrimaryOutput("Deleted:     true")
+	}
+	ctx.PrintPrimaryOutput("Type:        " + string(v.Type))
+	ctx.PrintPrimaryOutput("Title:       " + v.Title)
+	if v.Description != "" {
+		ctx.PrintPrimaryOutput("Description: " + strings.ReplaceAll(strings.ReplaceAll(v.Description, "\r", ""), "\n", " "))
+	}
+	if v.URI != "" {
+		ctx.PrintPrimaryOutput("URI:         " + v.URI)
+	}
+	if v.SiteURI != "" {
+		ctx.PrintPrimaryOutput("SiteURI:     " + v.URI)
+	}
+	if v.FeedURI != "" {
+		ctx.PrintPrimaryOutput("FeedURI:     " + v.URI)
+	}
+	if v.Type == models.BookmarkTypeFolder || v.Type == models.BookmarkTypeLivemark {
+		if len(v.Children) > 0 {
+			ctx.PrintPrimaryOutput("Children:    " + "['" + strings.Join(v.Children, "', '") + "']")
+		} else {
+			ctx.PrintPrimaryOutput("Children:    " + "[]")
+		}
+	}
+	ctx.PrintPrimaryOutput("")
+}
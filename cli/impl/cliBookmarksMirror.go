@@ -0,0 +1,587 @@
+package impl
+
+import (
+	"bytes"
+	"encoding/json"
+	"ffsyncclient/bookmarkmirror"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type CLIArgumentsBookmarksMirror struct {
+	Folder    string
+	Directory string
+	Format    string
+	Prefer    bookmarkmirror.Prefer
+	DryRun    bool
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksMirror() *CLIArgumentsBookmarksMirror {
+	return &CLIArgumentsBookmarksMirror{
+		Format: bookmarkmirror.ExtURL,
+		Prefer: bookmarkmirror.PreferNone,
+		DryRun: false,
+	}
+}
+
+func (a *CLIArgumentsBookmarksMirror) Mode() cli.Mode {
+	return cli.ModeBookmarksMirror
+}
+
+func (a *CLIArgumentsBookmarksMirror) PositionArgCount() (*int, *int) {
+	return langext.Ptr(2), langext.Ptr(2)
+}
+
+func (a *CLIArgumentsBookmarksMirror) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksMirror) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks mirror <folder> <dir>", "Two-way mirror of a bookmark folder to a local directory"},
+		{"          [--format <url|desktop>]", "The file format of new bookmark files (default: url)"},
+		{"          [--prefer <local|remote>]", "Resolve conflicts with the local or the remote state"},
+		{"          [--dry-run]", "Only show the changes, do not change anything"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksMirror) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks mirror <folder> <dir> [--format <url|desktop>] [--prefer <local|remote>] [--dry-run]",
+		"",
+		"Mirror the bookmark folder (by record-id, root folder or path) to the directory, and the directory back to the folder.",
+		"",
+		"Subfolders become directories and bookmarks (and queries) become `.url` (windows internet shortcut) or `.desktop` (freedesktop link) files, named after their title.",
+		"The order of the entries of every folder (and its separators, as `---`) is stored in the `.order` file of the directory.",
+		"Livemarks and microsummaries are not mirrored (and not changed).",
+		"",
+		"The state of the last run is stored in the file `" + bookmarkmirror.StateFileName + "` in the directory.",
+		"Every run compares both sides with this state and applies the changes of each side to the other one (files are only read if their modification time changed).",
+		"On the first run identical entries on both sides are matched, everything else is copied to the other side.",
+		"Files and directories are matched by their path, renamed or moved files are detected by their URL and renamed or moved directories by their content,",
+		"so that they keep their bookmark (incl. tags, keyword, description and dateAdded). The title of a `.url` file can be changed by renaming it.",
+		"",
+		"Entries that were changed on both sides are conflicts, they are listed and left untouched on both sides (exitcode 90).",
+		"With --prefer the conflicts are resolved with the local or the remote state instead.",
+		"If the order of a folder was changed on both sides the remote order is used.",
+		"",
+		"All changed records are written in a single batch upload, the request fails if the bookmarks were modified by another client in the meantime.",
+		"Hidden files and files with other extensions in the directory are ignored.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksMirror) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Folder = positionalArgs[0]
+	a.Directory = positionalArgs[1]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "format" && arg.Value != nil {
+			ext, err := bookmarkmirror.ParseFileFormat(*arg.Value)
+			if err != nil {
+				return fferr.DirectOutput.New(err.Error())
+			}
+			a.Format = ext
+			continue
+		}
+		if arg.Key == "prefer" && arg.Value != nil {
+			switch bookmarkmirror.Prefer(*arg.Value) {
+			case bookmarkmirror.PreferLocal, bookmarkmirror.PreferRemote:
+				a.Prefer = bookmarkmirror.Prefer(*arg.Value)
+			default:
+				return fferr.DirectOutput.New(fmt.Sprintf("Unknown value for --prefer: '%s' (possible values: local, remote)", *arg.Value))
+			}
+			continue
+		}
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksMirror) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Mirror Bookmarks]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Folder", a.Folder)
+	ctx.PrintVerboseKV("Directory", a.Directory)
+	ctx.PrintVerboseKV("Format", a.Format)
+	ctx.PrintVerboseKV("Prefer", a.Prefer)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query bookmarks")
+
+	rootID, err := a.resolveBookmarkRef(ctx, client, session, a.Folder, false)
+	if err != nil {
+		return err
+	}
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	if root, ok := coll.bookmarks[rootID]; !ok || root.Type != models.BookmarkTypeFolder {
+		return fferr.NewDirectOutput(consts.ExitcodeParentNotAFolder, fmt.Sprintf("The record '%s' is not a folder", rootID))
+	}
+
+	remote := a.remoteSnapshot(coll, rootID)
+
+	ctx.PrintVerbose(fmt.Sprintf("Found %d entries in folder %s", len(remote.Entries), rootID))
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Scan directory")
+
+	state, found, err := bookmarkmirror.LoadState(a.Directory)
+	if err != nil {
+		return errorx.Decorate(err, "failed to read mirror state")
+	}
+	if !found {
+		ctx.PrintVerbose("No mirror state found, this is the first run")
+		state = bookmarkmirror.NewState(rootID)
+	} else if state.FolderID != rootID {
+		return fferr.NewDirectOutput(consts.ExitcodeError, fmt.Sprintf("The directory is already a mirror of the folder '%s'", state.FolderID))
+	}
+
+	local, err := bookmarkmirror.Scan(a.Directory, state)
+	if err != nil {
+		return errorx.Decorate(err, "failed to scan directory")
+	}
+
+	ctx.PrintVerbose(fmt.Sprintf("Found %d entries in directory %s", len(local.Entries), a.Directory))
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[3] Reconcile changes")
+
+	res := bookmarkmirror.Merge(rootID, state.Snapshot(), local.Snapshot, remote, a.Prefer)
+
+	for lid, rid := range res.Paired {
+		ctx.PrintVerbose(fmt.Sprintf("Matched %s to existing record %s", local.Paths[lid], rid))
+	}
+
+	// new local entries get a record-id
+	ids := make(map[string]string)
+	for id := range res.Final.Entries {
+		if bookmarkmirror.IsLocalID(id) {
+			ids[id] = a.newBookmarkID()
+		}
+	}
+	final := bookmarkmirror.Rename(res.Final, ids)
+
+	paths := make(map[string]string, len(local.Paths))
+	for id, p := range local.Paths {
+		if v, ok := res.Paired[id]; ok {
+			id = v
+		}
+		if v, ok := ids[id]; ok {
+			id = v
+		}
+		paths[id] = p
+	}
+
+	// entries that were moved out of the mirrored folder on the remote side cannot be created again
+	for _, id := range langext.MapKeyArr(final.Entries) {
+		if _, inRemote := remote.Entries[id]; !inRemote {
+			if _, exists := coll.bookmarks[id]; exists {
+				ctx.PrintVerbose(fmt.Sprintf("Record %s was moved out of the mirrored folder, it is not restored", id))
+				final.Remove(id)
+			}
+		}
+	}
+
+	payloads, created, updated, deleted, err := a.calculatePayloads(ctx, coll, rootID, remote, final)
+	if err != nil {
+		return err
+	}
+
+	exts := make(map[string]string, len(paths))
+	for id, p := range paths {
+		exts[id] = strings.ToLower(path.Ext(p))
+	}
+
+	protected := make([]string, 0)
+	for _, v := range res.Conflicts {
+		if p, ok := paths[v.ID]; ok {
+			protected = append(protected, p)
+		}
+	}
+
+	rendered := bookmarkmirror.Render(rootID, final, res.Skipped, protected, exts, a.Format)
+
+	// ========================================================================
+
+	if !a.DryRun && len(payloads) > 0 {
+		ctx.PrintVerboseHeader("[4] Write remote changes")
+
+		err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx.PrintVerboseHeader("[5] Write local changes")
+
+	written, removed, err := a.applyLocal(ctx, local, rendered, protected)
+	if err != nil {
+		return errorx.Decorate(err, "failed to write directory")
+	}
+
+	if !a.DryRun {
+		next, err := bookmarkmirror.NextState(a.Directory, state, final, rendered, res.Skipped)
+		if err != nil {
+			return errorx.Decorate(err, "failed to calculate mirror state")
+		}
+		err = bookmarkmirror.SaveState(a.Directory, next)
+		if err != nil {
+			return errorx.Decorate(err, "failed to write mirror state")
+		}
+	}
+
+	// ========================================================================
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Remote: %d created, %d updated, %d deleted", created, updated, deleted))
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Local:  %d files written, %d deleted", written, removed))
+
+	for _, v := range res.OrderConflicts {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Order conflict: %s (changed locally and remotely, the remote order is used)", a.conflictPath(coll, paths, rootID, v)))
+	}
+	for _, v := range res.Conflicts {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Conflict: %s (%s)", a.conflictPath(coll, paths, rootID, v.ID), v.Reason))
+	}
+
+	if a.DryRun {
+		ctx.PrintPrimaryOutput("No changes were made (--dry-run).")
+	}
+
+	if len(res.Conflicts) > 0 {
+		return fferr.NewEmpty(consts.ExitcodeBookmarkMirrorConflict)
+	}
+	return nil
+}
+
+// isMirrored returns true for the entry types that are mirrored to the directory
+func (a *CLIArgumentsBookmarksMirror) isMirrored(bm models.BookmarkRecord) bool {
+	return bm.Type == models.BookmarkTypeFolder || bm.Type == models.BookmarkTypeBookmark || bm.Type == models.BookmarkTypeQuery || bm.Type == models.BookmarkTypeSeparator
+}
+
+// remoteSnapshot returns the mirrored entries in the folder (and its subfolders)
+func (a *CLIArgumentsBookmarksMirror) remoteSnapshot(coll *bookmarkCollection, rootID string) bookmarkmirror.Snapshot {
+	snap := bookmarkmirror.NewSnapshot()
+
+	for id, bm := range coll.bookmarks {
+		if id == rootID || !a.isMirrored(bm) || !coll.isAncestor(rootID, id) {
+			continue
+		}
+		e := bookmarkmirror.Entry{Type: bm.Type, Parent: bm.ParentID}
+		if bm.Type != models.BookmarkTypeSeparator {
+			e.Title = bm.Title
+		}
+		if bm.Type == models.BookmarkTypeBookmark || bm.Type == models.BookmarkTypeQuery {
+			e.URL = bm.URI
+		}
+		snap.Entries[id] = e
+	}
+
+	folders := []string{rootID}
+	for id, e := range snap.Entries {
+		if e.Type == models.BookmarkTypeFolder {
+			folders = append(folders, id)
+		}
+	}
+
+	for _, folder := range folders {
+		order := make([]string, 0)
+		for _, child := range coll.bookmarks[folder].Children {
+			if e, ok := snap.Entries[child]; ok && e.Parent == folder && !langext.InArray(child, order) {
+				order = append(order, child)
+			}
+		}
+
+		// children that are not listed in the folder are appended
+		missing := make([]string, 0)
+		for id, e := range snap.Entries {
+			if e.Parent == folder && !langext.InArray(id, order) {
+				missing = append(missing, id)
+			}
+		}
+		sort.Strings(missing)
+
+		snap.Orders[folder] = append(order, missing...)
+	}
+
+	return snap
+}
+
+// calculatePayloads returns the new records, the changed records (incl. the changed `children` of folders) and the tombstones of the deleted records
+func (a *CLIArgumentsBookmarksMirror) calculatePayloads(ctx *cli.FFSContext, coll *bookmarkCollection, rootID string, remote bookmarkmirror.Snapshot, final bookmarkmirror.Snapshot) ([]bookmarkPayload, int, int, int, error) {
+	now := time.Now().UnixMilli()
+
+	parentName := func(id string) string {
+		if e, ok := final.Entries[id]; ok {
+			return e.Title
+		}
+		return coll.bookmarks[id].Title
+	}
+
+	created := make([]bookmarkPayload, 0)
+	updated := make([]bookmarkPayload, 0)
+	deleted := make([]bookmarkPayload, 0)
+
+	changedIDs := make([]string, 0)
+	changed := make(map[string][]byte)
+
+	patch := func(id string, key string, value any) error {
+		plain, ok := changed[id]
+		if !ok {
+			plain = coll.records[id].DecodedData
+			changedIDs = append(changedIDs, id)
+		}
+		plain, err := langext.PatchJson(plain, key, value)
+		if err != nil {
+			return errorx.Decorate(err, "failed to patch data of record")
+		}
+		changed[id] = plain
+		return nil
+	}
+
+	ids := langext.MapKeyArr(final.Entries)
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		e := final.Entries[id]
+
+		re, exists := remote.Entries[id]
+		if !exists {
+			bso := models.BookmarkCreatePayloadSchema{
+				ID:         id,
+				Type:       string(e.Type),
+				DateAdded:  now,
+				ParentID:   e.Parent,
+				ParentName: parentName(e.Parent),
+			}
+			switch e.Type {
+			case models.BookmarkTypeFolder:
+				bso.Title = langext.Ptr(e.Title)
+				bso.Children = langext.Ptr(final.Orders[id])
+			case models.BookmarkTypeSeparator:
+				bso.SeparatorPosition = langext.Ptr(slices.Index(final.Orders[e.Parent], id))
+			default:
+				bso.Title = langext.Ptr(e.Title)
+				bso.URI = langext.Ptr(e.URL)
+			}
+
+			plain, err := json.Marshal(bso)
+			if err != nil {
+				return nil, 0, 0, 0, errorx.Decorate(err, "failed to marshal BSO json")
+			}
+
+			ctx.PrintVerbose(fmt.Sprintf("Create %s '%s' (%s)", e.Type, e.Title, id))
+			created = append(created, bookmarkPayload{ID: id, Plain: plain})
+			continue
+		}
+
+		if e.Title != re.Title {
+			if err := patch(id, "title", e.Title); err != nil {
+				return nil, 0, 0, 0, err
+			}
+		}
+		if e.URL != re.URL {
+			if err := patch(id, "bmkUri", e.URL); err != nil {
+				return nil, 0, 0, 0, err
+			}
+		}
+		if e.Parent != re.Parent {
+			if err := patch(id, "parentid", e.Parent); err != nil {
+				return nil, 0, 0, 0, err
+			}
+			if err := patch(id, "parentName", parentName(e.Parent)); err != nil {
+				return nil, 0, 0, 0, err
+			}
+		}
+	}
+
+	// the children of existing folders, entries that are not mirrored (e.g. livemarks) are kept at the end
+	folders := []string{rootID}
+	for _, id := range ids {
+		if _, exists := remote.Entries[id]; exists && final.Entries[id].Type == models.BookmarkTypeFolder {
+			folders = append(folders, id)
+		}
+	}
+	for _, folder := range folders {
+		children := slices.Clone(final.Orders[folder])
+		for _, child := range coll.bookmarks[folder].Children {
+			if _, mirrored := remote.Entries[child]; mirrored {
+				continue
+			}
+			if bm, ok := coll.bookmarks[child]; ok && bm.ParentID == folder && !langext.InArray(child, children) {
+				children = append(children, child)
+			}
+		}
+
+		if !slices.Equal(children, coll.bookmarks[folder].Children) {
+			ctx.PrintVerbose(fmt.Sprintf("Update children of folder %s", folder))
+			if err := patch(folder, "children", children); err != nil {
+				return nil, 0, 0, 0, err
+			}
+		}
+	}
+
+	for _, id := range changedIDs {
+		ctx.PrintVerbose("Update Record " + id)
+		updated = append(updated, bookmarkPayload{ID: id, Plain: changed[id]})
+	}
+
+	remoteIDs := langext.MapKeyArr(remote.Entries)
+	sort.Strings(remoteIDs)
+
+	for _, id := range remoteIDs {
+		if _, ok := final.Entries[id]; ok {
+			continue
+		}
+
+		plain, err := json.Marshal(models.BookmarkTombstonePayloadSchema{ID: id, Deleted: true})
+		if err != nil {
+			return nil, 0, 0, 0, errorx.Decorate(err, "failed to marshal tombstone")
+		}
+
+		ctx.PrintVerbose("Delete Record " + id)
+		deleted = append(deleted, bookmarkPayload{ID: id, Plain: plain})
+	}
+
+	payloads := make([]bookmarkPayload, 0, len(created)+len(updated)+len(deleted))
+	payloads = append(payloads, created...)
+	payloads = append(payloads, updated...)
+	payloads = append(payloads, deleted...)
+
+	return payloads, len(created), len(updated), len(deleted), nil
+}
+
+// applyLocal deletes the obsolete files and directories and writes the changed files (nothing is changed with --dry-run)
+// Paths of conflicts (and everything below them) are never deleted, directories that still contain other files are kept.
+func (a *CLIArgumentsBookmarksMirror) applyLocal(ctx *cli.FFSContext, local bookmarkmirror.Local, rendered bookmarkmirror.Rendered, protected []string) (int, int, error) {
+	isProtected := func(p string) bool {
+		for _, v := range protected {
+			if p == v || strings.HasPrefix(p, v+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	written, removed := 0, 0
+
+	// obsolete entries are removed first (deepest first), so that renames also work on case-insensitive filesystems
+	obsolete := make([]string, 0)
+	for _, p := range local.Files {
+		if _, ok := rendered.Files[p]; ok || langext.InArray(p, rendered.Dirs) || isProtected(p) {
+			continue
+		}
+		obsolete = append(obsolete, p)
+	}
+	sort.Slice(obsolete, func(i, j int) bool {
+		if di, dj := strings.Count(obsolete[i], "/"), strings.Count(obsolete[j], "/"); di != dj {
+			return di > dj
+		}
+		return obsolete[i] > obsolete[j]
+	})
+
+	for _, p := range obsolete {
+		fp := filepath.Join(a.Directory, filepath.FromSlash(p))
+
+		if info, err := os.Stat(fp); err == nil && info.IsDir() {
+			if items, err := os.ReadDir(fp); err == nil && len(items) > 0 && !a.DryRun {
+				ctx.PrintVerbose(fmt.Sprintf("Keep directory %s (contains other files)", p))
+				continue
+			}
+		}
+
+		ctx.PrintVerbose("Delete " + p)
+		removed++
+		if a.DryRun {
+			continue
+		}
+		if err := os.Remove(fp); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	for _, p := range rendered.Dirs {
+		if a.DryRun {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(a.Directory, filepath.FromSlash(p)), 0755); err != nil {
+			return 0, 0, err
+		}
+	}
+	if !a.DryRun {
+		if err := os.MkdirAll(a.Directory, 0755); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	files := langext.MapKeyArr(rendered.Files)
+	sort.Strings(files)
+
+	for _, p := range files {
+		fp := filepath.Join(a.Directory, filepath.FromSlash(p))
+
+		if existing, err := os.ReadFile(fp); err == nil && bytes.Equal(existing, rendered.Files[p]) {
+			continue
+		}
+
+		ctx.PrintVerbose("Write " + p)
+		written++
+		if a.DryRun {
+			continue
+		}
+		if err := os.WriteFile(fp, rendered.Files[p], 0644); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return written, removed, nil
+}
+
+func (a *CLIArgumentsBookmarksMirror) conflictPath(coll *bookmarkCollection, paths map[string]string, rootID string, id string) string {
+	if id == rootID {
+		return "."
+	}
+	if p, ok := paths[id]; ok {
+		return p
+	}
+	if _, ok := coll.bookmarks[id]; ok {
+		return coll.path(id)
+	}
+	return id
+}
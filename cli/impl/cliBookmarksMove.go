@@ -0,0 +1,240 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"github.com/joomcode/errorx"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"strconv"
+)
+
+type CLIArgumentsBookmarksMove struct {
+	RecordID string
+	ParentID string
+	Mkdirs   bool
+	Position int
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksMove() *CLIArgumentsBookmarksMove {
+	return &CLIArgumentsBookmarksMove{
+		ParentID: "",
+		Mkdirs:   false,
+		Position: -1,
+	}
+}
+
+func (a *CLIArgumentsBookmarksMove) Mode() cli.Mode {
+	return cli.ModeBookmarksMove
+}
+
+func (a *CLIArgumentsBookmarksMove) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsBookmarksMove) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksMove) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks move <id|path> --parent <id|path>", "Move a bookmark (or folder) into another folder"},
+		{"          [--mkdirs]", "Create the missing folders of the parent path"},
+		{"          [--position=<idx>]", "The position of the entry in the new parent (0 = first, default is last). Can use negative indizes."},
+	}
+}
+
+func (a *CLIArgumentsBookmarksMove) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks move <id|path> --parent <id|path> [--mkdirs] [--position <idx>]",
+		"",
+		"Move an existing bookmark entry into another folder",
+		"",
+		"The entry is removed from the children of its old parent and inserted in the children of the new parent.",
+		"Instead of an ID you can also use the root folders `menu`, `toolbar`, `unfiled` (or `other`) and `mobile` as the new parent.",
+		"Both the entry and the parent can also be specified as a path of titles, starting with a root folder (e.g. `toolbar/Dev/Go docs`). Use `\\/` for a `/` in a title.",
+		"With --mkdirs the missing folders of the parent path are created.",
+		"With --position you can specify the position in the new parent folder. The left-most position is 0 and the last position is len(folder.children). You can also use negative indizes: -1 is the last position and -2 the second-last etc. An invalid position throws an error.",
+		"If the position is negative you _have_ to use the --position=XX syntax. (Writing `--position XX` will result in a parser error)",
+		"",
+		"A folder cannot be moved into itself or one of its subfolders.",
+		"All changed records are written in a single request, the request fails if the bookmarks were modified by another client in the meantime.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksMove) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.RecordID = positionalArgs[0]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "parent" && arg.Value != nil {
+			a.ParentID = a.resolveParentAlias(*arg.Value)
+			continue
+		}
+		if arg.Key == "mkdirs" && arg.Value == nil {
+			a.Mkdirs = true
+			continue
+		}
+		if arg.Key == "position" && arg.Value != nil {
+			if v, err := strconv.ParseInt(*arg.Value, 10, 32); err == nil {
+				a.Position = int(v)
+				continue
+			}
+			return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse number argument '--%s': '%s'", arg.Key, *arg.Value))
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.ParentID == "" {
+		return fferr.DirectOutput.New("Missing required argument: --parent")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksMove) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Move Bookmark]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("RecordID", a.RecordID)
+	ctx.PrintVerboseKV("ParentID", a.ParentID)
+	ctx.PrintVerboseKV("Position", a.Position)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.RecordID, err = a.resolveBookmarkRef(ctx, client, session, a.RecordID, false)
+	if err != nil {
+		return err
+	}
+
+	a.ParentID, err = a.resolveBookmarkRef(ctx, client, session, a.ParentID, a.Mkdirs)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query bookmarks")
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	record, ok := coll.records[a.RecordID]
+	if !ok {
+		return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, "Record not found")
+	}
+	bmrec := coll.bookmarks[a.RecordID]
+
+	if a.RecordID == consts.BookmarkIDPlaces || langext.InArray(a.RecordID, consts.BookmarkRootIDs) {
+		return fferr.NewDirectOutput(consts.ExitcodeError, fmt.Sprintf("The root folder '%s' cannot be moved", a.RecordID))
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Find new parent")
+
+	newParentRecord, ok := coll.records[a.ParentID]
+	if !ok && langext.InArray(a.ParentID, consts.BookmarkRootIDs) {
+		ctx.PrintVerbose(fmt.Sprintf("Root folder '%s' does not exist yet, it will be created", a.ParentID))
+		newParentRecord, err = a.newRootRecord(a.ParentID)
+		if err != nil {
+			return err
+		}
+	} else if !ok {
+		return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, fmt.Sprintf("parent-record with ID '%s' not found", a.ParentID))
+	}
+
+	newParent, err := models.UnmarshalBookmark(ctx, newParentRecord)
+	if err != nil {
+		return errorx.Decorate(err, "failed to decode parent-record")
+	}
+
+	if coll.isAncestor(a.RecordID, a.ParentID) {
+		return fferr.NewDirectOutput(consts.ExitcodeBookmarkCycle, "Cannot move a folder into itself or one of its subfolders")
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[3] Calculate changes")
+
+	payloads := make([]bookmarkPayload, 0, 3)
+
+	if oldParentRecord, ok := coll.records[bmrec.ParentID]; ok && bmrec.ParentID != a.ParentID {
+		oldParent := coll.bookmarks[bmrec.ParentID]
+
+		newChildren := make([]string, 0, len(oldParent.Children))
+		for _, v := range oldParent.Children {
+			if v != a.RecordID {
+				newChildren = append(newChildren, v)
+			} else {
+				ctx.PrintVerbose("Remove child-entry from old parent: " + v)
+			}
+		}
+
+		plainOldParent, err := langext.PatchJson(oldParentRecord.DecodedData, "children", newChildren)
+		if err != nil {
+			return errorx.Decorate(err, "failed to patch payload of old parent")
+		}
+
+		payloads = append(payloads, bookmarkPayload{ID: oldParentRecord.ID, Plain: plainOldParent})
+	} else if !ok {
+		ctx.PrintVerbose(fmt.Sprintf("Old parent not found (parent-id := %s)", bmrec.ParentID))
+	}
+
+	newParent, plainNewParent, normpos, err := a.moveChild(ctx, newParentRecord, newParent, a.RecordID, a.Position)
+	if err != nil {
+		return err
+	}
+
+	payloads = append(payloads, bookmarkPayload{ID: newParent.ID, Plain: []byte(plainNewParent)})
+
+	plainRecord := record.DecodedData
+
+	plainRecord, err = langext.PatchJson(plainRecord, "parentid", newParent.ID)
+	if err != nil {
+		return errorx.Decorate(err, "failed to patch data of record")
+	}
+
+	plainRecord, err = langext.PatchJson(plainRecord, "parentName", newParent.Title)
+	if err != nil {
+		return errorx.Decorate(err, "failed to patch data of record")
+	}
+
+	if bmrec.Type == models.BookmarkTypeSeparator {
+		plainRecord, err = langext.PatchJson(plainRecord, "pos", normpos)
+		if err != nil {
+			return errorx.Decorate(err, "failed to patch data of record")
+		}
+	}
+
+	if string(plainRecord) != string(record.DecodedData) {
+		payloads = append(payloads, bookmarkPayload{ID: record.ID, Plain: plainRecord})
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[4] Update records")
+
+	err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	ctx.PrintPrimaryOutput("Okay.")
+	return nil
+}
@@ -0,0 +1,144 @@
+package impl
+
+import (
+	"encoding/json"
+	"errors"
+	"ffsyncclient/bookmarkkeyword"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"strings"
+)
+
+type CLIArgumentsBookmarksResolve struct {
+	Keyword string
+	Terms   []string
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksResolve() *CLIArgumentsBookmarksResolve {
+	return &CLIArgumentsBookmarksResolve{
+		Terms: make([]string, 0),
+	}
+}
+
+func (a *CLIArgumentsBookmarksResolve) Mode() cli.Mode {
+	return cli.ModeBookmarksResolve
+}
+
+func (a *CLIArgumentsBookmarksResolve) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), nil
+}
+
+func (a *CLIArgumentsBookmarksResolve) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText, cli.OutputFormatJson}
+}
+
+func (a *CLIArgumentsBookmarksResolve) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks resolve <keyword> [terms...]", "Resolve a keyword bookmark (e.g. `gh %s`) to its final URL"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksResolve) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks resolve <keyword> [terms...]",
+		"",
+		"Find the bookmark with the specified keyword and print its URL, with the terms substituted like in the firefox address bar.",
+		"",
+		"The terms are joined with spaces. In the URL `%s` is replaced by the URL-encoded terms and `%S` by the unescaped terms.",
+		"If the URL contains no placeholder no terms can be specified.",
+		"Keywords are matched case-insensitive, if multiple bookmarks have the same keyword the most recently modified one is used.",
+		"Keywords that send POST data are not supported.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksResolve) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Keyword = positionalArgs[0]
+	a.Terms = positionalArgs[1:]
+
+	for _, arg := range optionArgs {
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksResolve) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Resolve Bookmark Keyword]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Keyword", a.Keyword)
+	ctx.PrintVerboseKV("Terms", a.Terms)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	found := ""
+	for id, bm := range coll.bookmarks {
+		if !a.hasTags(bm) || bm.Keyword == "" || !strings.EqualFold(bm.Keyword, a.Keyword) {
+			continue
+		}
+		ctx.PrintVerbose(fmt.Sprintf("Found bookmark %s with keyword '%s'", id, bm.Keyword))
+		if found == "" || coll.records[id].ModifiedUnix > coll.records[found].ModifiedUnix {
+			found = id
+		}
+	}
+
+	if found == "" {
+		return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, fmt.Sprintf("No bookmark with the keyword '%s' found", a.Keyword))
+	}
+
+	bm := coll.bookmarks[found]
+
+	// the postData is not part of the sync schema, but it can still be in the payload of other clients
+	var raw struct {
+		PostData string `json:"postData"`
+	}
+	if err := json.Unmarshal(coll.records[found].DecodedData, &raw); err == nil && raw.PostData != "" {
+		return fferr.NewDirectOutput(consts.ExitcodeError, fmt.Sprintf("The keyword '%s' sends POST data, this is not supported", a.Keyword))
+	}
+
+	url, err := bookmarkkeyword.Expand(bm.URI, strings.Join(a.Terms, " "))
+	if errors.Is(err, bookmarkkeyword.ErrNoParameter) {
+		return fferr.NewDirectOutput(consts.ExitcodeError, fmt.Sprintf("The keyword '%s' does not take any search terms", a.Keyword))
+	}
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	switch langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) {
+
+	case cli.OutputFormatText:
+		ctx.PrintPrimaryOutput(url)
+		return nil
+
+	case cli.OutputFormatJson:
+		ctx.PrintPrimaryOutputJSON(langext.H{
+			"id":      bm.ID,
+			"keyword": bm.Keyword,
+			"bmkUri":  bm.URI,
+			"url":     url,
+		})
+		return nil
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
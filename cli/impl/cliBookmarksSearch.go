@@ -0,0 +1,265 @@
+package impl
+
+import (
+	"ffsyncclient/bookmarksearch"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"github.com/joomcode/errorx"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CLIArgumentsBookmarksSearch struct {
+	Query       string
+	SearchMode  bookmarksearch.Mode
+	Fields      []bookmarksearch.Field
+	Within      *string
+	AddedAfter  *string
+	AddedBefore *string
+	Limit       *int
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksSearch() *CLIArgumentsBookmarksSearch {
+	return &CLIArgumentsBookmarksSearch{
+		SearchMode:  bookmarksearch.ModeSubstring,
+		Fields:      make([]bookmarksearch.Field, 0),
+		Within:      nil,
+		AddedAfter:  nil,
+		AddedBefore: nil,
+		Limit:       nil,
+	}
+}
+
+func (a *CLIArgumentsBookmarksSearch) Mode() cli.Mode {
+	return cli.ModeBookmarksSearch
+}
+
+func (a *CLIArgumentsBookmarksSearch) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsBookmarksSearch) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatText, cli.OutputFormatJson}
+}
+
+func (a *CLIArgumentsBookmarksSearch) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks search <query>", "Search bookmarks by title, URL, description, tags and keyword"},
+		{"          [--regex | --fuzzy]", "Interpret the query as a regular expression / match it fuzzy (default is a case-insensitive substring search)"},
+		{"          [--field <title|url|description|tags|keyword>]", "Search only the specified field, can be specified multiple times"},
+		{"          [--within <id|path>]", "Search only in the specified folder (and its subfolders)"},
+		{"          [--added-after <date>]", "Return only bookmarks added after this date (RFC3339 or YYYY-MM-DD)"},
+		{"          [--added-before <date>]", "Return only bookmarks added before this date (RFC3339 or YYYY-MM-DD)"},
+		{"          [--limit <n>]", "Return max <n> results"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksSearch) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks search <query> [--regex | --fuzzy] [--field <f>] [--within <id|path>] [--added-after <date>] [--added-before <date>] [--limit <n>]",
+		"",
+		"Search all bookmark entries (except separators) for the query.",
+		"",
+		"By default the query is searched as a case-insensitive substring, with --regex it is interpreted as a (go) regular expression",
+		"and with --fuzzy the characters of the query only need to appear in order (e.g. `gdoc` matches `Go docs`).",
+		"",
+		"The fields title, url, description, tags and keyword are searched, use --field to search only specific fields.",
+		"With --within only the entries in the specified folder (by record-id, root folder or path) and its subfolders are searched.",
+		"With --added-after and --added-before the results can be filtered by their `dateAdded` field.",
+		"",
+		"The results are ranked by relevance (exact matches and matches in the title/keyword rank higher) and contain the full path of the entry.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksSearch) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Query = positionalArgs[0]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "regex" && arg.Value == nil {
+			a.SearchMode = bookmarksearch.ModeRegex
+			continue
+		}
+		if arg.Key == "fuzzy" && arg.Value == nil {
+			a.SearchMode = bookmarksearch.ModeFuzzy
+			continue
+		}
+		if arg.Key == "field" && arg.Value != nil {
+			a.Fields = append(a.Fields, bookmarksearch.Field(strings.ToLower(*arg.Value)))
+			continue
+		}
+		if arg.Key == "within" && arg.Value != nil {
+			a.Within = langext.Ptr(*arg.Value)
+			continue
+		}
+		if (arg.Key == "added-after" || arg.Key == "added-before") && arg.Value != nil {
+			if _, err := parseDateArg(*arg.Value, time.UTC); err != nil {
+				return fferr.DirectOutput.New("Failed to decode time argument '" + arg.Key + "' (expected format: RFC3339 or YYYY-MM-DD)")
+			}
+			if arg.Key == "added-after" {
+				a.AddedAfter = langext.Ptr(*arg.Value)
+			} else {
+				a.AddedBefore = langext.Ptr(*arg.Value)
+			}
+			continue
+		}
+		if arg.Key == "limit" && arg.Value != nil {
+			if v, err := strconv.ParseInt(*arg.Value, 10, 32); err == nil && v > 0 {
+				a.Limit = langext.Ptr(int(v))
+				continue
+			}
+			return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse number argument '--%s': '%s'", arg.Key, *arg.Value))
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+type bookmarkSearchResult struct {
+	Bookmark models.BookmarkRecord
+	Path     string
+	Score    float64
+	Fields   []bookmarksearch.Field
+}
+
+func (a *CLIArgumentsBookmarksSearch) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Search Bookmarks]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Query", a.Query)
+	ctx.PrintVerboseKV("Mode", a.SearchMode)
+
+	matcher, err := bookmarksearch.NewMatcher(a.SearchMode, a.Query, a.Fields)
+	if err != nil {
+		return fferr.NewDirectOutput(consts.ExitcodeCLIParse, err.Error())
+	}
+
+	var addedAfter *time.Time = nil
+	if a.AddedAfter != nil {
+		t, err := parseDateArg(*a.AddedAfter, ctx.Opt.TimeZone)
+		if err != nil {
+			return fferr.DirectOutput.New("Failed to decode time argument 'added-after' (expected format: RFC3339 or YYYY-MM-DD)")
+		}
+		addedAfter = langext.Ptr(t)
+	}
+
+	var addedBefore *time.Time = nil
+	if a.AddedBefore != nil {
+		t, err := parseDateArg(*a.AddedBefore, ctx.Opt.TimeZone)
+		if err != nil {
+			return fferr.DirectOutput.New("Failed to decode time argument 'added-before' (expected format: RFC3339 or YYYY-MM-DD)")
+		}
+		addedBefore = langext.Ptr(t)
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var within *string = nil
+	if a.Within != nil {
+		id, err := a.resolveBookmarkRef(ctx, client, session, *a.Within, false)
+		if err != nil {
+			return err
+		}
+		within = langext.Ptr(id)
+	}
+
+	// ========================================================================
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	results := make([]bookmarkSearchResult, 0)
+
+	for _, bm := range coll.bookmarks {
+		if bm.Type == models.BookmarkTypeSeparator {
+			continue
+		}
+		if within != nil && (bm.ID == *within || !coll.isAncestor(*within, bm.ID)) {
+			continue
+		}
+		if addedAfter != nil && (bm.DateAdded == nil || !bm.DateAdded.After(*addedAfter)) {
+			continue
+		}
+		if addedBefore != nil && (bm.DateAdded == nil || !bm.DateAdded.Before(*addedBefore)) {
+			continue
+		}
+
+		score, fields := matcher.Match(bm)
+		if score <= 0 {
+			continue
+		}
+
+		results = append(results, bookmarkSearchResult{Bookmark: bm, Path: coll.path(bm.ID), Score: score, Fields: fields})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+
+	ctx.PrintVerbose(fmt.Sprintf("Found %d matching entries", len(results)))
+
+	if a.Limit != nil && len(results) > *a.Limit {
+		results = results[:*a.Limit]
+	}
+
+	// ========================================================================
+
+	return a.printOutput(ctx, results)
+}
+
+func (a *CLIArgumentsBookmarksSearch) printOutput(ctx *cli.FFSContext, results []bookmarkSearchResult) error {
+
+	switch langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable) {
+
+	case cli.OutputFormatTable:
+		table := make([][]string, 0, len(results)+1)
+		table = append(table, []string{"ID", "TYPE", "PATH", "URI", "SCORE"})
+		for _, v := range results {
+			table = append(table, []string{v.Bookmark.ID, string(v.Bookmark.Type), v.Path, v.Bookmark.URI, fmt.Sprintf("%.2f", v.Score)})
+		}
+		ctx.PrintPrimaryOutputTable(table)
+		return nil
+
+	case cli.OutputFormatText:
+		for _, v := range results {
+			if v.Bookmark.URI != "" {
+				ctx.PrintPrimaryOutput(fmt.Sprintf("%s  %s", v.Path, v.Bookmark.URI))
+			} else {
+				ctx.PrintPrimaryOutput(v.Path)
+			}
+		}
+		return nil
+
+	case cli.OutputFormatJson:
+		arr := langext.A{}
+		for _, v := range results {
+			obj := v.Bookmark.ToJSON(ctx)
+			obj["path"] = v.Path
+			obj["score"] = v.Score
+			obj["matchedFields"] = v.Fields
+			arr = append(arr, obj)
+		}
+		ctx.PrintPrimaryOutputJSON(arr)
+		return nil
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
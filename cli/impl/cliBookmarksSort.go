@@ -0,0 +1,206 @@
+package impl
+
+import (
+	"ffsyncclient/bookmarksort"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type CLIArgumentsBookmarksSort struct {
+	Folder    string
+	Options   bookmarksort.Options
+	Recursive bool
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksSort() *CLIArgumentsBookmarksSort {
+	return &CLIArgumentsBookmarksSort{
+		Options: bookmarksort.Options{
+			By:           "",
+			Reverse:      false,
+			FoldersFirst: false,
+		},
+		Recursive: false,
+	}
+}
+
+func (a *CLIArgumentsBookmarksSort) Mode() cli.Mode {
+	return cli.ModeBookmarksSort
+}
+
+func (a *CLIArgumentsBookmarksSort) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsBookmarksSort) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksSort) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks sort <folder> --by <title|url|dateAdded>", "Sort the entries of a bookmark folder"},
+		{"          [--reverse]", "Sort in descending order"},
+		{"          [--recursive]", "Also sort all subfolders"},
+		{"          [--folders-first]", "Place the subfolders before the other entries"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksSort) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks sort <folder> --by <title|url|dateAdded> [--reverse] [--recursive] [--folders-first]",
+		"",
+		"Sort the entries of the folder (by record-id, root folder or path).",
+		"",
+		"Separators are section boundaries, they keep their position and only the entries between two separators are sorted.",
+		"Titles and URLs are compared case-insensitive, folders have no URL and are sorted by their title after all other entries.",
+		"Entries without a `dateAdded` are always placed last, entries with the same value keep their relative order.",
+		"With --folders-first the subfolders of every section are placed before the other entries.",
+		"With --recursive all subfolders (and their subfolders) are sorted too.",
+		"",
+		"Only the `children` of the changed folders are modified, the entries themselves are not changed.",
+		"All changed records are written in a single batch upload, the request fails if the bookmarks were modified by another client in the meantime.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksSort) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Folder = positionalArgs[0]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "by" && arg.Value != nil {
+			key, err := bookmarksort.ParseKey(*arg.Value)
+			if err != nil {
+				return fferr.DirectOutput.New(err.Error())
+			}
+			a.Options.By = key
+			continue
+		}
+		if arg.Key == "reverse" && arg.Value == nil {
+			a.Options.Reverse = true
+			continue
+		}
+		if arg.Key == "recursive" && arg.Value == nil {
+			a.Recursive = true
+			continue
+		}
+		if arg.Key == "folders-first" && arg.Value == nil {
+			a.Options.FoldersFirst = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.Options.By == "" {
+		return fferr.DirectOutput.New("Missing required argument: --by")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksSort) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Sort Bookmarks]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Folder", a.Folder)
+	ctx.PrintVerboseKV("By", a.Options.By)
+	ctx.PrintVerboseKV("Reverse", a.Options.Reverse)
+	ctx.PrintVerboseKV("Recursive", a.Recursive)
+	ctx.PrintVerboseKV("FoldersFirst", a.Options.FoldersFirst)
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query bookmarks")
+
+	folderID, err := a.resolveBookmarkRef(ctx, client, session, a.Folder, false)
+	if err != nil {
+		return err
+	}
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	if folder, ok := coll.bookmarks[folderID]; !ok || folder.Type != models.BookmarkTypeFolder {
+		return fferr.NewDirectOutput(consts.ExitcodeParentNotAFolder, fmt.Sprintf("The record '%s' is not a folder", folderID))
+	}
+
+	folders := []string{folderID}
+	if a.Recursive {
+		for id, bm := range coll.bookmarks {
+			if id != folderID && bm.Type == models.BookmarkTypeFolder && coll.isAncestor(folderID, id) {
+				folders = append(folders, id)
+			}
+		}
+		sort.Strings(folders[1:])
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Sort folders")
+
+	payloads := make([]bookmarkPayload, 0)
+
+	for _, id := range folders {
+		folder := coll.bookmarks[id]
+
+		children := make([]models.BookmarkRecord, 0, len(folder.Children))
+		for _, child := range folder.Children {
+			if bm, ok := coll.bookmarks[child]; ok {
+				children = append(children, bm)
+			} else {
+				children = append(children, models.BookmarkRecord{ID: child}) // kept at the end of its section
+			}
+		}
+
+		sorted := bookmarksort.Children(children, a.Options)
+
+		if slices.Equal(sorted, folder.Children) {
+			ctx.PrintVerbose(fmt.Sprintf("Folder %s is already sorted", id))
+			continue
+		}
+
+		ctx.PrintVerboseKV("Folder", id)
+		ctx.PrintVerboseKV("Folder<old>.children", strings.Join(folder.Children, ", "))
+		ctx.PrintVerboseKV("Folder<new>.children", strings.Join(sorted, ", "))
+
+		plain, err := a.patchChildren(coll.records[id], sorted)
+		if err != nil {
+			return err
+		}
+
+		payloads = append(payloads, bookmarkPayload{ID: id, Plain: []byte(plain)})
+	}
+
+	// ========================================================================
+
+	if len(payloads) > 0 {
+		ctx.PrintVerboseHeader("[3] Update folders")
+
+		err = a.postBookmarkPayloads(ctx, client, session, payloads, langext.Ptr(coll.lastModified))
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Sorted %d folders (%d were already sorted).", len(payloads), len(folders)-len(payloads)))
+	return nil
+}
@@ -0,0 +1,186 @@
+package impl
+
+import (
+	"ffsyncclient/bookmarksearch"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"strings"
+)
+
+type CLIArgumentsBookmarksTagsAdd struct {
+	Tag        string
+	Where      *string
+	SearchMode bookmarksearch.Mode
+	Fields     []bookmarksearch.Field
+	Within     *string
+	DryRun     bool
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksTagsAdd() *CLIArgumentsBookmarksTagsAdd {
+	return &CLIArgumentsBookmarksTagsAdd{
+		Where:      nil,
+		SearchMode: bookmarksearch.ModeSubstring,
+		Fields:     make([]bookmarksearch.Field, 0),
+		Within:     nil,
+		DryRun:     false,
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsAdd) Mode() cli.Mode {
+	return cli.ModeBookmarksTagsAdd
+}
+
+func (a *CLIArgumentsBookmarksTagsAdd) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsBookmarksTagsAdd) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksTagsAdd) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks tags add <tag> --where <query>", "Add a tag to all bookmarks matching the query"},
+		{"          [--regex]", "Interpret the query as a regular expression"},
+		{"          [--field <title|url|description|tags|keyword>]", "Match the query only against the specified field, can be specified multiple times"},
+		{"          [--within <id|path>]", "Only tag bookmarks in the specified folder (and its subfolders)"},
+		{"          [--dry-run]", "Only show the number of affected bookmarks, do not change anything"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsAdd) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks tags add <tag> --where <query> [--regex] [--field <f>] [--within <id|path>] [--dry-run]",
+		"",
+		"Add the tag to all bookmarks that match the query.",
+		"",
+		"The query is matched like in `bookmarks search`: by default as a case-insensitive substring, with --regex as a (go) regular expression.",
+		"With --field the query is only matched against specific fields (default: title, url, description, tags and keyword).",
+		"With --within only the bookmarks in the specified folder (by record-id, root folder or path) and its subfolders are tagged.",
+		"Instead of --where you can also only specify --within to tag all bookmarks in a folder.",
+		"",
+		"All other fields of the bookmarks are not changed.",
+		"All changed records are written in a single batch upload, the request fails if the bookmarks were modified by another client in the meantime.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsAdd) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Tag = positionalArgs[0]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "where" && arg.Value != nil {
+			a.Where = langext.Ptr(*arg.Value)
+			continue
+		}
+		if arg.Key == "regex" && arg.Value == nil {
+			a.SearchMode = bookmarksearch.ModeRegex
+			continue
+		}
+		if arg.Key == "field" && arg.Value != nil {
+			a.Fields = append(a.Fields, bookmarksearch.Field(strings.ToLower(*arg.Value)))
+			continue
+		}
+		if arg.Key == "within" && arg.Value != nil {
+			a.Within = langext.Ptr(*arg.Value)
+			continue
+		}
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.Tag == "" {
+		return fferr.DirectOutput.New("The tag cannot be empty")
+	}
+
+	if a.Where == nil && a.Within == nil {
+		return fferr.DirectOutput.New("Missing required argument: --where (or --within)")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksTagsAdd) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Add Bookmark Tag]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Tag", a.Tag)
+	ctx.PrintVerboseKV("Where", a.Where)
+	ctx.PrintVerboseKV("Within", a.Within)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	var matcher *bookmarksearch.Matcher = nil
+	if a.Where != nil {
+		m, err := bookmarksearch.NewMatcher(a.SearchMode, *a.Where, a.Fields)
+		if err != nil {
+			return fferr.NewDirectOutput(consts.ExitcodeCLIParse, err.Error())
+		}
+		matcher = m
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var within *string = nil
+	if a.Within != nil {
+		id, err := a.resolveBookmarkRef(ctx, client, session, *a.Within, false)
+		if err != nil {
+			return err
+		}
+		within = langext.Ptr(id)
+	}
+
+	// ========================================================================
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	changed, err := a.updateTags(ctx, client, session, coll, a.DryRun, func(bm models.BookmarkRecord) ([]string, bool) {
+		if langext.InArray(a.Tag, bm.Tags) {
+			return nil, false
+		}
+		if within != nil && (bm.ID == *within || !coll.isAncestor(*within, bm.ID)) {
+			return nil, false
+		}
+		if matcher != nil {
+			if score, _ := matcher.Match(bm); score <= 0 {
+				return nil, false
+			}
+		}
+
+		newTags := make([]string, 0, len(bm.Tags)+1)
+		newTags = append(newTags, bm.Tags...)
+		newTags = append(newTags, a.Tag)
+		return newTags, true
+	})
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	if a.DryRun {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("%d bookmarks would be updated, no changes were made (--dry-run).", len(changed)))
+	} else {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Updated %d bookmarks.", len(changed)))
+	}
+	return nil
+}
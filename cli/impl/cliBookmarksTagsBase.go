@@ -0,0 +1,56 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+)
+
+type CLIArgumentsBookmarksTagsBase struct {
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksTagsBase() *CLIArgumentsBookmarksTagsBase {
+	return &CLIArgumentsBookmarksTagsBase{}
+}
+
+func (a *CLIArgumentsBookmarksTagsBase) Mode() cli.Mode {
+	return cli.ModeBookmarksTagsBase
+}
+
+func (a *CLIArgumentsBookmarksTagsBase) PositionArgCount() (*int, *int) {
+	return nil, nil
+}
+
+func (a *CLIArgumentsBookmarksTagsBase) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksTagsBase) ShortHelp() [][]string {
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksTagsBase) FullHelp() []string {
+	r := []string{
+		"$> ffsclient bookmarks tags (list|add|rename|remove)",
+		"====================================================",
+		"",
+		"",
+	}
+	for _, v := range ListSubcommands(a.Mode(), true) {
+		r = append(r, GetModeImpl(v).FullHelp()...)
+		r = append(r, "")
+		r = append(r, "")
+		r = append(r, "")
+	}
+
+	return r
+}
+
+func (a *CLIArgumentsBookmarksTagsBase) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	return fferr.DirectOutput.New("ffsclient bookmarks tags must be called with a subcommand (eg `ffsclient bookmarks tags list`), possible subcommands are [list | add | rename | remove]")
+}
+
+func (a *CLIArgumentsBookmarksTagsBase) Execute(ctx *cli.FFSContext) error {
+	return fferr.NewDirectOutput(consts.ExitcodeError, "Cannot call `bookmarks tags` command without an subcommand")
+}
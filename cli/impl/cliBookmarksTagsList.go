@@ -0,0 +1,133 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"sort"
+	"strconv"
+)
+
+type CLIArgumentsBookmarksTagsList struct {
+	SortByName bool
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksTagsList() *CLIArgumentsBookmarksTagsList {
+	return &CLIArgumentsBookmarksTagsList{
+		SortByName: false,
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsList) Mode() cli.Mode {
+	return cli.ModeBookmarksTagsList
+}
+
+func (a *CLIArgumentsBookmarksTagsList) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsBookmarksTagsList) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatText, cli.OutputFormatJson}
+}
+
+func (a *CLIArgumentsBookmarksTagsList) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks tags list", "List all bookmark tags with the number of bookmarks using them"},
+		{"          [--sort-by-name]", "Sort the tags by name (default is by count)"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsList) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks tags list [--sort-by-name]",
+		"",
+		"List all tags that are used by (non-deleted) bookmarks, together with the number of bookmarks that have the tag.",
+		"",
+		"The tags are sorted by their count (most used tags first), with --sort-by-name they are sorted alphabetically.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsList) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if arg.Key == "sort-by-name" && arg.Value == nil {
+			a.SortByName = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksTagsList) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[List Bookmark Tags]")
+	ctx.PrintVerbose("")
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	counts := make(map[string]int)
+	for _, bm := range coll.bookmarks {
+		if !a.hasTags(bm) {
+			continue
+		}
+		for _, tag := range langext.ArrUnique(bm.Tags) {
+			counts[tag]++
+		}
+	}
+
+	tags := langext.MapKeyArr(counts)
+	sort.Slice(tags, func(i, j int) bool {
+		if !a.SortByName && counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+
+	// ========================================================================
+
+	switch langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable) {
+
+	case cli.OutputFormatTable:
+		table := make([][]string, 0, len(tags)+1)
+		table = append(table, []string{"TAG", "COUNT"})
+		for _, v := range tags {
+			table = append(table, []string{v, strconv.Itoa(counts[v])})
+		}
+		ctx.PrintPrimaryOutputTable(table)
+		return nil
+
+	case cli.OutputFormatText:
+		for _, v := range tags {
+			ctx.PrintPrimaryOutput(fmt.Sprintf("%s (%d)", v, counts[v]))
+		}
+		return nil
+
+	case cli.OutputFormatJson:
+		arr := langext.A{}
+		for _, v := range tags {
+			arr = append(arr, langext.H{"tag": v, "count": counts[v]})
+		}
+		ctx.PrintPrimaryOutputJSON(arr)
+		return nil
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
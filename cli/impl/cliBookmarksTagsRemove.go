@@ -0,0 +1,112 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+)
+
+type CLIArgumentsBookmarksTagsRemove struct {
+	Tag    string
+	DryRun bool
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksTagsRemove() *CLIArgumentsBookmarksTagsRemove {
+	return &CLIArgumentsBookmarksTagsRemove{
+		DryRun: false,
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsRemove) Mode() cli.Mode {
+	return cli.ModeBookmarksTagsRemove
+}
+
+func (a *CLIArgumentsBookmarksTagsRemove) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsBookmarksTagsRemove) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksTagsRemove) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks tags remove <tag>", "Remove a tag from all bookmarks"},
+		{"          [--dry-run]", "Only show the number of affected bookmarks, do not change anything"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsRemove) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks tags remove <tag> [--dry-run]",
+		"",
+		"Remove the tag from all bookmarks.",
+		"",
+		"All other fields of the bookmarks are not changed.",
+		"All changed records are written in a single batch upload, the request fails if the bookmarks were modified by another client in the meantime.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsRemove) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Tag = positionalArgs[0]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksTagsRemove) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Remove Bookmark Tag]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Tag", a.Tag)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	changed, err := a.updateTags(ctx, client, session, coll, a.DryRun, func(bm models.BookmarkRecord) ([]string, bool) {
+		if !langext.InArray(a.Tag, bm.Tags) {
+			return nil, false
+		}
+		return langext.ArrFilter(bm.Tags, func(v string) bool { return v != a.Tag }), true
+	})
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	if a.DryRun {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("%d bookmarks would be updated, no changes were made (--dry-run).", len(changed)))
+	} else {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Updated %d bookmarks.", len(changed)))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+)
+
+type CLIArgumentsBookmarksTagsRename struct {
+	OldTag string
+	NewTag string
+	DryRun bool
+
+	CLIArgumentsBookmarksUtil
+}
+
+func NewCLIArgumentsBookmarksTagsRename() *CLIArgumentsBookmarksTagsRename {
+	return &CLIArgumentsBookmarksTagsRename{
+		DryRun: false,
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsRename) Mode() cli.Mode {
+	return cli.ModeBookmarksTagsRename
+}
+
+func (a *CLIArgumentsBookmarksTagsRename) PositionArgCount() (*int, *int) {
+	return langext.Ptr(2), langext.Ptr(2)
+}
+
+func (a *CLIArgumentsBookmarksTagsRename) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsBookmarksTagsRename) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient bookmarks tags rename <old> <new>", "Rename a tag on all bookmarks"},
+		{"          [--dry-run]", "Only show the number of affected bookmarks, do not change anything"},
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsRename) FullHelp() []string {
+	return []string{
+		"$> ffsclient bookmarks tags rename <old> <new> [--dry-run]",
+		"",
+		"Replace the tag <old> with <new> on all bookmarks.",
+		"",
+		"Bookmarks that already have both tags only keep <new>.",
+		"All other fields of the bookmarks are not changed.",
+		"All changed records are written in a single batch upload, the request fails if the bookmarks were modified by another client in the meantime.",
+	}
+}
+
+func (a *CLIArgumentsBookmarksTagsRename) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.OldTag = positionalArgs[0]
+	a.NewTag = positionalArgs[1]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.NewTag == "" {
+		return fferr.DirectOutput.New("The new tag cannot be empty")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsBookmarksTagsRename) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Rename Bookmark Tag]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("OldTag", a.OldTag)
+	ctx.PrintVerboseKV("NewTag", a.NewTag)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	coll, err := a.loadBookmarkCollection(ctx, client, session)
+	if err != nil {
+		return errorx.Decorate(err, "failed to query bookmarks")
+	}
+
+	changed, err := a.updateTags(ctx, client, session, coll, a.DryRun, func(bm models.BookmarkRecord) ([]string, bool) {
+		if !langext.InArray(a.OldTag, bm.Tags) || a.OldTag == a.NewTag {
+			return nil, false
+		}
+
+		newTags := make([]string, 0, len(bm.Tags))
+		for _, v := range bm.Tags {
+			if v == a.OldTag {
+				v = a.NewTag
+			}
+			if !langext.InArray(v, newTags) {
+				newTags = append(newTags, v)
+			}
+		}
+		return newTags, true
+	})
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	if a.DryRun {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("%d bookmarks would be updated, no changes were made (--dry-run).", len(changed)))
+	} else {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Updated %d bookmarks.", len(changed)))
+	}
+	return nil
+}
@@ -52,7 +52,7 @@ func (a *CLIArgumentsBookmarksUpdate) AvailableOutputFormats() []cli.OutputForma
 
 func (a *CLIArgumentsBookmarksUpdate) ShortHelp() [][]string {
 	return [][]string{
-		{"ffsclient bookmarks update <id>", "Partially update a bookmark"},
+		{"ffsclient bookmarks update <id|path>", "Partially update a bookmark"},
 		{"          [--title <title>]", "Change the bookmark title"},
 		{"          [--url <url>]", "Change the URL"},
 		{"          [--description <desc>]", "Change the bookmark description"},
@@ -65,10 +65,11 @@ func (a *CLIArgumentsBookmarksUpdate) ShortHelp() [][]string {
 
 func (a *CLIArgumentsBookmarksUpdate) FullHelp() []string {
 	return []string{
-		"$> ffsclient bookmarks update <id> [--title <title>] [--url <url>] [--description <desc>] [--load-in-sidebar <true|false>] [--tag <tag>] [--keyword <kw>] [--position=<idx>]",
+		"$> ffsclient bookmarks update <id|path> [--title <title>] [--url <url>] [--description <desc>] [--load-in-sidebar <true|false>] [--tag <tag>] [--keyword <kw>] [--position=<idx>]",
 		"",
 		"Update the specified fields of an existing bookmark entry.",
 		"Supplied values that are not valid for the bookmark type result in an error.",
+		"The entry can also be specified as a path of titles, starting with a root folder (e.g. `toolbar/Dev/Go docs`). Use `\\/` for a `/` in a title.",
 		"",
 		"The fields of the found bookmark can be updated individually with the parameters:",
 		"  * --title",
@@ -145,6 +146,11 @@ func (a *CLIArgumentsBookmarksUpdate) Execute(ctx *cli.FFSContext) error {
 		return err
 	}
 
+	a.RecordID, err = a.resolveBookmarkRef(ctx, client, session, a.RecordID, false)
+	if err != nil {
+		return err
+	}
+
 	// ========================================================================
 
 	ctx.PrintVerboseHeader("[0] Find bookmark")
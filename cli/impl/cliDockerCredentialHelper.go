@@ -0,0 +1,344 @@
+package impl
+
+import (
+	"encoding/json"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/loginmatch"
+	"ffsyncclient/models"
+	"ffsyncclient/syncclient"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"strings"
+	"time"
+)
+
+// magic string, the docker cli checks for this exact message to detect missing credentials
+const dockerCredentialsNotFound = "credentials not found in native keychain"
+
+// httpRealm that marks password records created by the credential helper
+const dockerCredentialRealm = "docker-credential-helper"
+
+// server-url of the docker hub, the docker cli expects exactly this url (with the path) for the docker hub credentials
+const dockerHubServerURL = "https://index.docker.io/v1/"
+
+type dockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+type CLIArgumentsDockerCredentialHelper struct {
+	Action string
+
+	CLIArgumentsPasswordsUtil
+}
+
+func NewCLIArgumentsDockerCredentialHelper() *CLIArgumentsDockerCredentialHelper {
+	return &CLIArgumentsDockerCredentialHelper{
+		Action: "",
+	}
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) Mode() cli.Mode {
+	return cli.ModeDockerCredentialHelper
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatJson}
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient docker-credential-helper (get|store|erase|list)", "Act as a docker credential helper (request is read from stdin)"},
+	}
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) FullHelp() []string {
+	return []string{
+		"$> ffsclient docker-credential-helper (get|store|erase|list)",
+		"",
+		"Implements the docker credential helper protocol on top of the passwords collection",
+		"",
+		"The origin of the registry server-url (scheme, host and port) is stored in the hostname field of the password records.",
+		"  - get:   Reads a server-url from stdin and prints the matching credentials as json ({ServerURL, Username, Secret})",
+		"  - store: Reads credentials as json ({ServerURL, Username, Secret}) from stdin and creates or updates the password record",
+		"  - erase: Reads a server-url from stdin and deletes the matching password record",
+		"  - list:  Prints all stored registry credentials as a json object ({ServerURL: Username})",
+		"",
+		"New records are created with the httpRealm '" + dockerCredentialRealm + "', only these records are returned by `list` and changed by `store` and `erase`.",
+		"If no such record exists, `get` also returns a normal login with the same origin.",
+		"Server-urls are compared by their origin (e.g. 'https://registry.example.com/v2/' matches 'registry.example.com').",
+		"",
+		"If the executable is called as `docker-credential-ffsclient` this subcommand is automatically selected.",
+		"To use it with docker create a symlink with that name in your $PATH and set {\"credsStore\": \"ffsclient\"} in ~/.docker/config.json",
+		"",
+		"If no matching credentials are found the exitcode [82] is returned",
+	}
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	if !langext.InArray(positionalArgs[0], []string{"get", "store", "erase", "list"}) {
+		return fferr.DirectOutput.New("Unknown docker-credential-helper action: '" + positionalArgs[0] + "' (must be one of get|store|erase|list)")
+	}
+	a.Action = positionalArgs[0]
+
+	if len(optionArgs) > 0 {
+		return fferr.DirectOutput.New("Unknown argument: " + optionArgs[0].Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Docker Credential Helper]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Action", a.Action)
+
+	input := ""
+	if a.Action != "list" {
+		stdin, err := ctx.ReadStdIn()
+		if err != nil {
+			return err
+		}
+		input = stdin
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	records, err := client.ListRecords(ctx, session, consts.CollectionPasswords, nil, nil, false, true, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	passwords, err := models.UnmarshalPasswords(ctx, records, true)
+	if err != nil {
+		return err
+	}
+
+	passwords = a.filterDeleted(ctx, passwords, false, false)
+
+	// ========================================================================
+
+	switch a.Action {
+	case "get":
+		return a.executeGet(ctx, passwords, strings.TrimSpace(input))
+	case "store":
+		return a.executeStore(ctx, client, session, records, passwords, input)
+	case "erase":
+		return a.executeErase(ctx, client, session, passwords, strings.TrimSpace(input))
+	case "list":
+		return a.executeList(ctx, passwords)
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeError, "Unknown docker-credential-helper action: '"+a.Action+"'")
+	}
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) executeGet(ctx *cli.FFSContext, passwords []models.PasswordRecord, serverURL string) error {
+	ctx.PrintVerboseKV("ServerURL", serverURL)
+
+	pwrec, found := a.findDockerRecord(passwords, serverURL, true)
+	if !found {
+		ctx.PrintPrimaryOutput(dockerCredentialsNotFound)
+		return fferr.NewEmpty(consts.ExitcodePasswordNotFound)
+	}
+
+	ctx.PrintVerbose("Found record " + pwrec.ID)
+
+	ctx.PrintPrimaryOutputJSON(dockerCredentials{
+		ServerURL: serverURL,
+		Username:  pwrec.Username,
+		Secret:    pwrec.Password,
+	})
+	return nil
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) executeStore(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, records []models.Record, passwords []models.PasswordRecord, input string) error {
+	var creds dockerCredentials
+	err := json.Unmarshal([]byte(input), &creds)
+	if err != nil {
+		return fferr.WrapDirectOutput(err, consts.ExitcodeError, "Failed to decode credentials from stdin")
+	}
+
+	if creds.ServerURL == "" {
+		return fferr.NewDirectOutput(consts.ExitcodeError, "Missing field 'ServerURL' in credentials")
+	}
+
+	origin, err := loginmatch.ParseOrigin(creds.ServerURL)
+	if err != nil {
+		return fferr.WrapDirectOutput(err, consts.ExitcodeError, "Failed to parse field 'ServerURL' in credentials")
+	}
+
+	ctx.PrintVerboseKV("ServerURL", creds.ServerURL)
+	ctx.PrintVerboseKV("Origin", origin.String())
+	ctx.PrintVerboseKV("Username", creds.Username)
+
+	now := time.Now()
+
+	var recordID string
+	var plainPayload []byte
+
+	if pwrec, found := a.findDockerRecord(passwords, creds.ServerURL, false); found {
+
+		ctx.PrintVerbose("Update existing record " + pwrec.ID)
+
+		if pwrec.Username == creds.Username && pwrec.Password == creds.Secret {
+			ctx.PrintVerbose("Do not update record (nothing to do)")
+			return nil
+		}
+
+		record, ok := langext.ArrFirst(records, func(v models.Record) bool { return v.ID == pwrec.ID })
+		if !ok {
+			return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, "Record not found")
+		}
+
+		recordID = pwrec.ID
+		plainPayload = record.DecodedData
+
+		plainPayload, err = langext.PatchJson(plainPayload, "username", creds.Username)
+		if err != nil {
+			return errorx.Decorate(err, "failed to patch data of existing record")
+		}
+
+		if pwrec.Password != creds.Secret {
+			plainPayload, err = langext.PatchJson(plainPayload, "password", creds.Secret)
+			if err != nil {
+				return errorx.Decorate(err, "failed to patch data of existing record")
+			}
+
+			plainPayload, err = langext.PatchJson(plainPayload, "timePasswordChanged", now.UnixMilli())
+			if err != nil {
+				return errorx.Decorate(err, "failed to patch data of existing record")
+			}
+		}
+
+	} else {
+
+		recordID = a.newPasswordID()
+
+		ctx.PrintVerbose("Create new record " + recordID)
+
+		bso := models.PasswordPayloadSchema{
+			ID:                  recordID,
+			Hostname:            origin.String(),
+			FormSubmitURL:       "",
+			HTTPRealm:           langext.Ptr(dockerCredentialRealm),
+			Username:            creds.Username,
+			Password:            creds.Secret,
+			UsernameField:       "",
+			PasswordField:       "",
+			TimeCreated:         langext.Ptr(now.UnixMilli()),
+			TimePasswordChanged: langext.Ptr(now.UnixMilli()),
+		}
+
+		plainPayload, err = json.Marshal(bso)
+		if err != nil {
+			return errorx.Decorate(err, "failed to marshal BSO json")
+		}
+
+	}
+
+	payload, err := client.EncryptPayload(ctx, session, consts.CollectionPasswords, string(plainPayload))
+	if err != nil {
+		return err
+	}
+
+	update := models.RecordUpdate{
+		ID:      recordID,
+		Payload: langext.Ptr(payload),
+	}
+
+	err = client.PutRecord(ctx, session, consts.CollectionPasswords, update, false, false)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) executeErase(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, passwords []models.PasswordRecord, serverURL string) error {
+	ctx.PrintVerboseKV("ServerURL", serverURL)
+
+	pwrec, found := a.findDockerRecord(passwords, serverURL, false)
+	if !found {
+		ctx.PrintPrimaryOutput(dockerCredentialsNotFound)
+		return fferr.NewEmpty(consts.ExitcodePasswordNotFound)
+	}
+
+	ctx.PrintVerbose("Delete Record " + pwrec.ID)
+
+	err := client.SoftDeleteRecord(ctx, session, consts.CollectionPasswords, pwrec.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsDockerCredentialHelper) executeList(ctx *cli.FFSContext, passwords []models.PasswordRecord) error {
+	result := make(map[string]string)
+
+	for _, v := range passwords {
+		if langext.Coalesce(v.HTTPRealm, "") != dockerCredentialRealm {
+			ctx.PrintVerbose(fmt.Sprintf("Skip entry %v (not created by the docker-credential-helper)", v.ID))
+			continue
+		}
+		if a.dockerServerKey(v.Hostname) == a.dockerServerKey(dockerHubServerURL) {
+			result[dockerHubServerURL] = v.Username
+		} else {
+			result[v.Hostname] = v.Username
+		}
+	}
+
+	ctx.PrintPrimaryOutputJSON(result)
+	return nil
+}
+
+// findDockerRecord returns the password record (created by the credential helper) for the registry
+// If allowFallback is true and there is no such record, a normal login with the same origin is returned.
+func (a *CLIArgumentsDockerCredentialHelper) findDockerRecord(passwords []models.PasswordRecord, serverURL string, allowFallback bool) (models.PasswordRecord, bool) {
+	key := a.dockerServerKey(serverURL)
+
+	var fallback *models.PasswordRecord
+
+	for _, v := range passwords {
+		if a.dockerServerKey(v.Hostname) != key {
+			continue
+		}
+		if langext.Coalesce(v.HTTPRealm, "") == dockerCredentialRealm {
+			return v, true
+		}
+		if fallback == nil && allowFallback {
+			fallback = langext.Ptr(v)
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+
+	return models.PasswordRecord{}, false
+}
+
+// dockerServerKey normalizes a registry url to its origin, so that eg `https://registry.example.com/v2/` and `registry.example.com` are equal
+func (a *CLIArgumentsDockerCredentialHelper) dockerServerKey(v string) string {
+	origin, err := loginmatch.ParseOrigin(v)
+	if err != nil {
+		return strings.TrimRight(strings.ToLower(strings.TrimSpace(v)), "/")
+	}
+
+	return origin.String()
+}
@@ -0,0 +1,216 @@
+package impl
+
+import (
+	"errors"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+)
+
+type execEnvEntry struct {
+	Name  string
+	Query string
+	Field string // username | password
+}
+
+type CLIArgumentsExec struct {
+	Command          []string
+	Env              []execEnvEntry
+	QueryIsHost      bool
+	QueryIsExactHost bool
+	QueryIsID        bool
+
+	CLIArgumentsPasswordsUtil
+}
+
+func NewCLIArgumentsExec() *CLIArgumentsExec {
+	return &CLIArgumentsExec{
+		Command:          nil,
+		Env:              make([]execEnvEntry, 0),
+		QueryIsHost:      false,
+		QueryIsExactHost: false,
+		QueryIsID:        false,
+	}
+}
+
+func (a *CLIArgumentsExec) Mode() cli.Mode {
+	return cli.ModeExec
+}
+
+func (a *CLIArgumentsExec) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), nil
+}
+
+func (a *CLIArgumentsExec) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsExec) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient exec --env <name>=<query>[:username|:password] -- <cmd> [<args>...]", "Run a command with passwords injected as environment variables"},
+		{"          [--is-host | --is-exact-host | --is-id]", "Specify that the supplied queries are a host / record-id (otherwise both is possible)"},
+	}
+}
+
+func (a *CLIArgumentsExec) FullHelp() []string {
+	return []string{
+		"$> ffsclient exec --env <name>=<query>[:username|:password] [--env ...] [--is-host | --is-exact-host | --is-id] -- <cmd> [<args>...]",
+		"",
+		"Run a command with passwords injected as environment variables",
+		"",
+		"Every --env parameter resolves <query> to a single password and sets the environment variable <name> for the child process.",
+		"By default the password is injected, append ':username' or ':password' to the query to choose the field explicitly.",
+		"The query can be a record-id, the exact host field or an URI with the same host (logins on subdomains or the base-domain are not matched).",
+		"If --is-host is specified, the queries are parsed as an URI and we use the password with the same host.",
+		"An exact origin match (scheme, host and port) is preferred over an http login on an https origin, which is preferred over a login with another scheme or port.",
+		"If --is-exact-host is specified, the queries are matched exactly against the host field in the password record.",
+		"If --is-id is specified, the queries are matched exactly agains the record-id.",
+		"",
+		"The secrets are never printed (not even with --verbose) and never written to disk.",
+		"The exitcode of the child process is passed through.",
+		"If no matching password is found the command is not executed and the exitcode [82] is returned",
+		"If a query matches multiple passwords the command is not executed and the exitcode [91] is returned",
+	}
+}
+
+func (a *CLIArgumentsExec) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.Command = positionalArgs
+
+	for _, arg := range optionArgs {
+		if arg.Key == "env" && arg.Value != nil {
+			entry, err := a.parseEnvArg(*arg.Value)
+			if err != nil {
+				return err
+			}
+			a.Env = append(a.Env, entry)
+			continue
+		}
+		if arg.Key == "is-host" && arg.Value == nil {
+			a.QueryIsHost = true
+			continue
+		}
+		if arg.Key == "is-exact-host" && arg.Value == nil {
+			a.QueryIsExactHost = true
+			continue
+		}
+		if arg.Key == "is-id" && arg.Value == nil {
+			a.QueryIsID = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsExec) parseEnvArg(v string) (execEnvEntry, error) {
+	idx := strings.Index(v, "=")
+	if idx <= 0 {
+		return execEnvEntry{}, fferr.DirectOutput.New("Invalid --env value: '" + v + "' (expected format: <name>=<query>[:username|:password])")
+	}
+
+	name := v[:idx]
+	query := v[idx+1:]
+	field := "password"
+
+	if strings.HasSuffix(query, ":username") {
+		query = strings.TrimSuffix(query, ":username")
+		field = "username"
+	} else if strings.HasSuffix(query, ":password") {
+		query = strings.TrimSuffix(query, ":password")
+		field = "password"
+	}
+
+	if query == "" {
+		return execEnvEntry{}, fferr.DirectOutput.New("Invalid --env value: '" + v + "' (empty query)")
+	}
+
+	return execEnvEntry{Name: name, Query: query, Field: field}, nil
+}
+
+func (a *CLIArgumentsExec) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Exec]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Command", strings.Join(a.Command, " "))
+
+	if langext.BoolCount(a.QueryIsID, a.QueryIsExactHost, a.QueryIsHost) > 1 {
+		return fferr.NewDirectOutput(consts.ExitcodeError, "Must specify at most one of --id, --exact-host, --host")
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	candidates, err := a.listPasswordCandidates(ctx, client, session)
+	if err != nil {
+		return err
+	}
+
+	env := os.Environ()
+
+	for _, entry := range a.Env {
+		ctx.PrintVerbose("Resolve environment variable " + entry.Name + " (" + entry.Field + " of '" + entry.Query + "')")
+
+		match, found, err := a.resolvePasswordRecord(ctx, candidates, entry.Query, a.QueryIsID, a.QueryIsHost, a.QueryIsExactHost)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return fferr.NewDirectOutput(consts.ExitcodePasswordNotFound, "No password found for '"+entry.Query+"' (environment variable "+entry.Name+")")
+		}
+
+		if entry.Field == "username" {
+			env = append(env, entry.Name+"="+match.Password.Username)
+		} else {
+			env = append(env, entry.Name+"="+match.Password.Password)
+		}
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerbose("Start child process")
+
+	cmd := exec.Command(a.Command[0], a.Command[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Start()
+	if err != nil {
+		return fferr.WrapDirectOutput(err, consts.ExitcodeError, "Failed to start command '"+a.Command[0]+"'")
+	}
+
+	// the child receives the interrupt itself (same process group), we only need to wait for it to exit
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, os.Interrupt)
+	defer signal.Stop(sigchan)
+
+	err = cmd.Wait()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		ctx.PrintVerboseKV("ExitCode", exitErr.ExitCode())
+		if exitErr.ExitCode() < 0 {
+			return fferr.WrapDirectOutput(err, consts.ExitcodeError, "Command was terminated by a signal")
+		}
+		return fferr.NewEmpty(consts.FFExitCode{Raw: exitErr.ExitCode()})
+	}
+	if err != nil {
+		return errorx.Decorate(err, "failed to run command")
+	}
+
+	return nil
+}
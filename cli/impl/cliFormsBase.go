@@ -5,6 +5,7 @@ import (
 	"ffsyncclient/consts"
 	"ffsyncclient/fferr"
 	"ffsyncclient/models"
+	"ffsyncclient/syncclient"
 	"fmt"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
 )
@@ -35,8 +36,8 @@ func (a *CLIArgumentsFormsBase) ShortHelp() [][]string {
 
 func (a *CLIArgumentsFormsBase) FullHelp() []string {
 	r := []string{
-		"$> ffsclient forms (list|delete|create|update|get)",
-		"======================================================",
+		"$> ffsclient forms (list|delete|create|update|get|purge|scan)",
+		"===============================================================",
 		"",
 		"",
 	}
@@ -90,3 +91,50 @@ func (a *CLIArgumentsFormsUtil) filterDeleted(ctx *cli.FFSContext, records []mod
 func (a *CLIArgumentsFormsUtil) newFormID() string {
 	return langext.RandBase62(16)
 }
+
+// queryForms returns all (not deleted) form entries
+func (a *CLIArgumentsFormsUtil) queryForms(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, ignoreSchemaErrors bool) ([]models.FormRecord, error) {
+	records, err := client.ListRecords(ctx, session, consts.CollectionForms, nil, nil, false, true, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	forms, err := models.UnmarshalForms(ctx, records, ignoreSchemaErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.filterDeleted(ctx, forms, false, false, nil), nil
+}
+
+// purgeForms replaces the entries with tombstones, after asking for confirmation (unless yes == true)
+func (a *CLIArgumentsFormsUtil) purgeForms(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, entries []models.FormRecord, dryRun bool, yes bool) error {
+	if dryRun {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Found %d matching form entries, no changes were made (--dry-run).", len(entries)))
+		return nil
+	}
+
+	if !yes {
+		ok, err := a.confirm(fmt.Sprintf("Delete %d form entries?", len(entries)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			ctx.PrintPrimaryOutput("Aborted.")
+			return nil
+		}
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, v := range entries {
+		ids = append(ids, v.ID)
+	}
+
+	err := client.SoftDeleteRecords(ctx, session, consts.CollectionForms, ids)
+	if err != nil {
+		return err
+	}
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Deleted %d form entries.", len(ids)))
+	return nil
+}
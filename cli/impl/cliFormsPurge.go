@@ -0,0 +1,162 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"regexp"
+)
+
+type CLIArgumentsFormsPurge struct {
+	NameRegex          *regexp.Regexp
+	ValueRegex         *regexp.Regexp
+	DryRun             bool
+	Yes                bool
+	IgnoreSchemaErrors bool
+
+	CLIArgumentsFormsUtil
+}
+
+func NewCLIArgumentsFormsPurge() *CLIArgumentsFormsPurge {
+	return &CLIArgumentsFormsPurge{
+		NameRegex:          nil,
+		ValueRegex:         nil,
+		DryRun:             false,
+		Yes:                false,
+		IgnoreSchemaErrors: false,
+	}
+}
+
+func (a *CLIArgumentsFormsPurge) Mode() cli.Mode {
+	return cli.ModeFormsPurge
+}
+
+func (a *CLIArgumentsFormsPurge) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsFormsPurge) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsFormsPurge) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient forms purge", "Delete all HTML-Form autocomplete suggestions with a matching field name and/or value"},
+		{"          [--name <regex>]", "Delete the entries whose field name matches the (go) regular expression"},
+		{"          [--value <regex>]", "Delete the entries whose value matches the (go) regular expression"},
+		{"          [--dry-run]", "Only list the matching entries, do not change anything"},
+		{"          [--yes]", "Do not ask for confirmation"},
+		{"          [--ignore-schema-errors]", "Skip records that cannot be decoded into a form schema"},
+	}
+}
+
+func (a *CLIArgumentsFormsPurge) FullHelp() []string {
+	return []string{
+		"$> ffsclient forms purge [--name <regex>] [--value <regex>] [--dry-run] [--yes] [--ignore-schema-errors]",
+		"",
+		"Delete all (not deleted) HTML-Form autocomplete suggestions that match all of the specified filters (at least one filter is required).",
+		"The regular expressions are not anchored, use `^...$` to match the whole name or value (e.g. `--name '^(cc-number|iban)$'`).",
+		"",
+		"The entries are replaced by tombstones ({id, deleted:true}) in batches, so that the other devices also delete them on their next sync.",
+		"If --dry-run is specified only the matching entries are listed and nothing is changed.",
+		"Before changing anything you are asked for confirmation, this can be skipped with --yes.",
+		"If --ignore-schema-errors is not supplied the programm returns with exitcode [60] if any record in the forms collection has invalid data. Otherwise we simply skip that record.",
+	}
+}
+
+func (a *CLIArgumentsFormsPurge) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if (arg.Key == "name" || arg.Key == "value") && arg.Value != nil {
+			rex, err := regexp.Compile(*arg.Value)
+			if err != nil {
+				return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse regular expression argument '--%s': %s", arg.Key, err.Error()))
+			}
+			if arg.Key == "name" {
+				a.NameRegex = rex
+			} else {
+				a.ValueRegex = rex
+			}
+			continue
+		}
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		if arg.Key == "yes" && arg.Value == nil {
+			a.Yes = true
+			continue
+		}
+		if arg.Key == "ignore-schema-errors" && arg.Value == nil {
+			a.IgnoreSchemaErrors = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.NameRegex == nil && a.ValueRegex == nil {
+		return fferr.DirectOutput.New("Missing required argument: --name or --value")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsFormsPurge) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Purge Forms]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Name", a.NameRegex)
+	ctx.PrintVerboseKV("Value", a.ValueRegex)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query forms")
+
+	forms, err := a.queryForms(ctx, client, session, a.IgnoreSchemaErrors)
+	if err != nil {
+		return err
+	}
+
+	matches := make([]models.FormRecord, 0)
+	for _, v := range forms {
+		if a.NameRegex != nil && !a.NameRegex.MatchString(v.Name) {
+			continue
+		}
+		if a.ValueRegex != nil && !a.ValueRegex.MatchString(v.Value) {
+			continue
+		}
+		ctx.PrintVerbose(fmt.Sprintf("Match entry %v (%s)", v.ID, v.Name))
+		matches = append(matches, v)
+	}
+
+	if len(matches) == 0 {
+		ctx.PrintPrimaryOutput("No matching form entries found.")
+		return nil
+	}
+
+	if a.DryRun {
+		for _, v := range matches {
+			ctx.PrintPrimaryOutput(fmt.Sprintf("%s  %s = %s", v.ID, v.Name, v.Value))
+		}
+		ctx.PrintPrimaryOutput("")
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Delete entries")
+
+	return a.purgeForms(ctx, client, session, matches, a.DryRun, a.Yes)
+}
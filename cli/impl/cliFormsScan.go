@@ -0,0 +1,284 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"ffsyncclient/secretscan"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"slices"
+)
+
+type CLIArgumentsFormsScan struct {
+	Kinds              []secretscan.Kind
+	ShowValues         bool
+	Purge              bool
+	DryRun             bool
+	Yes                bool
+	IgnoreSchemaErrors bool
+
+	CLIArgumentsFormsUtil
+}
+
+type formScanResult struct {
+	Form     models.FormRecord
+	Findings []secretscan.Finding
+}
+
+func NewCLIArgumentsFormsScan() *CLIArgumentsFormsScan {
+	return &CLIArgumentsFormsScan{
+		Kinds:              make([]secretscan.Kind, 0),
+		ShowValues:         false,
+		Purge:              false,
+		DryRun:             false,
+		Yes:                false,
+		IgnoreSchemaErrors: false,
+	}
+}
+
+func (a *CLIArgumentsFormsScan) Mode() cli.Mode {
+	return cli.ModeFormsScan
+}
+
+func (a *CLIArgumentsFormsScan) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsFormsScan) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatText, cli.OutputFormatJson, cli.OutputFormatCSV, cli.OutputFormatTSV}
+}
+
+func (a *CLIArgumentsFormsScan) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient forms scan", "Find HTML-Form autocomplete suggestions that contain credit card numbers, IBANs, API tokens or email addresses"},
+		{"          [--kind <kind>]", "Only search for (credit-card|iban|token|email), can be specified multiple times"},
+		{"          [--show-values]", "Show the found values in clear text (instead of masked)"},
+		{"          [--purge]", "Delete the found entries"},
+		{"          [--dry-run]", "Only list the found entries, do not delete anything (with --purge)"},
+		{"          [--yes]", "Do not ask for confirmation (with --purge)"},
+		{"          [--ignore-schema-errors]", "Skip records that cannot be decoded into a form schema"},
+	}
+}
+
+func (a *CLIArgumentsFormsScan) FullHelp() []string {
+	return []string{
+		"$> ffsclient forms scan [--kind <kind>] [--show-values] [--purge [--dry-run] [--yes]] [--ignore-schema-errors]",
+		"",
+		"Search the values of all (not deleted) HTML-Form autocomplete suggestions for sensitive data that was typed into a form field (and is now synced to all devices):",
+		"  * credit-card: card numbers with a known prefix and a valid luhn checksum",
+		"  * iban:        bank account numbers with a valid (mod 97) checksum",
+		"  * token:       API keys and access tokens (github, gitlab, slack, aws, google, stripe, openai, npm, jwt, private keys) and other random looking strings",
+		"  * email:       email addresses",
+		"The search can be limited to specific kinds with --kind (can be specified multiple times).",
+		"",
+		"The found values are masked in the output, use --show-values to show them in clear text.",
+		"",
+		"With --purge the found entries are deleted (replaced by tombstones, so that the other devices also delete them on their next sync).",
+		"Before deleting anything you are asked for confirmation, this can be skipped with --yes.",
+		"If --dry-run is specified together with --purge only the found entries are listed and nothing is changed.",
+		"--purge can only be used with the output-formats table and text.",
+		"",
+		"If --ignore-schema-errors is not supplied the programm returns with exitcode [60] if any record in the forms collection has invalid data. Otherwise we simply skip that record.",
+	}
+}
+
+func (a *CLIArgumentsFormsScan) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if arg.Key == "kind" && arg.Value != nil {
+			k, ok := secretscan.ParseKind(*arg.Value)
+			if !ok {
+				return fferr.DirectOutput.New(fmt.Sprintf("Unknown kind '%s' (possible values: credit-card, iban, token, email)", *arg.Value))
+			}
+			a.Kinds = append(a.Kinds, k)
+			continue
+		}
+		if arg.Key == "show-values" && arg.Value == nil {
+			a.ShowValues = true
+			continue
+		}
+		if arg.Key == "purge" && arg.Value == nil {
+			a.Purge = true
+			continue
+		}
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		if arg.Key == "yes" && arg.Value == nil {
+			a.Yes = true
+			continue
+		}
+		if arg.Key == "ignore-schema-errors" && arg.Value == nil {
+			a.IgnoreSchemaErrors = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if (a.DryRun || a.Yes) && !a.Purge {
+		return fferr.DirectOutput.New("--dry-run and --yes can only be used together with --purge")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsFormsScan) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Scan Forms]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("Kinds", a.Kinds)
+	ctx.PrintVerboseKV("Purge", a.Purge)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	ofmt := langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable)
+	if a.Purge && ofmt != cli.OutputFormatTable && ofmt != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format (with --purge): "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query forms")
+
+	forms, err := a.queryForms(ctx, client, session, a.IgnoreSchemaErrors)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Scan values")
+
+	results := make([]formScanResult, 0)
+	for _, v := range forms {
+		findings := make([]secretscan.Finding, 0)
+		for _, f := range secretscan.Scan(v.Value) {
+			if len(a.Kinds) == 0 || slices.Contains(a.Kinds, f.Kind) {
+				findings = append(findings, f)
+			}
+		}
+		if len(findings) == 0 {
+			continue
+		}
+
+		ctx.PrintVerbose(fmt.Sprintf("Entry %v (%s) contains %d sensitive values", v.ID, v.Name, len(findings)))
+		results = append(results, formScanResult{Form: v, Findings: findings})
+	}
+
+	// ========================================================================
+
+	err = a.printOutput(ctx, results)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 || (ofmt != cli.OutputFormatTable && ofmt != cli.OutputFormatText) {
+		return nil
+	}
+
+	if !a.Purge {
+		ctx.PrintPrimaryOutput("")
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Found %d form entries with sensitive values, run the scan again with --purge to delete them.", len(results)))
+		return nil
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[3] Delete entries")
+
+	entries := make([]models.FormRecord, 0, len(results))
+	for _, v := range results {
+		entries = append(entries, v.Form)
+	}
+
+	ctx.PrintPrimaryOutput("")
+	return a.purgeForms(ctx, client, session, entries, a.DryRun, a.Yes)
+}
+
+func (a *CLIArgumentsFormsScan) formatValue(v string) string {
+	if a.ShowValues {
+		return v
+	}
+	return secretscan.Mask(v)
+}
+
+func (a *CLIArgumentsFormsScan) printOutput(ctx *cli.FFSContext, results []formScanResult) error {
+	ofmt := langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable)
+	switch ofmt {
+
+	case cli.OutputFormatTable:
+		if len(results) == 0 {
+			ctx.PrintPrimaryOutput("No sensitive values found.")
+			return nil
+		}
+		table := make([][]string, 0, len(results)+1)
+		table = append(table, []string{"ID", "NAME", "KIND", "DETAIL", "VALUE"})
+		for _, r := range results {
+			for _, f := range r.Findings {
+				table = append(table, []string{r.Form.ID, r.Form.Name, string(f.Kind), f.Detail, a.formatValue(f.Match)})
+			}
+		}
+		ctx.PrintPrimaryOutputTable(table)
+		return nil
+
+	case cli.OutputFormatText:
+		if len(results) == 0 {
+			ctx.PrintPrimaryOutput("No sensitive values found.")
+			return nil
+		}
+		for _, r := range results {
+			ctx.PrintPrimaryOutput("ID:    " + r.Form.ID)
+			ctx.PrintPrimaryOutput("Name:  " + r.Form.Name)
+			ctx.PrintPrimaryOutput("Value: " + a.formatValue(r.Form.Value))
+			for _, f := range r.Findings {
+				if f.Detail != "" {
+					ctx.PrintPrimaryOutput(fmt.Sprintf("  - %s (%s): %s", f.Kind, f.Detail, a.formatValue(f.Match)))
+				} else {
+					ctx.PrintPrimaryOutput(fmt.Sprintf("  - %s: %s", f.Kind, a.formatValue(f.Match)))
+				}
+			}
+			ctx.PrintPrimaryOutput("")
+		}
+		return nil
+
+	case cli.OutputFormatJson:
+		json := langext.A{}
+		for _, r := range results {
+			findings := langext.A{}
+			for _, f := range r.Findings {
+				findings = append(findings, langext.H{"kind": string(f.Kind), "detail": f.Detail, "value": a.formatValue(f.Match)})
+			}
+			json = append(json, langext.H{
+				"id":       r.Form.ID,
+				"name":     r.Form.Name,
+				"value":    a.formatValue(r.Form.Value),
+				"findings": findings,
+			})
+		}
+		ctx.PrintPrimaryOutputJSON(json)
+		return nil
+
+	case cli.OutputFormatTSV:
+		fallthrough
+	case cli.OutputFormatCSV:
+		table := make([][]string, 0, len(results)+1)
+		table = append(table, []string{"ID", "Name", "Kind", "Detail", "Value"})
+		for _, r := range results {
+			for _, f := range r.Findings {
+				table = append(table, []string{r.Form.ID, r.Form.Name, string(f.Kind), f.Detail, a.formatValue(f.Match)})
+			}
+		}
+		ctx.PrintPrimaryOutputCSV(table, ofmt == cli.OutputFormatTSV)
+		return nil
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
@@ -0,0 +1,182 @@
+package impl
+
+import (
+	"encoding/json"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+)
+
+type CLIArgumentsFormsUpdate struct {
+	RecordID string
+	NewName  *string
+	NewValue *string
+
+	CLIArgumentsFormsUtil
+}
+
+func NewCLIArgumentsFormsUpdate() *CLIArgumentsFormsUpdate {
+	return &CLIArgumentsFormsUpdate{
+		RecordID: "",
+		NewName:  nil,
+		NewValue: nil,
+	}
+}
+
+func (a *CLIArgumentsFormsUpdate) Mode() cli.Mode {
+	return cli.ModeFormsUpdate
+}
+
+func (a *CLIArgumentsFormsUpdate) PositionArgCount() (*int, *int) {
+	return langext.Ptr(1), langext.Ptr(1)
+}
+
+func (a *CLIArgumentsFormsUpdate) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsFormsUpdate) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient forms update <id>", "Update an existing HTML-Form autocomplete suggestion (replaces it with a new record and prints the new ID)"},
+		{"          [--name <n>]", "Update the name of the form field"},
+		{"          [--value <v>]", "Update the suggested value"},
+	}
+}
+
+func (a *CLIArgumentsFormsUpdate) FullHelp() []string {
+	return []string{
+		"$> ffsclient forms update <id> [--name <n>] [--value <v>]",
+		"",
+		"Update the name and/or the value of an existing HTML-Form autocomplete suggestion.",
+		"Firefox ignores changes to existing form records, so the entry is replaced by a new record:",
+		"the old record is deleted (replaced by a tombstone) and a new record with a new ID is created.",
+		"The new ID is printed (if nothing changed, the ID of the existing entry is printed).",
+		"If no (not deleted) entry with this ID is found the exitcode [66] is returned.",
+	}
+}
+
+func (a *CLIArgumentsFormsUpdate) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	a.RecordID = positionalArgs[0]
+
+	for _, arg := range optionArgs {
+		if arg.Key == "name" && arg.Value != nil {
+			a.NewName = langext.Ptr(*arg.Value)
+			continue
+		}
+		if arg.Key == "value" && arg.Value != nil {
+			a.NewValue = langext.Ptr(*arg.Value)
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.NewName == nil && a.NewValue == nil {
+		return fferr.DirectOutput.New("Missing required argument: --name or --value")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsFormsUpdate) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Update Form]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("RecordID", a.RecordID)
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Get Record")
+
+	record, err := client.GetRecord(ctx, session, consts.CollectionForms, a.RecordID, true)
+	if err != nil && errorx.IsOfType(err, fferr.Request404) {
+		return fferr.WrapDirectOutput(err, consts.ExitcodeRecordNotFound, "Record not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	form, err := models.UnmarshalForm(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	if form.Deleted {
+		return fferr.NewDirectOutput(consts.ExitcodeRecordNotFound, "Record not found (entry is deleted)")
+	}
+
+	// ========================================================================
+
+	newName := langext.Coalesce(a.NewName, form.Name)
+	newValue := langext.Coalesce(a.NewValue, form.Value)
+
+	if newName == form.Name && newValue == form.Value {
+		ctx.PrintVerbose("Do not update record (nothing to do)")
+
+		ctx.PrintPrimaryOutput(record.ID)
+		return nil
+	}
+
+	// ========================================================================
+
+	// Firefox ignores changes to existing form records, so the entry is replaced by a new record (with a new ID)
+
+	recordID := a.newFormID()
+
+	ctx.PrintVerboseHeader("[2] Create new record")
+
+	ctx.PrintVerbose(fmt.Sprintf("Replace \"%s\" = \"%s\" with \"%s\" = \"%s\" (new id: %s)", form.Name, form.Value, newName, newValue, recordID))
+
+	bso := models.FormPayloadSchema{
+		ID:    recordID,
+		Name:  newName,
+		Value: newValue,
+	}
+
+	plainPayload, err := json.Marshal(bso)
+	if err != nil {
+		return errorx.Decorate(err, "failed to marshal BSO json")
+	}
+
+	payloadNewRecord, err := client.EncryptPayload(ctx, session, consts.CollectionForms, string(plainPayload))
+	if err != nil {
+		return err
+	}
+
+	update := models.RecordUpdate{
+		ID:      recordID,
+		Payload: langext.Ptr(payloadNewRecord),
+	}
+
+	err = client.PutRecord(ctx, session, consts.CollectionForms, update, true, false)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[3] Delete old record")
+
+	err = client.SoftDeleteRecord(ctx, session, consts.CollectionForms, record.ID)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintPrimaryOutput(recordID)
+	return nil
+}
@@ -152,6 +152,12 @@ func (a *CLIArgumentsHelp) Execute(ctx *cli.FFSContext) error {
 		ctx.PrintPrimaryOutput("  83            (create-bookmarks): Parent record is not a folder")
 		ctx.PrintPrimaryOutput("  84            (create-bookmarks): The position in the parent would be out of bounds")
 		ctx.PrintPrimaryOutput("  85            (update-bookmarks): One of the specified fields is not valid on the record type")
+		ctx.PrintPrimaryOutput("  86            (move-bookmarks): The bookmarks were modified by another client during the operation")
+		ctx.PrintPrimaryOutput("  87            (move-bookmarks): A folder cannot be moved into itself or one of its subfolders")
+		ctx.PrintPrimaryOutput("  88            (check-bookmarks): The bookmark tree contains problems")
+		ctx.PrintPrimaryOutput("  89            (bookmarks): The bookmark path matches multiple entries")
+		ctx.PrintPrimaryOutput("  90            (mirror-bookmarks): The mirror contains unresolved conflicts")
+		ctx.PrintPrimaryOutput("  91            (passwords): The query matches multiple passwords")
 		ctx.PrintPrimaryOutput("")
 		return fferr.NewEmpty(a.ExitCode)
 
@@ -182,10 +188,15 @@ func (a *CLIArgumentsHelp) globalOptions() [][]string { //TODO use yyyy-MM-dd sy
 		{"", "- 'text'"},
 		{"", "- 'json'"},
 		{"", "- 'netscape'   (default firefox bookmarks format)"},
+		{"", "- 'firefox-json' (firefox bookmark backup format)"},
 		{"", "- 'xml'"},
 		{"", "- 'table'"},
 		{"", "- 'csv'"},
 		{"", "- 'tsv'"},
+		{"", "- 'markdown'"},
+		{"", "- 'opml'"},
+		{"", "- 'org'        (emacs org-mode)"},
+		{"", "- 'sqlite'     (binary database file, use with --output)"},
 
 		{"--table-truncate", "Truncate columns of table-format to fit terminal width (needs -f table)"},
 		{"--no-table-truncate", "Disable truncation of columns in table-format output"},
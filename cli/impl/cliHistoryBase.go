@@ -1,12 +1,18 @@
 package impl
 
 import (
+	"encoding/json"
 	"ffsyncclient/cli"
 	"ffsyncclient/consts"
 	"ffsyncclient/fferr"
+	"ffsyncclient/historyfilter"
 	"ffsyncclient/models"
+	"ffsyncclient/syncclient"
 	"fmt"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"regexp"
+	"time"
 )
 
 type CLIArgumentsHistoryBase struct {
@@ -35,8 +41,8 @@ func (a *CLIArgumentsHistoryBase) ShortHelp() [][]string {
 
 func (a *CLIArgumentsHistoryBase) FullHelp() []string {
 	r := []string{
-		"$> ffsclient history (list|delete|create|update)",
-		"======================================================",
+		"$> ffsclient history (list|search|stats|visits|import|delete|purge)",
+		"====================================================================",
 		"",
 		"",
 	}
@@ -59,6 +65,9 @@ func (a *CLIArgumentsHistoryBase) Execute(ctx *cli.FFSContext) error {
 }
 
 type CLIArgumentsHistoryUtil struct {
+	VisitedAfter  *string // parsed into the filter by resolveHistoryFilterArgs
+	VisitedBefore *string // parsed into the filter by resolveHistoryFilterArgs
+
 	CLIArgumentsBaseUtil
 }
 
@@ -85,3 +94,238 @@ func (a *CLIArgumentsHistoryUtil) filterDeleted(ctx *cli.FFSContext, records []m
 func (a *CLIArgumentsHistoryUtil) newHistoryID() string {
 	return langext.RandBase62(12)
 }
+
+// historyFilterShortHelp returns the help lines of the filter options (see parseHistoryFilterArg)
+func (a *CLIArgumentsHistoryUtil) historyFilterShortHelp() [][]string {
+	return [][]string{
+		{"          [--url-regex <regex>]", "Return only pages whose URL matches the (go) regular expression"},
+		{"          [--title-regex <regex>]", "Return only pages whose title matches the (go) regular expression"},
+		{"          [--domain <domain>]", "Return only pages on the domain (or its subdomains), can be specified multiple times"},
+		{"          [--visited-after <date>]", "Return only visits after this date (RFC3339 or YYYY-MM-DD)"},
+		{"          [--visited-before <date>]", "Return only visits before this date (RFC3339 or YYYY-MM-DD)"},
+		{"          [--transition <type>]", "Return only visits with the transition type (LINK, TYPED, BOOKMARK, ...), can be specified multiple times"},
+	}
+}
+
+// parseHistoryFilterArg parses the filter options of the history commands into the filter, it returns false if the argument is not a filter option
+func (a *CLIArgumentsHistoryUtil) parseHistoryFilterArg(filter *historyfilter.Filter, arg cli.ArgumentTuple) (bool, error) {
+	if arg.Value == nil {
+		return false, nil
+	}
+
+	switch arg.Key {
+
+	case "url-regex", "title-regex":
+		rex, err := regexp.Compile(*arg.Value)
+		if err != nil {
+			return true, fferr.DirectOutput.New(fmt.Sprintf("Failed to parse regular expression argument '--%s': %s", arg.Key, err.Error()))
+		}
+		if arg.Key == "url-regex" {
+			filter.URLRegex = rex
+		} else {
+			filter.TitleRegex = rex
+		}
+		return true, nil
+
+	case "domain":
+		filter.Domains = append(filter.Domains, *arg.Value)
+		return true, nil
+
+	case "visited-after", "visited-before":
+		if _, err := parseDateArg(*arg.Value, time.UTC); err != nil {
+			return true, fferr.DirectOutput.New("Failed to decode time argument '" + arg.Key + "' (expected format: RFC3339 or YYYY-MM-DD)")
+		}
+		if arg.Key == "visited-after" {
+			a.VisitedAfter = langext.Ptr(*arg.Value)
+		} else {
+			a.VisitedBefore = langext.Ptr(*arg.Value)
+		}
+		return true, nil
+
+	case "transition":
+		t, ok := models.ParseHistoryTransitionType(*arg.Value)
+		if !ok {
+			return true, fferr.DirectOutput.New(fmt.Sprintf("Unknown transition type '%s' (possible values: LINK, TYPED, BOOKMARK, EMBED, REDIRECT_PERMANENT, REDIRECT_TEMPORARY, DOWNLOAD, FRAMED_LINK, RELOAD)", *arg.Value))
+		}
+		filter.Transitions = append(filter.Transitions, t)
+		return true, nil
+
+	default:
+		return false, nil
+	}
+}
+
+// resolveHistoryFilterArgs parses the date options into the filter, days are interpreted in the --timezone of the context
+func (a *CLIArgumentsHistoryUtil) resolveHistoryFilterArgs(ctx *cli.FFSContext, filter *historyfilter.Filter) error {
+	if a.VisitedAfter != nil {
+		t, err := parseDateArg(*a.VisitedAfter, ctx.Opt.TimeZone)
+		if err != nil {
+			return fferr.DirectOutput.New("Failed to decode time argument 'visited-after' (expected format: RFC3339 or YYYY-MM-DD)")
+		}
+		filter.VisitedAfter = langext.Ptr(t)
+	}
+	if a.VisitedBefore != nil {
+		t, err := parseDateArg(*a.VisitedBefore, ctx.Opt.TimeZone)
+		if err != nil {
+			return fferr.DirectOutput.New("Failed to decode time argument 'visited-before' (expected format: RFC3339 or YYYY-MM-DD)")
+		}
+		filter.VisitedBefore = langext.Ptr(t)
+	}
+	return nil
+}
+
+// queryHistoryEntries returns all (not deleted) history records that match the filter, with only the matching visits
+func (a *CLIArgumentsHistoryUtil) queryHistoryEntries(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, filter historyfilter.Filter, ignoreSchemaErrors bool) ([]models.HistoryRecord, error) {
+	records, err := client.ListRecords(ctx, session, consts.CollectionHistory, nil, nil, false, true, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := models.UnmarshalHistories(ctx, records, ignoreSchemaErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.HistoryRecord, 0, len(entries))
+	for _, v := range entries {
+		if m, ok := filter.Apply(v); ok {
+			result = append(result, m)
+		} else {
+			ctx.PrintVerbose(fmt.Sprintf("Skip entry %v (does not match the filter)", v.ID))
+		}
+	}
+
+	return result, nil
+}
+
+// historyPayload converts the record to the json payload of the history collection (visit dates are microseconds)
+func (a *CLIArgumentsHistoryUtil) historyPayload(v models.HistoryRecord) ([]byte, error) {
+	visits := make([]models.HistoryVisitSchema, 0, len(v.Visits))
+	for _, visit := range v.Visits {
+		visits = append(visits, models.HistoryVisitSchema{
+			TransitionType: visit.TransitionType,
+			VisitDate:      visit.VisitDate.UnixMicro(), // UnixMicro, not UnixMilli (!)
+		})
+	}
+
+	data, err := json.Marshal(models.HistoryPayloadSchema{
+		ID:     v.ID,
+		URI:    v.URI,
+		Title:  v.Title,
+		Visits: visits,
+	})
+	if err != nil {
+		return nil, errorx.Decorate(err, "failed to marshal BSO json")
+	}
+
+	return data, nil
+}
+
+// postHistoryRecords creates or replaces the records in the history collection, in batches of POST /storage/history
+func (a *CLIArgumentsHistoryUtil) postHistoryRecords(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, records []models.HistoryRecord) error {
+	updates := make([]models.RecordUpdate, 0, len(records))
+	for _, v := range records {
+		plainPayload, err := a.historyPayload(v)
+		if err != nil {
+			return err
+		}
+		payload, err := client.EncryptPayload(ctx, session, consts.CollectionHistory, string(plainPayload))
+		if err != nil {
+			return err
+		}
+		updates = append(updates, models.RecordUpdate{ID: v.ID, Payload: langext.Ptr(payload)})
+	}
+
+	_, err := client.PostRecordsBatched(ctx, session, consts.CollectionHistory, updates, nil)
+	return err
+}
+
+// printHistoryEntries prints the records in the output-format (the deleted column is only shown if includeDeleted && !onlyDeleted)
+func (a *CLIArgumentsHistoryUtil) printHistoryEntries(ctx *cli.FFSContext, entries []models.HistoryRecord, includeDeleted bool, onlyDeleted bool) error {
+	ofmt := langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable)
+	switch ofmt {
+
+	case cli.OutputFormatTable:
+		table := make([][]string, 0, len(entries))
+		table = append(table, []string{"ID", "DELETED", "URI", "TITLE", "VISITS", "LAST VISIT", "FIRST VISIT"})
+		for _, v := range entries {
+			table = append(table, []string{
+				v.ID,
+				langext.FormatBool(v.Deleted, "true", "false"),
+				v.URI,
+				v.Title,
+				fmt.Sprintf("%d", len(v.Visits)),
+				v.LastVisitStr(ctx),
+				v.FirstVisitStr(ctx),
+			})
+		}
+
+		if includeDeleted && !onlyDeleted {
+			ctx.PrintPrimaryOutputTableExt(table, []int{0, 1, 2, 3, 4, 5, 6})
+		} else {
+			ctx.PrintPrimaryOutputTableExt(table, []int{0, 2, 3, 4, 5, 6})
+		}
+
+		return nil
+
+	case cli.OutputFormatText:
+		for _, v := range entries {
+			ctx.PrintPrimaryOutput("ID:          " + v.ID)
+			if v.Deleted {
+				ctx.PrintPrimaryOutput("Deleted:     true")
+			}
+			ctx.PrintPrimaryOutput("Uri:         " + v.URI)
+			ctx.PrintPrimaryOutput("Title:       " + v.Title)
+			ctx.PrintPrimaryOutput(fmt.Sprintf("Visits (%d):", len(v.Visits)))
+			for _, visit := range v.Visits {
+				ctx.PrintPrimaryOutput(fmt.Sprintf("  - %s (%s)", visit.VisitDate.Format(ctx.Opt.TimeFormat), visit.TransitionType.ConstantString()))
+			}
+			ctx.PrintPrimaryOutput("")
+		}
+		return nil
+
+	case cli.OutputFormatJson:
+		json := langext.A{}
+		for _, v := range entries {
+			json = append(json, v.ToJSON(ctx))
+		}
+		ctx.PrintPrimaryOutputJSON(json)
+		return nil
+
+	case cli.OutputFormatXML:
+		type xmlroot struct {
+			Entries []any
+			XMLName struct{} `xml:"History"`
+		}
+		node := xmlroot{Entries: make([]any, 0, len(entries))}
+		for _, v := range entries {
+			node.Entries = append(node.Entries, v.ToSingleXML(ctx, includeDeleted))
+		}
+		ctx.PrintPrimaryOutputXML(node)
+		return nil
+
+	case cli.OutputFormatTSV:
+		fallthrough
+	case cli.OutputFormatCSV:
+		table := make([][]string, 0, len(entries))
+		table = append(table, []string{"ID", "Deleted", "URI", "Title", "Visits", "LastVisit", "FirstVisit"})
+		for _, v := range entries {
+			table = append(table, []string{
+				v.ID,
+				langext.FormatBool(v.Deleted, "true", "false"),
+				v.URI,
+				v.Title,
+				fmt.Sprintf("%d", len(v.Visits)),
+				v.LastVisitStr(ctx),
+				v.FirstVisitStr(ctx),
+			})
+		}
+
+		ctx.PrintPrimaryOutputCSV(table, ofmt == cli.OutputFormatTSV)
+
+		return nil
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
@@ -0,0 +1,223 @@
+package impl
+
+import (
+	"ffsyncclient/browserprofile"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+)
+
+type CLIArgumentsHistoryImport struct {
+	ProfileDir         string
+	DryRun             bool
+	IgnoreSchemaErrors bool
+
+	CLIArgumentsHistoryUtil
+}
+
+func NewCLIArgumentsHistoryImport() *CLIArgumentsHistoryImport {
+	return &CLIArgumentsHistoryImport{
+		ProfileDir:         "",
+		DryRun:             false,
+		IgnoreSchemaErrors: false,
+	}
+}
+
+func (a *CLIArgumentsHistoryImport) Mode() cli.Mode {
+	return cli.ModeHistoryImport
+}
+
+func (a *CLIArgumentsHistoryImport) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsHistoryImport) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsHistoryImport) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient history import --from-profile <dir>", "Import the history of a local firefox or chromium profile"},
+		{"          [--dry-run]", "Only count the new entries and visits, do not change anything"},
+		{"          [--ignore-schema-errors]", "Skip records that cannot be decoded into a history schema"},
+	}
+}
+
+func (a *CLIArgumentsHistoryImport) FullHelp() []string {
+	return []string{
+		"$> ffsclient history import --from-profile <dir> [--dry-run] [--ignore-schema-errors]",
+		"",
+		"Import the history directly from a local browser profile directory:",
+		"  * firefox: the places.sqlite database (e.g. ~/.mozilla/firefox/<profile>/)",
+		"  * chromium (chrome, edge, brave, etc): the History database (e.g. ~/.config/chromium/Default/)",
+		"The browser should be closed, otherwise the newest changes may not yet be written to disk.",
+		"",
+		fmt.Sprintf("Only http, https and ftp pages are imported, with (like firefox does) their %d newest visits.", browserprofile.MaxVisits),
+		"Pages that already exist in the synced history (same URL) are merged: the new visits are added, visits that already exist are skipped.",
+		"This makes it safe to import the same profile multiple times.",
+		"",
+		"If --dry-run is specified only the number of new entries and visits is printed and nothing is changed.",
+		"If --ignore-schema-errors is not supplied the programm returns with exitcode [60] if any record in the history collection has invalid data. Otherwise we simply skip that record.",
+	}
+}
+
+func (a *CLIArgumentsHistoryImport) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if arg.Key == "from-profile" && arg.Value != nil {
+			a.ProfileDir = *arg.Value
+			continue
+		}
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		if arg.Key == "ignore-schema-errors" && arg.Value == nil {
+			a.IgnoreSchemaErrors = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if a.ProfileDir == "" {
+		return fferr.DirectOutput.New("Missing required argument: --from-profile")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsHistoryImport) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Import History]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("ProfileDir", a.ProfileDir)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Read profile")
+
+	local, err := browserprofile.ReadHistory(a.ProfileDir)
+	if err != nil {
+		return fferr.WrapDirectOutput(err, consts.ExitcodeError, "Failed to read history from profile '"+a.ProfileDir+"'")
+	}
+
+	ctx.PrintVerboseKV("Local entries", len(local))
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Query history")
+
+	records, err := client.ListRecords(ctx, session, consts.CollectionHistory, nil, nil, false, true, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	remote, err := models.UnmarshalHistories(ctx, records, a.IgnoreSchemaErrors)
+	if err != nil {
+		return err
+	}
+
+	usedIDs := make(map[string]bool, len(remote))
+	byURI := make(map[string]models.HistoryRecord, len(remote))
+	for _, v := range remote {
+		usedIDs[v.ID] = true
+		if !v.Deleted {
+			byURI[v.URI] = v
+		}
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[3] Merge entries")
+
+	changed := make([]models.HistoryRecord, 0)
+	created := 0
+	updated := 0
+	newVisits := 0
+
+	for _, v := range local {
+		if existing, ok := byURI[v.URI]; ok {
+			merged := browserprofile.MergeVisits(existing.Visits, v.Visits)
+			added := a.countNewVisits(existing.Visits, merged)
+			if added == 0 {
+				ctx.PrintVerbose(fmt.Sprintf("Skip entry %v (%s) (no new visits)", existing.ID, v.URI))
+				continue
+			}
+
+			ctx.PrintVerbose(fmt.Sprintf("Update entry %v (%s) (%d new visits)", existing.ID, v.URI, added))
+			existing.Visits = merged
+			if existing.Title == "" {
+				existing.Title = v.Title
+			}
+			byURI[v.URI] = existing
+			changed = append(changed, existing)
+			updated++
+			newVisits += added
+			continue
+		}
+
+		id := v.ID
+		if id == "" || usedIDs[id] {
+			id = a.newHistoryID()
+		}
+		usedIDs[id] = true
+
+		ctx.PrintVerbose(fmt.Sprintf("Create entry %v (%s) (%d visits)", id, v.URI, len(v.Visits)))
+		v.ID = id
+		byURI[v.URI] = v
+		changed = append(changed, v)
+		created++
+		newVisits += len(v.Visits)
+	}
+
+	if len(changed) == 0 {
+		ctx.PrintPrimaryOutput("No new history entries or visits found.")
+		return nil
+	}
+
+	if a.DryRun {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Would create %d and update %d history entries (with %d new visits), no changes were made (--dry-run).", created, updated, newVisits))
+		return nil
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[4] Upload entries")
+
+	err = a.postHistoryRecords(ctx, client, session, changed)
+	if err != nil {
+		return err
+	}
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Created %d and updated %d history entries (with %d new visits).", created, updated, newVisits))
+	return nil
+}
+
+// countNewVisits returns the number of visits in merged that are not in existing
+func (a *CLIArgumentsHistoryImport) countNewVisits(existing []models.HistoryVisit, merged []models.HistoryVisit) int {
+	known := make(map[string]bool, len(existing))
+	for _, v := range existing {
+		known[fmt.Sprintf("%d|%d", v.VisitDate.UnixMicro(), v.TransitionType)] = true
+	}
+
+	r := 0
+	for _, v := range merged {
+		if !known[fmt.Sprintf("%d|%d", v.VisitDate.UnixMicro(), v.TransitionType)] {
+			r++
+		}
+	}
+	return r
+}
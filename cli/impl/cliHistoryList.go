@@ -161,90 +161,5 @@ func (a *CLIArgumentsHistoryList) Execute(ctx *cli.FFSContext) error {
 func (a *CLIArgumentsHistoryList) printOutput(ctx *cli.FFSContext, entries []models.HistoryRecord) error {
 	entries = a.filterDeleted(ctx, entries, a.IncludeDeleted, a.OnlyDeleted)
 
-	ofmt := langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable)
-	switch ofmt {
-
-	case cli.OutputFormatTable:
-		table := make([][]string, 0, len(entries))
-		table = append(table, []string{"ID", "DELETED", "URI", "TITLE", "VISITS", "LAST VISIT", "FIRST VISIT"})
-		for _, v := range entries {
-			table = append(table, []string{
-				v.ID,
-				langext.FormatBool(v.Deleted, "true", "false"),
-				v.URI,
-				v.Title,
-				fmt.Sprintf("%d", len(v.Visits)),
-				v.LastVisitStr(ctx),
-				v.FirstVisitStr(ctx),
-			})
-		}
-
-		if a.IncludeDeleted && !a.OnlyDeleted {
-			ctx.PrintPrimaryOutputTableExt(table, []int{0, 1, 2, 3, 4, 5, 6})
-		} else {
-			ctx.PrintPrimaryOutputTableExt(table, []int{0, 2, 3, 4, 5, 6})
-		}
-
-		return nil
-
-	case cli.OutputFormatText:
-		for _, v := range entries {
-			ctx.PrintPrimaryOutput("ID:          " + v.ID)
-			if v.Deleted {
-				ctx.PrintPrimaryOutput("Deleted:     true")
-			}
-			ctx.PrintPrimaryOutput("Uri:         " + v.URI)
-			ctx.PrintPrimaryOutput("Title:       " + v.Title)
-			ctx.PrintPrimaryOutput(fmt.Sprintf("Visits (%d):", len(v.Visits)))
-			for _, visit := range v.Visits {
-				ctx.PrintPrimaryOutput(fmt.Sprintf("  - %s (%s)", visit.VisitDate.Format(ctx.Opt.TimeFormat), visit.TransitionType.ConstantString()))
-			}
-			ctx.PrintPrimaryOutput("")
-		}
-		return nil
-
-	case cli.OutputFormatJson:
-		json := langext.A{}
-		for _, v := range entries {
-			json = append(json, v.ToJSON(ctx))
-		}
-		ctx.PrintPrimaryOutputJSON(json)
-		return nil
-
-	case cli.OutputFormatXML:
-		type xmlroot struct {
-			Entries []any
-			XMLName struct{} `xml:"History"`
-		}
-		node := xmlroot{Entries: make([]any, 0, len(entries))}
-		for _, v := range entries {
-			node.Entries = append(node.Entries, v.ToSingleXML(ctx, a.IncludeDeleted))
-		}
-		ctx.PrintPrimaryOutputXML(node)
-		return nil
-
-	case cli.OutputFormatTSV:
-		fallthrough
-	case cli.OutputFormatCSV:
-		table := make([][]string, 0, len(entries))
-		table = append(table, []string{"ID", "Deleted", "URI", "Title", "Visits", "LastVisit", "FirstVisit"})
-		for _, v := range entries {
-			table = append(table, []string{
-				v.ID,
-				langext.FormatBool(v.Deleted, "true", "false"),
-				v.URI,
-				v.Title,
-				fmt.Sprintf("%d", len(v.Visits)),
-				v.LastVisitStr(ctx),
-				v.FirstVisitStr(ctx),
-			})
-		}
-
-		ctx.PrintPrimaryOutputCSV(table, ofmt == cli.OutputFormatTSV)
-
-		return nil
-
-	default:
-		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
-	}
+	return a.printHistoryEntries(ctx, entries, a.IncludeDeleted, a.OnlyDeleted)
 }
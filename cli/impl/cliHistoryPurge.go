@@ -0,0 +1,195 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/historyfilter"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"regexp"
+	"time"
+)
+
+type CLIArgumentsHistoryPurge struct {
+	Filter             historyfilter.Filter
+	Before             *string
+	DryRun             bool
+	Yes                bool
+	IgnoreSchemaErrors bool
+
+	CLIArgumentsHistoryUtil
+}
+
+func NewCLIArgumentsHistoryPurge() *CLIArgumentsHistoryPurge {
+	return &CLIArgumentsHistoryPurge{
+		Filter:             historyfilter.Filter{},
+		Before:             nil,
+		DryRun:             false,
+		Yes:                false,
+		IgnoreSchemaErrors: false,
+	}
+}
+
+func (a *CLIArgumentsHistoryPurge) Mode() cli.Mode {
+	return cli.ModeHistoryPurge
+}
+
+func (a *CLIArgumentsHistoryPurge) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsHistoryPurge) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsHistoryPurge) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient history purge", "Delete all history entries of a domain, matching an URL pattern or older than a date"},
+		{"          [--domain <domain>]", "Delete the pages on the domain (or its subdomains), can be specified multiple times"},
+		{"          [--url-regex <regex>]", "Delete the pages whose URL matches the (go) regular expression"},
+		{"          [--before <date>]", "Delete the pages that were last visited before this date (RFC3339 or YYYY-MM-DD)"},
+		{"          [--dry-run]", "Only count the matching entries, do not change anything"},
+		{"          [--yes]", "Do not ask for confirmation"},
+		{"          [--ignore-schema-errors]", "Skip records that cannot be decoded into a history schema"},
+	}
+}
+
+func (a *CLIArgumentsHistoryPurge) FullHelp() []string {
+	return []string{
+		"$> ffsclient history purge [--domain <domain>] [--url-regex <regex>] [--before <date>] [--dry-run] [--yes] [--ignore-schema-errors]",
+		"",
+		"Delete all (not deleted) history entries that match all of the specified filters (at least one filter is required).",
+		"",
+		"With --domain the pages on the domain or one of its subdomains are deleted (`example.com` also matches `www.example.com`).",
+		"With --url-regex the pages whose URL matches the (go) regular expression are deleted.",
+		"With --before only pages whose visits are all before the date are deleted (pages with newer visits are kept completely).",
+		"Pages without any visits have no last visit date, they are never matched by --before (use --domain or --url-regex to delete them).",
+		"",
+		"The entries are replaced by tombstones ({id, deleted:true}) in batches, so that the other devices also delete them on their next sync.",
+		"If --dry-run is specified only the number of matching entries is printed and nothing is changed.",
+		"Before changing anything you are asked for confirmation, this can be skipped with --yes.",
+		"If --ignore-schema-errors is not supplied the programm returns with exitcode [60] if any record in the history collection has invalid data. Otherwise we simply skip that record.",
+	}
+}
+
+func (a *CLIArgumentsHistoryPurge) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if arg.Key == "domain" && arg.Value != nil {
+			a.Filter.Domains = append(a.Filter.Domains, *arg.Value)
+			continue
+		}
+		if arg.Key == "url-regex" && arg.Value != nil {
+			rex, err := regexp.Compile(*arg.Value)
+			if err != nil {
+				return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse regular expression argument '--%s': %s", arg.Key, err.Error()))
+			}
+			a.Filter.URLRegex = rex
+			continue
+		}
+		if arg.Key == "before" && arg.Value != nil {
+			if _, err := parseDateArg(*arg.Value, time.UTC); err != nil {
+				return fferr.DirectOutput.New("Failed to decode time argument '" + arg.Key + "' (expected format: RFC3339 or YYYY-MM-DD)")
+			}
+			a.Before = langext.Ptr(*arg.Value)
+			continue
+		}
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		if arg.Key == "yes" && arg.Value == nil {
+			a.Yes = true
+			continue
+		}
+		if arg.Key == "ignore-schema-errors" && arg.Value == nil {
+			a.IgnoreSchemaErrors = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	if len(a.Filter.Domains) == 0 && a.Filter.URLRegex == nil && a.Before == nil {
+		return fferr.DirectOutput.New("Missing required argument: --domain, --url-regex or --before")
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsHistoryPurge) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Purge History]")
+	ctx.PrintVerbose("")
+
+	if a.Before != nil {
+		t, err := parseDateArg(*a.Before, ctx.Opt.TimeZone)
+		if err != nil {
+			return fferr.DirectOutput.New("Failed to decode time argument 'before' (expected format: RFC3339 or YYYY-MM-DD)")
+		}
+		a.Filter.LastVisitBefore = langext.Ptr(t)
+	}
+
+	ctx.PrintVerboseKV("Domains", a.Filter.Domains)
+	ctx.PrintVerboseKV("Before", a.Filter.LastVisitBefore)
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	if langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) != cli.OutputFormatText {
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] Query history")
+
+	entries, err := a.queryHistoryEntries(ctx, client, session, a.Filter, a.IgnoreSchemaErrors)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		ctx.PrintPrimaryOutput("No matching history entries found.")
+		return nil
+	}
+
+	ids := make([]string, 0, len(entries))
+	visits := 0
+	for _, v := range entries {
+		ctx.PrintVerbose(fmt.Sprintf("Match entry %v (%s)", v.ID, v.URI))
+		ids = append(ids, v.ID)
+		visits += len(v.Visits)
+	}
+
+	if a.DryRun {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Found %d matching history entries (with %d visits), no changes were made (--dry-run).", len(ids), visits))
+		return nil
+	}
+
+	if !a.Yes {
+		ok, err := a.confirm(fmt.Sprintf("Delete %d history entries (with %d visits)?", len(ids), visits))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			ctx.PrintPrimaryOutput("Aborted.")
+			return nil
+		}
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Delete entries")
+
+	err = client.SoftDeleteRecords(ctx, session, consts.CollectionHistory, ids)
+	if err != nil {
+		return err
+	}
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Deleted %d history entries (with %d visits).", len(ids), visits))
+	return nil
+}
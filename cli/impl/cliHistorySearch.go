@@ -0,0 +1,146 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/fferr"
+	"ffsyncclient/historyfilter"
+	"ffsyncclient/models"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type CLIArgumentsHistorySearch struct {
+	Filter             historyfilter.Filter
+	IgnoreSchemaErrors bool
+	Limit              *int
+
+	CLIArgumentsHistoryUtil
+}
+
+func NewCLIArgumentsHistorySearch() *CLIArgumentsHistorySearch {
+	return &CLIArgumentsHistorySearch{
+		Filter:             historyfilter.Filter{},
+		IgnoreSchemaErrors: false,
+		Limit:              nil,
+	}
+}
+
+func (a *CLIArgumentsHistorySearch) Mode() cli.Mode {
+	return cli.ModeHistorySearch
+}
+
+func (a *CLIArgumentsHistorySearch) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsHistorySearch) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatText, cli.OutputFormatJson, cli.OutputFormatXML, cli.OutputFormatCSV, cli.OutputFormatTSV}
+}
+
+func (a *CLIArgumentsHistorySearch) ShortHelp() [][]string {
+	r := [][]string{
+		{"ffsclient history search", "Search history entries by URL, title, domain, visit date and transition type"},
+	}
+	r = append(r, a.historyFilterShortHelp()...)
+	r = append(r, [][]string{
+		{"          [--ignore-schema-errors]", "Skip records that cannot be decoded into a history schema"},
+		{"          [--limit <n>]", "Return max <n> elements"},
+	}...)
+	return r
+}
+
+func (a *CLIArgumentsHistorySearch) FullHelp() []string {
+	return []string{
+		"$> ffsclient history search [--url-regex <regex>] [--title-regex <regex>] [--domain <domain>] [--visited-after <date>] [--visited-before <date>] [--transition <type>] [--ignore-schema-errors] [--limit <n>]",
+		"",
+		"Search all (not deleted) history entries.",
+		"",
+		"With --url-regex and --title-regex the URL and title of the page must match the (go) regular expression.",
+		"With --domain only pages on the domain or one of its subdomains are returned (`example.com` also matches `www.example.com`).",
+		"With --visited-after, --visited-before and --transition only the matching visits of a page are returned, pages without matching visits are skipped.",
+		"The transition type is the name (e.g. TYPED) or the numeric value of the firefox transition constant.",
+		"--domain and --transition can be specified multiple times, an entry must match one of them.",
+		"",
+		"The results are sorted by their (last matching) visit, newest first.",
+		"If --ignore-schema-errors is not supplied the programm returns with exitcode [60] if any record in the history collection has invalid data. Otherwise we simply skip that record.",
+	}
+}
+
+func (a *CLIArgumentsHistorySearch) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if ok, err := a.parseHistoryFilterArg(&a.Filter, arg); err != nil {
+			return err
+		} else if ok {
+			continue
+		}
+		if arg.Key == "ignore-schema-errors" && arg.Value == nil {
+			a.IgnoreSchemaErrors = true
+			continue
+		}
+		if arg.Key == "limit" && arg.Value != nil {
+			if v, err := strconv.ParseInt(*arg.Value, 10, 32); err == nil && v > 0 {
+				a.Limit = langext.Ptr(int(v))
+				continue
+			}
+			return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse number argument '--%s': '%s'", arg.Key, *arg.Value))
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsHistorySearch) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Search History Entries]")
+	ctx.PrintVerbose("")
+
+	if err := a.resolveHistoryFilterArgs(ctx, &a.Filter); err != nil {
+		return err
+	}
+
+	ctx.PrintVerboseKV("Domains", a.Filter.Domains)
+	ctx.PrintVerboseKV("VisitedAfter", a.Filter.VisitedAfter)
+	ctx.PrintVerboseKV("VisitedBefore", a.Filter.VisitedBefore)
+	ctx.PrintVerboseKV("Transitions", a.Filter.Transitions)
+	ctx.PrintVerboseKV("Limit", a.Limit)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	entries, err := a.queryHistoryEntries(ctx, client, session, a.Filter, a.IgnoreSchemaErrors)
+	if err != nil {
+		return err
+	}
+
+	lastVisit := func(v models.HistoryRecord) time.Time {
+		r := time.Time{}
+		for _, visit := range v.Visits {
+			if visit.VisitDate.After(r) {
+				r = visit.VisitDate
+			}
+		}
+		return r
+	}
+
+	// entries without visits come last
+	sort.SliceStable(entries, func(i, j int) bool {
+		return lastVisit(entries[i]).After(lastVisit(entries[j]))
+	})
+
+	if a.Limit != nil && len(entries) > *a.Limit {
+		entries = entries[:*a.Limit]
+	}
+
+	// ========================================================================
+
+	return a.printHistoryEntries(ctx, entries, false, false)
+}
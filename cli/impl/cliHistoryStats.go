@@ -0,0 +1,152 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/historyfilter"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+)
+
+type CLIArgumentsHistoryStats struct {
+	Filter             historyfilter.Filter
+	IgnoreSchemaErrors bool
+
+	CLIArgumentsHistoryUtil
+}
+
+func NewCLIArgumentsHistoryStats() *CLIArgumentsHistoryStats {
+	return &CLIArgumentsHistoryStats{
+		Filter:             historyfilter.Filter{},
+		IgnoreSchemaErrors: false,
+	}
+}
+
+func (a *CLIArgumentsHistoryStats) Mode() cli.Mode {
+	return cli.ModeHistoryStats
+}
+
+func (a *CLIArgumentsHistoryStats) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsHistoryStats) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatJson}
+}
+
+func (a *CLIArgumentsHistoryStats) ShortHelp() [][]string {
+	r := [][]string{
+		{"ffsclient history stats", "Show the number of visits per domain, per day and per transition type"},
+	}
+	r = append(r, a.historyFilterShortHelp()...)
+	r = append(r, [][]string{
+		{"          [--ignore-schema-errors]", "Skip records that cannot be decoded into a history schema"},
+	}...)
+	return r
+}
+
+func (a *CLIArgumentsHistoryStats) FullHelp() []string {
+	return []string{
+		"$> ffsclient history stats [--url-regex <regex>] [--title-regex <regex>] [--domain <domain>] [--visited-after <date>] [--visited-before <date>] [--transition <type>] [--ignore-schema-errors]",
+		"",
+		"Aggregate the visits of all (not deleted) history entries per domain, per day and per transition type.",
+		"",
+		"The same filters as in `history search` can be used to only count specific pages or visits.",
+		"Days are calculated in the timezone of the --timezone option.",
+		"For every group the number of visits and the number of distinct pages (history entries) is shown.",
+		"If --ignore-schema-errors is not supplied the programm returns with exitcode [60] if any record in the history collection has invalid data. Otherwise we simply skip that record.",
+	}
+}
+
+func (a *CLIArgumentsHistoryStats) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if ok, err := a.parseHistoryFilterArg(&a.Filter, arg); err != nil {
+			return err
+		} else if ok {
+			continue
+		}
+		if arg.Key == "ignore-schema-errors" && arg.Value == nil {
+			a.IgnoreSchemaErrors = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsHistoryStats) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[History Statistics]")
+	ctx.PrintVerbose("")
+
+	if err := a.resolveHistoryFilterArgs(ctx, &a.Filter); err != nil {
+		return err
+	}
+
+	ctx.PrintVerboseKV("Domains", a.Filter.Domains)
+	ctx.PrintVerboseKV("VisitedAfter", a.Filter.VisitedAfter)
+	ctx.PrintVerboseKV("VisitedBefore", a.Filter.VisitedBefore)
+	ctx.PrintVerboseKV("Transitions", a.Filter.Transitions)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	entries, err := a.queryHistoryEntries(ctx, client, session, a.Filter, a.IgnoreSchemaErrors)
+	if err != nil {
+		return err
+	}
+
+	stats := historyfilter.Aggregate(entries, ctx.Opt.TimeZone)
+
+	// ========================================================================
+
+	switch langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable) {
+
+	case cli.OutputFormatTable:
+		ctx.PrintPrimaryOutput(fmt.Sprintf("%d visits on %d pages", stats.Visits, stats.Pages))
+		for _, grp := range []struct {
+			header string
+			counts []historyfilter.Count
+		}{
+			{"DOMAIN", stats.Domains},
+			{"DAY", stats.Days},
+			{"TRANSITION", stats.Transitions},
+		} {
+			ctx.PrintPrimaryOutput("")
+			table := make([][]string, 0, len(grp.counts)+1)
+			table = append(table, []string{grp.header, "VISITS", "PAGES"})
+			for _, v := range grp.counts {
+				table = append(table, []string{v.Key, fmt.Sprintf("%d", v.Visits), fmt.Sprintf("%d", v.Pages)})
+			}
+			ctx.PrintPrimaryOutputTable(table)
+		}
+		return nil
+
+	case cli.OutputFormatJson:
+		toJSON := func(counts []historyfilter.Count, key string) langext.A {
+			r := langext.A{}
+			for _, v := range counts {
+				r = append(r, langext.H{key: v.Key, "visits": v.Visits, "pages": v.Pages})
+			}
+			return r
+		}
+		ctx.PrintPrimaryOutputJSON(langext.H{
+			"visits":      stats.Visits,
+			"pages":       stats.Pages,
+			"domains":     toJSON(stats.Domains, "domain"),
+			"days":        toJSON(stats.Days, "day"),
+			"transitions": toJSON(stats.Transitions, "transition"),
+		})
+		return nil
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
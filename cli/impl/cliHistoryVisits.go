@@ -0,0 +1,270 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/historyfilter"
+	"ffsyncclient/models"
+	"ffsyncclient/sqlitefmt"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+type CLIArgumentsHistoryVisits struct {
+	Filter             historyfilter.Filter
+	IgnoreSchemaErrors bool
+	Limit              *int
+
+	CLIArgumentsHistoryUtil
+}
+
+type historyVisitRow struct {
+	Record models.HistoryRecord
+	Visit  models.HistoryVisit
+}
+
+func NewCLIArgumentsHistoryVisits() *CLIArgumentsHistoryVisits {
+	return &CLIArgumentsHistoryVisits{
+		Filter:             historyfilter.Filter{},
+		IgnoreSchemaErrors: false,
+		Limit:              nil,
+	}
+}
+
+func (a *CLIArgumentsHistoryVisits) Mode() cli.Mode {
+	return cli.ModeHistoryVisits
+}
+
+func (a *CLIArgumentsHistoryVisits) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsHistoryVisits) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatTable, cli.OutputFormatJson, cli.OutputFormatCSV, cli.OutputFormatTSV, cli.OutputFormatSQLite}
+}
+
+func (a *CLIArgumentsHistoryVisits) ShortHelp() [][]string {
+	r := [][]string{
+		{"ffsclient history visits", "List all visits of the history entries (one row per visit)"},
+	}
+	r = append(r, a.historyFilterShortHelp()...)
+	r = append(r, [][]string{
+		{"          [--ignore-schema-errors]", "Skip records that cannot be decoded into a history schema"},
+		{"          [--limit <n>]", "Return max <n> visits"},
+	}...)
+	return r
+}
+
+func (a *CLIArgumentsHistoryVisits) FullHelp() []string {
+	return []string{
+		"$> ffsclient history visits [--url-regex <regex>] [--title-regex <regex>] [--domain <domain>] [--visited-after <date>] [--visited-before <date>] [--transition <type>] [--ignore-schema-errors] [--limit <n>]",
+		"",
+		"List the visits of all (not deleted) history entries, with one row per visit (url, title, visit date and transition type).",
+		"The same filters as in `history search` can be used to only list specific pages or visits.",
+		"The visits are sorted by their date, newest first.",
+		"",
+		"With `--format sqlite` a database file is written (use --output <file>) with the tables `places` and `visits`.",
+		"They have the same structure as the `moz_places` and `moz_historyvisits` tables of firefox (visit_date and last_visit_date are microseconds since the unix epoch, visit_type is the numeric transition type).",
+		"",
+		"If --ignore-schema-errors is not supplied the programm returns with exitcode [60] if any record in the history collection has invalid data. Otherwise we simply skip that record.",
+	}
+}
+
+func (a *CLIArgumentsHistoryVisits) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if ok, err := a.parseHistoryFilterArg(&a.Filter, arg); err != nil {
+			return err
+		} else if ok {
+			continue
+		}
+		if arg.Key == "ignore-schema-errors" && arg.Value == nil {
+			a.IgnoreSchemaErrors = true
+			continue
+		}
+		if arg.Key == "limit" && arg.Value != nil {
+			if v, err := strconv.ParseInt(*arg.Value, 10, 32); err == nil && v > 0 {
+				a.Limit = langext.Ptr(int(v))
+				continue
+			}
+			return fferr.DirectOutput.New(fmt.Sprintf("Failed to parse number argument '--%s': '%s'", arg.Key, *arg.Value))
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsHistoryVisits) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[List History Visits]")
+	ctx.PrintVerbose("")
+
+	if err := a.resolveHistoryFilterArgs(ctx, &a.Filter); err != nil {
+		return err
+	}
+
+	ctx.PrintVerboseKV("Domains", a.Filter.Domains)
+	ctx.PrintVerboseKV("VisitedAfter", a.Filter.VisitedAfter)
+	ctx.PrintVerboseKV("VisitedBefore", a.Filter.VisitedBefore)
+	ctx.PrintVerboseKV("Transitions", a.Filter.Transitions)
+	ctx.PrintVerboseKV("Limit", a.Limit)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	entries, err := a.queryHistoryEntries(ctx, client, session, a.Filter, a.IgnoreSchemaErrors)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]historyVisitRow, 0)
+	for _, v := range entries {
+		for _, visit := range v.Visits {
+			rows = append(rows, historyVisitRow{Record: v, Visit: visit})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Visit.VisitDate.After(rows[j].Visit.VisitDate)
+	})
+
+	if a.Limit != nil && len(rows) > *a.Limit {
+		rows = rows[:*a.Limit]
+	}
+
+	// ========================================================================
+
+	ofmt := langext.Coalesce(ctx.Opt.Format, cli.OutputFormatTable)
+	switch ofmt {
+
+	case cli.OutputFormatTable:
+		table := make([][]string, 0, len(rows)+1)
+		table = append(table, []string{"ID", "URI", "TITLE", "VISIT DATE", "TRANSITION"})
+		for _, v := range rows {
+			table = append(table, []string{
+				v.Record.ID,
+				v.Record.URI,
+				v.Record.Title,
+				v.Visit.VisitDate.In(ctx.Opt.TimeZone).Format(ctx.Opt.TimeFormat),
+				v.Visit.TransitionType.ConstantString(),
+			})
+		}
+		ctx.PrintPrimaryOutputTable(table)
+		return nil
+
+	case cli.OutputFormatJson:
+		json := langext.A{}
+		for _, v := range rows {
+			json = append(json, langext.H{
+				"id":               v.Record.ID,
+				"uri":              v.Record.URI,
+				"title":            v.Record.Title,
+				"date":             v.Visit.VisitDate.In(ctx.Opt.TimeZone).Format(ctx.Opt.TimeFormat),
+				"date_unix":        v.Visit.VisitDate.Unix(),
+				"transition":       v.Visit.TransitionType.ConstantString(),
+				"transition_const": int(v.Visit.TransitionType),
+			})
+		}
+		ctx.PrintPrimaryOutputJSON(json)
+		return nil
+
+	case cli.OutputFormatTSV:
+		fallthrough
+	case cli.OutputFormatCSV:
+		table := make([][]string, 0, len(rows)+1)
+		table = append(table, []string{"ID", "URI", "Title", "VisitDate", "Transition"})
+		for _, v := range rows {
+			table = append(table, []string{
+				v.Record.ID,
+				v.Record.URI,
+				v.Record.Title,
+				v.Visit.VisitDate.In(ctx.Opt.TimeZone).Format(ctx.Opt.TimeFormat),
+				v.Visit.TransitionType.ConstantString(),
+			})
+		}
+		ctx.PrintPrimaryOutputCSV(table, ofmt == cli.OutputFormatTSV)
+		return nil
+
+	case cli.OutputFormatSQLite:
+		data, err := sqlitefmt.Write(a.historySQLiteTables(rows))
+		if err != nil {
+			return errorx.Decorate(err, "failed to create sqlite database")
+		}
+		ctx.PrintPrimaryOutputBinary(data)
+		return nil
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
+
+// historySQLiteTables converts the visits into the tables `places` and `visits` (a subset of the columns of moz_places and moz_historyvisits)
+func (a *CLIArgumentsHistoryVisits) historySQLiteTables(rows []historyVisitRow) []sqlitefmt.Table {
+	chronological := slices.Clone(rows)
+	sort.SliceStable(chronological, func(i, j int) bool {
+		return chronological[i].Visit.VisitDate.Before(chronological[j].Visit.VisitDate)
+	})
+
+	placeIDs := make(map[string]int64)
+	placeRows := make(map[string][]any)
+	places := sqlitefmt.Table{
+		Name: "places",
+		Columns: []sqlitefmt.Column{
+			{Name: "id", PrimaryKey: true},
+			{Name: "url", Type: "LONGVARCHAR"},
+			{Name: "title", Type: "LONGVARCHAR"},
+			{Name: "rev_host", Type: "LONGVARCHAR"},
+			{Name: "visit_count", Type: "INTEGER DEFAULT 0"},
+			{Name: "hidden", Type: "INTEGER DEFAULT 0 NOT NULL"},
+			{Name: "typed", Type: "INTEGER DEFAULT 0 NOT NULL"},
+			{Name: "last_visit_date", Type: "INTEGER"},
+			{Name: "guid", Type: "TEXT"},
+		},
+	}
+	visits := sqlitefmt.Table{
+		Name: "visits",
+		Columns: []sqlitefmt.Column{
+			{Name: "id", PrimaryKey: true},
+			{Name: "from_visit", Type: "INTEGER"},
+			{Name: "place_id", Type: "INTEGER"},
+			{Name: "visit_date", Type: "INTEGER"},
+			{Name: "visit_type", Type: "INTEGER"},
+			{Name: "session", Type: "INTEGER"},
+		},
+	}
+
+	for i, v := range chronological {
+		placeID, ok := placeIDs[v.Record.ID]
+		if !ok {
+			placeID = int64(len(placeIDs) + 1)
+			placeIDs[v.Record.ID] = placeID
+
+			host := []rune(historyfilter.Domain(v.Record.URI))
+			slices.Reverse(host)
+
+			placeRows[v.Record.ID] = []any{placeID, v.Record.URI, v.Record.Title, string(host) + ".", int64(0), int64(0), int64(0), nil, v.Record.ID}
+			places.Rows = append(places.Rows, placeRows[v.Record.ID])
+		}
+
+		row := placeRows[v.Record.ID]
+		row[4] = row[4].(int64) + 1
+		if v.Visit.TransitionType == models.HistoryTransitionTypeTyped {
+			row[6] = int64(1)
+		}
+		row[7] = v.Visit.VisitDate.UnixMicro()
+
+		visits.Rows = append(visits.Rows, []any{int64(i + 1), int64(0), placeID, v.Visit.VisitDate.UnixMicro(), int64(v.Visit.TransitionType), int64(0)})
+	}
+
+	return []sqlitefmt.Table{places, visits}
+}
@@ -4,13 +4,17 @@ import (
 	"ffsyncclient/cli"
 	"ffsyncclient/consts"
 	"ffsyncclient/fferr"
+	"ffsyncclient/loginmatch"
 	"ffsyncclient/models"
+	"ffsyncclient/passwordgen"
 	"ffsyncclient/syncclient"
 	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
 	"github.com/google/uuid"
 	"github.com/joomcode/errorx"
 	"net/url"
 	"strings"
+	"strconv"
 	"time"
 )
 
@@ -80,7 +84,122 @@ type CLIArgumentsPasswordsUtil struct {
 	CLIArgumentsBaseUtil
 }
 
+type passwordMatch struct {
+	Record      models.Record
+	Password    models.PasswordRecord
+	Specificity loginmatch.Specificity
+}
+
+// findPasswordRecord returns the single record that matches the query (for the commands that modify or use the record)
+// Unlike findPasswordRecords (passwords get) the query must be the record-id, the exact hostname or an URI with the same host,
+// logins on subdomains or the base-domain are never matched. Only the most specific matches are used (an exact origin match hides logins
+// with another scheme or port). If multiple records match an ExitcodePasswordAmbiguous error is returned.
 func (a *CLIArgumentsPasswordsUtil) findPasswordRecord(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, query string, queryIsID bool, queryIsHost bool, queryIsExactHost bool) (models.Record, models.PasswordRecord, bool, error) {
+	if queryIsID {
+		matches, err := a.findPasswordRecords(ctx, client, session, query, true, false, false, nil, nil)
+		if err != nil {
+			return models.Record{}, models.PasswordRecord{}, false, err
+		}
+		if len(matches) == 0 {
+			return models.Record{}, models.PasswordRecord{}, false, nil
+		}
+		return matches[0].Record, matches[0].Password, true, nil
+	}
+
+	candidates, err := a.listPasswordCandidates(ctx, client, session)
+	if err != nil {
+		return models.Record{}, models.PasswordRecord{}, false, err
+	}
+
+	match, found, err := a.resolvePasswordRecord(ctx, candidates, query, false, queryIsHost, queryIsExactHost)
+	if err != nil || !found {
+		return models.Record{}, models.PasswordRecord{}, false, err
+	}
+
+	return match.Record, match.Password, true, nil
+}
+
+// resolvePasswordRecord searches the candidates for the single record that matches the query (see findPasswordRecord)
+func (a *CLIArgumentsPasswordsUtil) resolvePasswordRecord(ctx *cli.FFSContext, candidates []passwordMatch, query string, queryIsID bool, queryIsHost bool, queryIsExactHost bool) (passwordMatch, bool, error) {
+	matches := make([]passwordMatch, 0)
+
+	if !queryIsHost && !queryIsExactHost {
+		for _, v := range candidates {
+			if v.Password.ID == query {
+				return v, true, nil
+			}
+		}
+		if queryIsID {
+			return passwordMatch{}, false, nil
+		}
+	}
+
+	if !queryIsHost {
+		for _, v := range candidates {
+			if v.Password.Hostname == query {
+				matches = append(matches, v)
+			}
+		}
+	}
+
+	if len(matches) == 0 && !queryIsExactHost {
+		origin, err := loginmatch.ParseOrigin(query)
+		if err != nil && queryIsHost {
+			return passwordMatch{}, false, fferr.WrapDirectOutput(err, consts.ExitcodeError, "cannot parse supplied argument as an URI")
+		} else if err != nil {
+			ctx.PrintVerbose("Failed to parse query as an URI: " + err.Error())
+		} else {
+			ctx.PrintVerbose("Parsed query to origin: '" + origin.String() + "'")
+			ranked := a.rankPasswordRecords(ctx, candidates, origin, nil, nil)
+			results := make([]loginmatch.Result, 0, len(ranked))
+			for i, v := range ranked {
+				results = append(results, loginmatch.Result{Index: i, Specificity: v.Specificity})
+			}
+			for _, r := range loginmatch.Best(results, loginmatch.MatchHost) {
+				matches = append(matches, ranked[r.Index])
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return passwordMatch{}, false, nil
+	}
+
+	if len(matches) > 1 {
+		ids := make([]string, 0, len(matches))
+		for _, v := range matches {
+			ids = append(ids, v.Password.ID+" ("+v.Password.Hostname+")")
+		}
+		return passwordMatch{}, false, fferr.NewDirectOutput(consts.ExitcodePasswordAmbiguous, fmt.Sprintf("The query '%s' matches %d passwords, use the record-id (with --is-id) to select one: %s", query, len(matches), strings.Join(ids, ", ")))
+	}
+
+	return matches[0], true, nil
+}
+
+// listPasswordCandidates returns all (not deleted) password records
+func (a *CLIArgumentsPasswordsUtil) listPasswordCandidates(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession) ([]passwordMatch, error) {
+	records, err := client.ListRecords(ctx, session, consts.CollectionPasswords, nil, nil, false, true, nil, nil)
+	if err != nil {
+		return nil, errorx.Decorate(err, "failed to list passwords")
+	}
+
+	candidates := make([]passwordMatch, 0, len(records))
+	for _, rec := range records {
+		v, err := models.UnmarshalPassword(ctx, rec)
+		if err != nil {
+			continue
+		}
+		if v.Deleted {
+			continue
+		}
+		candidates = append(candidates, passwordMatch{Record: rec, Password: v})
+	}
+
+	return candidates, nil
+}
+
+// findPasswordRecords returns all matching records, the best match first
+func (a *CLIArgumentsPasswordsUtil) findPasswordRecords(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, query string, queryIsID bool, queryIsHost bool, queryIsExactHost bool, formSubmitURL *string, httpRealm *string) ([]passwordMatch, error) {
 
 	// #### VARIANT 1: <QueryIsID>
 
@@ -89,31 +208,23 @@ func (a *CLIArgumentsPasswordsUtil) findPasswordRecord(ctx *cli.FFSContext, clie
 
 		record, err := client.GetRecord(ctx, session, consts.CollectionPasswords, query, true)
 		if err != nil && errorx.IsOfType(err, fferr.Request404) {
-			return models.Record{}, models.PasswordRecord{}, false, nil
+			return []passwordMatch{}, nil
 		}
 		if err != nil {
-			return models.Record{}, models.PasswordRecord{}, false, errorx.Decorate(err, "failed to query record")
+			return nil, errorx.Decorate(err, "failed to query record")
 		}
 
 		pwrec, err := models.UnmarshalPassword(ctx, record)
 		if err != nil {
-			return models.Record{}, models.PasswordRecord{}, false, errorx.Decorate(err, "failed to decode password-record")
+			return nil, errorx.Decorate(err, "failed to decode password-record")
 		}
 
-		return record, pwrec, true, nil
+		return []passwordMatch{{Record: record, Password: pwrec, Specificity: loginmatch.MatchExact}}, nil
 	}
 
-	records, err := client.ListRecords(ctx, session, consts.CollectionPasswords, nil, nil, false, true, nil, nil)
+	candidates, err := a.listPasswordCandidates(ctx, client, session)
 	if err != nil {
-		return models.Record{}, models.PasswordRecord{}, false, errorx.Decorate(err, "failed to list passwords")
-	}
-
-	var parsedURI *url.URL
-
-	if u, err := a.extUrlParse(query); err == nil {
-		ctx.PrintVerbose("Parsed query to uri: '" + u.Host + "'")
-
-		parsedURI = u
+		return nil, err
 	}
 
 	// #### VARIANT 2: <QueryIsHost>
@@ -121,22 +232,14 @@ func (a *CLIArgumentsPasswordsUtil) findPasswordRecord(ctx *cli.FFSContext, clie
 	if queryIsHost {
 		ctx.PrintVerbose("Search for record by URI")
 
-		if parsedURI == nil {
-			return models.Record{}, models.PasswordRecord{}, false, errorx.Decorate(err, "cannot parse supplied argument as an URI")
+		origin, err := loginmatch.ParseOrigin(query)
+		if err != nil {
+			return nil, fferr.WrapDirectOutput(err, consts.ExitcodeError, "cannot parse supplied argument as an URI")
 		}
 
-		for _, rec := range records {
-			v, err := models.UnmarshalPassword(ctx, rec)
-			if err != nil {
-				continue
-			}
-			if recordURI, err := url.Parse(v.Hostname); err == nil {
-				if strings.ToLower(recordURI.Host) == strings.ToLower(parsedURI.Host) {
-					return rec, v, true, nil
-				}
-			}
-		}
-		return models.Record{}, models.PasswordRecord{}, false, nil
+		ctx.PrintVerbose("Parsed query to origin: '" + origin.String() + "'")
+
+		return a.rankPasswordRecords(ctx, candidates, origin, formSubmitURL, httpRealm), nil
 	}
 
 	// #### VARIANT 3: <QueryIsExactHost>
@@ -144,16 +247,13 @@ func (a *CLIArgumentsPasswordsUtil) findPasswordRecord(ctx *cli.FFSContext, clie
 	if queryIsExactHost {
 		ctx.PrintVerbose("Search for record by exact Hostname")
 
-		for _, rec := range records {
-			v, err := models.UnmarshalPassword(ctx, rec)
-			if err != nil {
-				continue
-			}
-			if v.Hostname == query {
-				return rec, v, true, nil
+		result := make([]passwordMatch, 0)
+		for _, v := range candidates {
+			if v.Password.Hostname == query {
+				result = append(result, passwordMatch{Record: v.Record, Password: v.Password, Specificity: loginmatch.MatchExact})
 			}
 		}
-		return models.Record{}, models.PasswordRecord{}, false, nil
+		return result, nil
 	}
 
 	// #### VARIANT 4: <GUESS>
@@ -161,31 +261,53 @@ func (a *CLIArgumentsPasswordsUtil) findPasswordRecord(ctx *cli.FFSContext, clie
 	{
 		ctx.PrintVerbose("Search for record (guess query type)")
 
-		for _, rec := range records {
-			v, err := models.UnmarshalPassword(ctx, rec)
-			if err != nil {
-				continue
+		for _, v := range candidates {
+			if v.Password.ID == query {
+				return []passwordMatch{{Record: v.Record, Password: v.Password, Specificity: loginmatch.MatchExact}}, nil
 			}
+		}
 
-			if v.ID == query {
-				return rec, v, true, nil
-			}
-			if v.Hostname == query {
-				return rec, v, true, nil
-			}
-			if parsedURI != nil {
-				if recordURI, err := url.Parse(v.Hostname); err == nil {
-					if strings.ToLower(recordURI.Host) == strings.ToLower(parsedURI.Host) {
-						return rec, v, true, nil
-					}
-				}
-			}
+		origin, err := loginmatch.ParseOrigin(query)
+		if err != nil {
+			ctx.PrintVerbose("Failed to parse query as an URI: " + err.Error())
+			return []passwordMatch{}, nil
 		}
-		return models.Record{}, models.PasswordRecord{}, false, nil
+
+		ctx.PrintVerbose("Parsed query to origin: '" + origin.String() + "'")
+
+		return a.rankPasswordRecords(ctx, candidates, origin, formSubmitURL, httpRealm), nil
 	}
 
 }
 
+func (a *CLIArgumentsPasswordsUtil) rankPasswordRecords(ctx *cli.FFSContext, candidates []passwordMatch, origin loginmatch.Origin, formSubmitURL *string, httpRealm *string) []passwordMatch {
+	logins := make([]loginmatch.Login, 0, len(candidates))
+	for _, v := range candidates {
+		lastUsed := langext.Coalesce(v.Password.LastUsed, langext.Coalesce(v.Password.PasswordChanged, langext.Coalesce(v.Password.Created, time.Time{})))
+		logins = append(logins, loginmatch.Login{
+			Hostname:      v.Password.Hostname,
+			FormSubmitURL: v.Password.FormSubmitURL,
+			HTTPRealm:     v.Password.HTTPRealm,
+			LastUsed:      lastUsed,
+		})
+	}
+
+	query := loginmatch.Query{
+		Origin:        origin,
+		FormSubmitURL: formSubmitURL,
+		HTTPRealm:     httpRealm,
+	}
+
+	result := make([]passwordMatch, 0)
+	for _, r := range loginmatch.Rank(query, logins) {
+		v := candidates[r.Index]
+		ctx.PrintVerbose(fmt.Sprintf("Record %s (%s) matches with specificity %d", v.Password.ID, v.Password.Hostname, r.Specificity))
+		result = append(result, passwordMatch{Record: v.Record, Password: v.Password, Specificity: r.Specificity})
+	}
+
+	return result
+}
+
 func (a *CLIArgumentsPasswordsUtil) extUrlParse(v string) (*url.URL, error) {
 	if !urlSchemaRegex.MatchString(v) {
 		v = "generic://" + v
@@ -217,3 +339,129 @@ func (a *CLIArgumentsPasswordsUtil) filterDeleted(ctx *cli.FFSContext, records [
 func (a *CLIArgumentsPasswordsUtil) newPasswordID() string {
 	return "{" + uuid.New().String() + "}"
 }
+
+type passwordGeneratorArgs struct {
+	Generate      bool
+	Length        *int
+	Charset       *string
+	NoSymbols     bool
+	Preset        *string
+	Pronounceable bool
+	Passphrase    bool
+	Wordlist      *string
+}
+
+func (g *passwordGeneratorArgs) shortHelp() [][]string {
+	return [][]string{
+		{"          [--generate [<length>]]", "Generate a random password (printed once)"},
+		{"          [--charset <chars>]", "Generate the password from this set of characters"},
+		{"          [--no-symbols]", "Generate a password without special characters"},
+		{"          [--preset firefox]", "Generate a password like the firefox password manager"},
+		{"          [--pronounceable]", "Generate a pronounceable password"},
+		{"          [--passphrase [--wordlist <file>]]", "Generate a passphrase (<length> is the number of words)"},
+	}
+}
+
+func (g *passwordGeneratorArgs) fullHelp() []string {
+	return []string{
+		"If --generate is specified a random password is generated (with a cryptographically secure RNG).",
+		"The generated password is printed once, with --quiet it is not printed at all.",
+		"By default the password has 20 characters (letters, digits and symbols), --no-symbols removes the symbols (also with --preset firefox).",
+		"With --charset the characters can be specified explicitly.",
+		"With --preset firefox the password follows the rules of the firefox password manager (15 characters, no ambiguous characters).",
+		"With --pronounceable the password consists of alternating consonants and vowels (default length 16).",
+		"With --passphrase a passphrase of random words is generated (default 6 words from the EFF large wordlist, or from the --wordlist file).",
+	}
+}
+
+func (g *passwordGeneratorArgs) parseArg(arg cli.ArgumentTuple) (bool, error) {
+	if arg.Key == "generate" && arg.Value == nil {
+		g.Generate = true
+		return true, nil
+	}
+	if arg.Key == "generate" && arg.Value != nil {
+		v, err := strconv.ParseInt(*arg.Value, 10, 32)
+		if err != nil || v <= 0 {
+			return true, fferr.DirectOutput.New(fmt.Sprintf("Failed to parse number argument '--%s': '%s'", arg.Key, *arg.Value))
+		}
+		g.Generate = true
+		g.Length = langext.Ptr(int(v))
+		return true, nil
+	}
+	if arg.Key == "charset" && arg.Value != nil {
+		g.Charset = langext.Ptr(*arg.Value)
+		return true, nil
+	}
+	if arg.Key == "no-symbols" && arg.Value == nil {
+		g.NoSymbols = true
+		return true, nil
+	}
+	if arg.Key == "preset" && arg.Value != nil {
+		if *arg.Value != "firefox" {
+			return true, fferr.DirectOutput.New("Invalid parameter for preset: '" + *arg.Value + "' (must be 'firefox')")
+		}
+		g.Preset = langext.Ptr(*arg.Value)
+		return true, nil
+	}
+	if arg.Key == "pronounceable" && arg.Value == nil {
+		g.Pronounceable = true
+		return true, nil
+	}
+	if arg.Key == "passphrase" && arg.Value == nil {
+		g.Passphrase = true
+		return true, nil
+	}
+	if arg.Key == "wordlist" && arg.Value != nil {
+		g.Wordlist = langext.Ptr(*arg.Value)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (g *passwordGeneratorArgs) validate() error {
+	if !g.Generate && (g.Charset != nil || g.NoSymbols || g.Preset != nil || g.Pronounceable || g.Passphrase || g.Wordlist != nil) {
+		return fferr.DirectOutput.New("The password generator options can only be used together with --generate")
+	}
+	if langext.BoolCount(g.Charset != nil, g.Preset != nil, g.Pronounceable, g.Passphrase) > 1 {
+		return fferr.DirectOutput.New("Must specify at most one of --charset, --preset, --pronounceable, --passphrase")
+	}
+	if g.NoSymbols && (g.Charset != nil || g.Pronounceable || g.Passphrase) {
+		return fferr.DirectOutput.New("--no-symbols cannot be used together with --charset, --pronounceable or --passphrase")
+	}
+	if g.Wordlist != nil && !g.Passphrase {
+		return fferr.DirectOutput.New("--wordlist can only be used together with --passphrase")
+	}
+	return nil
+}
+
+func (g *passwordGeneratorArgs) generate() (string, error) {
+	if g.Preset != nil && *g.Preset == "firefox" {
+		return passwordgen.Firefox(langext.Coalesce(g.Length, passwordgen.FirefoxDefaultLength), !g.NoSymbols)
+	}
+
+	if g.Pronounceable {
+		return passwordgen.Pronounceable(langext.Coalesce(g.Length, 16))
+	}
+
+	if g.Passphrase {
+		wordlist := passwordgen.DefaultWordlist()
+		if g.Wordlist != nil {
+			wl, err := passwordgen.LoadWordlist(*g.Wordlist)
+			if err != nil {
+				return "", fferr.WrapDirectOutput(err, consts.ExitcodeError, "Failed to load wordlist '"+*g.Wordlist+"'")
+			}
+			wordlist = wl
+		}
+		return passwordgen.Passphrase(langext.Coalesce(g.Length, 6), "-", wordlist)
+	}
+
+	charset := passwordgen.CharsetLower + passwordgen.CharsetUpper + passwordgen.CharsetDigits
+	if !g.NoSymbols {
+		charset += passwordgen.CharsetSymbols
+	}
+	if g.Charset != nil {
+		charset = *g.Charset
+	}
+
+	return passwordgen.Random(langext.Coalesce(g.Length, 20), charset)
+}
@@ -20,6 +20,8 @@ type CLIArgumentsPasswordsCreate struct {
 	UsernameField *string
 	PasswordField *string
 
+	Generator passwordGeneratorArgs
+
 	CLIArgumentsPasswordsUtil
 }
 
@@ -32,7 +34,7 @@ func (a *CLIArgumentsPasswordsCreate) Mode() cli.Mode {
 }
 
 func (a *CLIArgumentsPasswordsCreate) PositionArgCount() (*int, *int) {
-	return langext.Ptr(3), langext.Ptr(3)
+	return langext.Ptr(2), langext.Ptr(3)
 }
 
 func (a *CLIArgumentsPasswordsCreate) AvailableOutputFormats() []cli.OutputFormat {
@@ -40,34 +42,46 @@ func (a *CLIArgumentsPasswordsCreate) AvailableOutputFormats() []cli.OutputForma
 }
 
 func (a *CLIArgumentsPasswordsCreate) ShortHelp() [][]string {
-	return [][]string{
-		{"ffsclient passwords create <host> <username> <password>", "Insert a new password"},
+	r := [][]string{
+		{"ffsclient passwords create <host> <username> [<password>]", "Insert a new password"},
 		{"          [--form-submit-url <url>]", "Specify the submission URL (GET/POST url set by <form>)"},
 		{"          [--http-realm <realm>]", "Specify the HTTP Realm (HTTP Realm for which the login is valid)"},
 		{"          [--username-field <name>]", "Specify the Username field (HTML field name of the username)"},
 		{"          [--password-field <name>]", "Specify the Password field (HTML field name of the password)"},
 	}
+	return append(r, a.Generator.shortHelp()...)
 }
 
 func (a *CLIArgumentsPasswordsCreate) FullHelp() []string {
-	return []string{
-		"$> ffsclient passwords create <host> <username> <password> [--form-submit-url <url>] [--http-realm <realm>] [--username-field <name>] [--password-field <name>]",
+	r := []string{
+		"$> ffsclient passwords create <host> <username> [<password>] [--form-submit-url <url>] [--http-realm <realm>] [--username-field <name>] [--password-field <name>] [--generate [<length>]] [--charset <chars>] [--no-symbols] [--preset firefox] [--pronounceable] [--passphrase [--wordlist <file>]]",
 		"",
 		"Insert a new password",
 		"",
-		"The fields <host>, <username> <password> must be specified.",
+		"The fields <host>, <username> <password> must be specified (<password> must be omitted if --generate is used).",
 		"The fields formSubmitURL, httpRealm, usernameField and passwordField are optional and get their default values (empty-string/null) if not supplied",
 		"",
-		"Outputs the RecordID of the newly created entry on success.",
 	}
+	r = append(r, a.Generator.fullHelp()...)
+	r = append(r, "")
+	r = append(r, "Outputs the RecordID of the newly created entry on success (and the generated password in a second line).")
+	return r
 }
 
 func (a *CLIArgumentsPasswordsCreate) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
 	a.Host = positionalArgs[0]
 	a.Username = positionalArgs[1]
-	a.Password = positionalArgs[2]
+	if len(positionalArgs) > 2 {
+		a.Password = positionalArgs[2]
+	}
 
 	for _, arg := range optionArgs {
+		if ok, err := a.Generator.parseArg(arg); ok {
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if arg.Key == "form-submit-url" && arg.Value != nil {
 			a.FormSubmitURL = langext.Ptr(*arg.Value)
 			continue
@@ -87,6 +101,17 @@ func (a *CLIArgumentsPasswordsCreate) Init(positionalArgs []string, optionArgs [
 		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
 	}
 
+	if err := a.Generator.validate(); err != nil {
+		return err
+	}
+
+	if a.Generator.Generate && len(positionalArgs) > 2 {
+		return fferr.DirectOutput.New("Cannot specify a <password> together with --generate")
+	}
+	if !a.Generator.Generate && len(positionalArgs) < 3 {
+		return fferr.DirectOutput.New("Not enough arguments for `ffsclient passwords create` (must specify a <password> or --generate)")
+	}
+
 	return nil
 }
 
@@ -103,6 +128,18 @@ func (a *CLIArgumentsPasswordsCreate) Execute(ctx *cli.FFSContext) error {
 
 	// ========================================================================
 
+	if a.Generator.Generate {
+		ctx.PrintVerbose("Generate new password")
+
+		pw, err := a.Generator.generate()
+		if err != nil {
+			return err
+		}
+		a.Password = pw
+	}
+
+	// ========================================================================
+
 	recordID := a.newPasswordID()
 
 	now := time.Now()
@@ -147,5 +184,8 @@ func (a *CLIArgumentsPasswordsCreate) Execute(ctx *cli.FFSContext) error {
 	}
 
 	ctx.PrintPrimaryOutput(recordID)
+	if a.Generator.Generate {
+		ctx.PrintPrimaryOutput(a.Password)
+	}
 	return nil
 }
@@ -0,0 +1,304 @@
+package impl
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"ffsyncclient/passworddedupe"
+	"ffsyncclient/syncclient"
+	"fmt"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"github.com/joomcode/errorx"
+	"strconv"
+	"time"
+)
+
+type CLIArgumentsPasswordsDedupe struct {
+	DryRun        bool
+	Yes           bool
+	ShowPasswords bool
+
+	CLIArgumentsPasswordsUtil
+}
+
+func NewCLIArgumentsPasswordsDedupe() *CLIArgumentsPasswordsDedupe {
+	return &CLIArgumentsPasswordsDedupe{
+		DryRun:        false,
+		Yes:           false,
+		ShowPasswords: false,
+	}
+}
+
+func (a *CLIArgumentsPasswordsDedupe) Mode() cli.Mode {
+	return cli.ModePasswordsDedupe
+}
+
+func (a *CLIArgumentsPasswordsDedupe) PositionArgCount() (*int, *int) {
+	return langext.Ptr(0), langext.Ptr(0)
+}
+
+func (a *CLIArgumentsPasswordsDedupe) AvailableOutputFormats() []cli.OutputFormat {
+	return []cli.OutputFormat{cli.OutputFormatText}
+}
+
+func (a *CLIArgumentsPasswordsDedupe) ShortHelp() [][]string {
+	return [][]string{
+		{"ffsclient passwords dedupe", "Find and merge duplicate logins (same origin, username and login type)"},
+		{"          [--dry-run]", "Only show the duplicates, do not change anything"},
+		{"          [--yes]", "Do not ask for confirmation"},
+		{"          [--show-passwords]", "Show the actual passwords in the diff"},
+	}
+}
+
+func (a *CLIArgumentsPasswordsDedupe) FullHelp() []string {
+	return []string{
+		"$> ffsclient passwords dedupe [--dry-run] [--yes] [--show-passwords]",
+		"",
+		"Find and merge duplicate logins",
+		"",
+		"Logins are grouped by their normalized origin (scheme, host and port), their username, their httpRealm and whether they have a formSubmitURL.",
+		"An HTTP-auth login and a form login for the same site and user are never merged.",
+		"For every group with more than one login the differing fields are shown.",
+		"A group is merged into the login with the most recently changed password,",
+		"the timesUsed counters are summed up, the earliest timeCreated and the latest timeLastUsed are kept.",
+		"All other logins of the group are deleted (only marked with {deleted:true} as a tombstone).",
+		"",
+		"If --dry-run is specified nothing is changed.",
+		"Before changing anything you are asked for confirmation, this can be skipped with --yes.",
+	}
+}
+
+func (a *CLIArgumentsPasswordsDedupe) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
+	for _, arg := range optionArgs {
+		if arg.Key == "dry-run" && arg.Value == nil {
+			a.DryRun = true
+			continue
+		}
+		if arg.Key == "yes" && arg.Value == nil {
+			a.Yes = true
+			continue
+		}
+		if arg.Key == "show-passwords" && arg.Value == nil {
+			a.ShowPasswords = true
+			continue
+		}
+		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
+	}
+
+	return nil
+}
+
+func (a *CLIArgumentsPasswordsDedupe) Execute(ctx *cli.FFSContext) error {
+	ctx.PrintVerbose("[Dedupe Passwords]")
+	ctx.PrintVerbose("")
+	ctx.PrintVerboseKV("DryRun", a.DryRun)
+
+	// ========================================================================
+
+	client, session, err := a.InitClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[1] List passwords")
+
+	records, err := client.ListRecords(ctx, session, consts.CollectionPasswords, nil, nil, false, true, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[2] Find duplicates")
+
+	groups, recordMap := a.findDuplicates(ctx, records)
+
+	if len(groups) == 0 {
+		ctx.PrintPrimaryOutput("No duplicate logins found.")
+		return nil
+	}
+
+	deleteCount := 0
+	for i, grp := range groups {
+		a.printGroup(ctx, i+1, grp)
+		deleteCount += len(grp.Entries) - 1
+	}
+
+	if a.DryRun {
+		ctx.PrintPrimaryOutput(fmt.Sprintf("Found %d groups of duplicates (%d logins would be deleted), no changes were made (--dry-run).", len(groups), deleteCount))
+		return nil
+	}
+
+	if !a.Yes {
+		ok, err := a.confirm(fmt.Sprintf("Merge %d groups of duplicates and delete %d logins?", len(groups), deleteCount))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			ctx.PrintPrimaryOutput("Aborted.")
+			return nil
+		}
+	}
+
+	// ========================================================================
+
+	ctx.PrintVerboseHeader("[3] Merge duplicates")
+
+	for _, grp := range groups {
+		err = a.mergeGroup(ctx, client, session, grp, recordMap[grp.Entries[grp.Keep].ID])
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("Merged %d groups of duplicates, deleted %d logins.", len(groups), deleteCount))
+	return nil
+}
+
+func (a *CLIArgumentsPasswordsDedupe) findDuplicates(ctx *cli.FFSContext, records []models.Record) ([]passworddedupe.Group, map[string]models.Record) {
+	passwords := make([]models.PasswordRecord, 0, len(records))
+	recordMap := make(map[string]models.Record, len(records))
+	modified := make(map[string]time.Time, len(records))
+
+	for _, rec := range records {
+		pwrec, err := models.UnmarshalPassword(ctx, rec)
+		if err != nil {
+			ctx.PrintVerbose(fmt.Sprintf("Skip record %s (failed to decode)", rec.ID))
+			continue
+		}
+		passwords = append(passwords, pwrec)
+		recordMap[pwrec.ID] = rec
+		modified[pwrec.ID] = rec.Modified
+	}
+
+	groups := passworddedupe.Find(passwords, modified)
+
+	for _, grp := range groups {
+		ctx.PrintVerbose(fmt.Sprintf("Found %d duplicates for %s (%s)", len(grp.Entries), grp.Origin, grp.Username))
+	}
+
+	return groups, recordMap
+}
+
+func (a *CLIArgumentsPasswordsDedupe) printGroup(ctx *cli.FFSContext, num int, grp passworddedupe.Group) {
+	kind := "form"
+	if grp.HTTPRealm != nil {
+		kind = "http-auth " + strconv.Quote(*grp.HTTPRealm)
+	} else if !grp.HasFormSubmitURL {
+		kind = "no formSubmitURL"
+	}
+
+	ctx.PrintPrimaryOutput(fmt.Sprintf("[%d] %s (%s) [%s] - %d logins", num, grp.Origin, grp.Username, kind, len(grp.Entries)))
+
+	for i, v := range grp.Entries {
+		action := "delete"
+		if i == grp.Keep {
+			action = "keep  "
+		}
+		ctx.PrintPrimaryOutput(fmt.Sprintf("    %s  %s  password:%s  changed:%s  used:%d",
+			action,
+			v.ID,
+			v.FormatPassword(a.ShowPasswords),
+			fmtOptDate(ctx, v.PasswordChanged),
+			langext.Coalesce(v.TimesUsed, 0)))
+	}
+
+	fields := []struct {
+		name  string
+		value func(v models.PasswordRecord) string
+	}{
+		{"hostname", func(v models.PasswordRecord) string { return v.Hostname }},
+		{"password", func(v models.PasswordRecord) string { return v.Password }},
+		{"formSubmitURL", func(v models.PasswordRecord) string { return v.FormSubmitURL }},
+		{"httpRealm", func(v models.PasswordRecord) string { return langext.Coalesce(v.HTTPRealm, "") }},
+		{"usernameField", func(v models.PasswordRecord) string { return v.UsernameField }},
+		{"passwordField", func(v models.PasswordRecord) string { return v.PasswordField }},
+	}
+
+	for _, field := range fields {
+		differs := false
+		for _, v := range grp.Entries {
+			if field.value(v) != field.value(grp.Entries[0]) {
+				differs = true
+			}
+		}
+		if !differs {
+			continue
+		}
+
+		ctx.PrintPrimaryOutput("    ~ " + field.name + ":")
+		for _, v := range grp.Entries {
+			value := field.value(v)
+			if field.name == "password" {
+				value = v.FormatPassword(a.ShowPasswords)
+			}
+			ctx.PrintPrimaryOutput(fmt.Sprintf("        %s  %s", v.ID, strconv.Quote(value)))
+		}
+	}
+
+	ctx.PrintPrimaryOutput("")
+}
+
+func (a *CLIArgumentsPasswordsDedupe) mergeGroup(ctx *cli.FFSContext, client *syncclient.FxAClient, session syncclient.FFSyncSession, grp passworddedupe.Group, keepRecord models.Record) error {
+	var err error
+
+	merged := passworddedupe.Merge(grp)
+
+	newData := keepRecord.DecodedData
+
+	if merged.TimesUsed != nil {
+		newData, err = langext.PatchJson(newData, "timesUsed", *merged.TimesUsed)
+		if err != nil {
+			return errorx.Decorate(err, "failed to patch data of existing record")
+		}
+	}
+	if merged.Created != nil {
+		newData, err = langext.PatchJson(newData, "timeCreated", merged.Created.UnixMilli())
+		if err != nil {
+			return errorx.Decorate(err, "failed to patch data of existing record")
+		}
+	}
+	if merged.LastUsed != nil {
+		newData, err = langext.PatchJson(newData, "timeLastUsed", merged.LastUsed.UnixMilli())
+		if err != nil {
+			return errorx.Decorate(err, "failed to patch data of existing record")
+		}
+	}
+
+	if string(newData) != string(keepRecord.DecodedData) {
+		ctx.PrintVerbose("Update Record " + keepRecord.ID)
+
+		payload, err := client.EncryptPayload(ctx, session, consts.CollectionPasswords, string(newData))
+		if err != nil {
+			return err
+		}
+
+		update := models.RecordUpdate{
+			ID:      keepRecord.ID,
+			Payload: langext.Ptr(payload),
+		}
+
+		err = client.PutRecord(ctx, session, consts.CollectionPasswords, update, false, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, v := range grp.Entries {
+		if i == grp.Keep {
+			continue
+		}
+
+		ctx.PrintVerbose("Delete Record " + v.ID)
+
+		err = client.SoftDeleteRecord(ctx, session, consts.CollectionPasswords, v.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -54,11 +54,11 @@ func (a *CLIArgumentsPasswordsDelete) FullHelp() []string {
 		"Delete a single password",
 		"",
 		"By default we can supply a host or a record-id.",
-		"If --is-host is specified, the query is parsed as an URI and we return the password that matches the host.",
+		"If --is-host is specified, the query is parsed as an URI and we delete the password with the same host (logins on subdomains or the base-domain are not matched).",
 		"If --is-exact-host is specified, the query is matched exactly against the host field in the password record.",
 		"If --is-id is specified, the query is matched exactly agains the record-id.",
 		"If --is-id is _not_ specified this method needs to query all passwords from the server and do a local search.",
-		"If multiple passwords match the first match is deleted.",
+		"If multiple passwords match nothing is deleted and the exitcode [91] is returned (use --is-id to select one).",
 		"If --hard is specified we delete the record, otherwise we only add {deleted:true} to mark it as a tombstone",
 		"If no matching password is found the exitcode [82] is returned",
 	}
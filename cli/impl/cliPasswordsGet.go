@@ -13,6 +13,9 @@ type CLIArgumentsPasswordsGet struct {
 	QueryIsHost      bool
 	QueryIsExactHost bool
 	QueryIsID        bool
+	FormSubmitURL    *string
+	HTTPRealm        *string
+	All              bool
 
 	CLIArgumentsPasswordsUtil
 }
@@ -23,6 +26,9 @@ func NewCLIArgumentsPasswordsGet() *CLIArgumentsPasswordsGet {
 		QueryIsHost:      false,
 		QueryIsExactHost: false,
 		QueryIsID:        false,
+		FormSubmitURL:    nil,
+		HTTPRealm:        nil,
+		All:              false,
 	}
 }
 
@@ -42,17 +48,27 @@ func (a *CLIArgumentsPasswordsGet) ShortHelp() [][]string {
 	return [][]string{
 		{"ffsclient passwords get <host|id>", "Insert a new password"},
 		{"          [--is-host | --is-exact-host | --is-id]", "Specify that the supplied argument is a host / record-id (otherwise both is possible)"},
+		{"          [--form-submit-url <url>]", "Only match logins for this form-action (or logins without a form-action)"},
+		{"          [--http-realm <realm>]", "Only match logins for this HTTP Realm"},
+		{"          [--all]", "Return all matching logins (ordered by specificity)"},
 	}
 }
 
 func (a *CLIArgumentsPasswordsGet) FullHelp() []string {
 	return []string{
-		"$> ffsclient passwords get <host|id> [--is-host | --is-exact-host | --is-id]",
+		"$> ffsclient passwords get <host|id> [--is-host | --is-exact-host | --is-id] [--form-submit-url <url>] [--http-realm <realm>] [--all]",
 		"",
 		"Get a password",
 		"",
 		"By default we can supply a host or a record-id.",
 		"If --is-host is specified, the query is parsed as an URI and we return the password that matches the host.",
+		"Hosts are matched like in firefox: exact origin (scheme, host and port) > http login on an https origin > same host > subdomain > same base-domain.",
+		"The base-domain is determined with the public suffix list (e.g. `a.example.co.uk` and `b.example.co.uk` match, but `alice.github.io` and `bob.github.io` do not).",
+		"Internationalized domain names are compared in their punycode form.",
+		"If --form-submit-url is specified, logins with a different form-action origin are skipped.",
+		"If --http-realm is specified, only logins with this HTTP Realm are matched.",
+		"If multiple logins match, the most specific (and then the most recently used) one is returned.",
+		"If --all is specified, all matching logins are returned.",
 		"If --is-exact-host is specified, the query is matched exactly against the host field in the password record.",
 		"If --is-id is specified, the query is matched exactly agains the record-id.",
 		"If --is-id is _not_ specified this method needs to query all passwords from the server and do a local search.",
@@ -76,6 +92,18 @@ func (a *CLIArgumentsPasswordsGet) Init(positionalArgs []string, optionArgs []cl
 			a.QueryIsID = true
 			continue
 		}
+		if arg.Key == "form-submit-url" && arg.Value != nil {
+			a.FormSubmitURL = langext.Ptr(*arg.Value)
+			continue
+		}
+		if arg.Key == "http-realm" && arg.Value != nil {
+			a.HTTPRealm = langext.Ptr(*arg.Value)
+			continue
+		}
+		if arg.Key == "all" && arg.Value == nil {
+			a.All = true
+			continue
+		}
 		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
 	}
 
@@ -99,18 +127,22 @@ func (a *CLIArgumentsPasswordsGet) Execute(ctx *cli.FFSContext) error {
 
 	// ========================================================================
 
-	_, record, found, err := a.findPasswordRecord(ctx, client, session, a.Query, a.QueryIsID, a.QueryIsHost, a.QueryIsExactHost)
+	matches, err := a.findPasswordRecords(ctx, client, session, a.Query, a.QueryIsID, a.QueryIsHost, a.QueryIsExactHost, a.FormSubmitURL, a.HTTPRealm)
 	if err != nil {
 		return err
 	}
 
-	if !found {
+	if len(matches) == 0 {
 		return fferr.NewDirectOutput(consts.ExitcodePasswordNotFound, "Record not found")
 	}
 
 	// ========================================================================
 
-	return a.printOutput(ctx, record)
+	if a.All {
+		return a.printOutputAll(ctx, matches)
+	}
+
+	return a.printOutput(ctx, matches[0].Password)
 }
 
 func (a *CLIArgumentsPasswordsGet) printOutput(ctx *cli.FFSContext, password models.PasswordRecord) error {
@@ -132,3 +164,39 @@ func (a *CLIArgumentsPasswordsGet) printOutput(ctx *cli.FFSContext, password mod
 		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
 	}
 }
+
+func (a *CLIArgumentsPasswordsGet) printOutputAll(ctx *cli.FFSContext, matches []passwordMatch) error {
+	switch langext.Coalesce(ctx.Opt.Format, cli.OutputFormatText) {
+
+	case cli.OutputFormatText:
+		for _, v := range matches {
+			ctx.PrintPrimaryOutput(v.Password.Hostname + " " + v.Password.Username + " " + v.Password.Password)
+		}
+		return nil
+
+	case cli.OutputFormatJson:
+		arr := langext.A{}
+		for _, v := range matches {
+			obj := v.Password.ToJSON(ctx, true)
+			obj["specificity"] = int(v.Specificity)
+			arr = append(arr, obj)
+		}
+		ctx.PrintPrimaryOutputJSON(arr)
+		return nil
+
+	case cli.OutputFormatXML:
+		type xml struct {
+			Entries []any
+			XMLName struct{} `xml:"Passwords"`
+		}
+		node := xml{Entries: make([]any, 0, len(matches))}
+		for _, v := range matches {
+			node.Entries = append(node.Entries, v.Password.ToXML(ctx, "Password", true))
+		}
+		ctx.PrintPrimaryOutputXML(node)
+		return nil
+
+	default:
+		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/joomcode/errorx"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"time"
 )
 
 type CLIArgumentsPasswordsUpdate struct {
@@ -24,6 +25,8 @@ type CLIArgumentsPasswordsUpdate struct {
 	NewUsernameField *string
 	NewPasswordField *string
 
+	Generator passwordGeneratorArgs
+
 	CLIArgumentsPasswordsUtil
 }
 
@@ -56,7 +59,7 @@ func (a *CLIArgumentsPasswordsUpdate) AvailableOutputFormats() []cli.OutputForma
 }
 
 func (a *CLIArgumentsPasswordsUpdate) ShortHelp() [][]string {
-	return [][]string{
+	r := [][]string{
 		{"ffsclient passwords update <host|id>", "Update an existing password"},
 		{"          [--is-host | --is-exact-host | --is-id]", "Specify that the supplied argument is a host / record-id (otherwise both is possible)"},
 		{"          [--host <url>]", "Update the host field"},
@@ -67,20 +70,22 @@ func (a *CLIArgumentsPasswordsUpdate) ShortHelp() [][]string {
 		{"          [--username-field <name>]", "Update the Username field (HTML field name of the username)"},
 		{"          [--password-field <name>]", "Update the Password field (HTML field name of the password)"},
 	}
+	return append(r, a.Generator.shortHelp()...)
 }
 
 func (a *CLIArgumentsPasswordsUpdate) FullHelp() []string {
-	return []string{
-		"$> ffsclient passwords update <host|id> [--is-host | --is-exact-host | --is-id] [--host <url>] [--username <user>] [--password <pass>] [--form-submit-url <url>] [--http-realm <realm>] [--username-field <name>] [--password-field <name>]",
+	r := []string{
+		"$> ffsclient passwords update <host|id> [--is-host | --is-exact-host | --is-id] [--host <url>] [--username <user>] [--password <pass>] [--form-submit-url <url>] [--http-realm <realm>] [--username-field <name>] [--password-field <name>] [--generate [<length>]] [--charset <chars>] [--no-symbols] [--preset firefox] [--pronounceable] [--passphrase [--wordlist <file>]]",
 		"",
 		"Update the specified fields of an existing password entry.",
 		"",
 		"By default we can supply a host or a record-id.",
-		"If --is-host is specified, the query is parsed as an URI and we return the password that matches the host.",
+		"If --is-host is specified, the query is parsed as an URI and we update the password with the same host (logins on subdomains or the base-domain are not matched).",
 		"If --is-exact-host is specified, the query is matched exactly against the host field in the password record.",
 		"If --is-id is specified, the query is matched exactly agains the record-id.",
 		"If --is-id is _not_ specified this method needs to query all passwords from the server and do a local search.",
 		"If no matching password is found the exitcode [82] is returned",
+		"If multiple passwords match nothing is updated and the exitcode [91] is returned (use --is-id to select one).",
 		"",
 		"The fields of the found password can be updated individually with the parameters:",
 		"  * --host",
@@ -90,7 +95,12 @@ func (a *CLIArgumentsPasswordsUpdate) FullHelp() []string {
 		"  * --http-realm",
 		"  * --username-field",
 		"  * --password-field",
+		"",
+		"If the password is changed the timePasswordChanged field is also updated.",
+		"Instead of --password a new password can be generated with --generate.",
 	}
+	r = append(r, a.Generator.fullHelp()...)
+	return r
 }
 
 func (a *CLIArgumentsPasswordsUpdate) Init(positionalArgs []string, optionArgs []cli.ArgumentTuple) error {
@@ -109,6 +119,12 @@ func (a *CLIArgumentsPasswordsUpdate) Init(positionalArgs []string, optionArgs [
 			a.QueryIsID = true
 			continue
 		}
+		if ok, err := a.Generator.parseArg(arg); ok {
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if arg.Key == "host" && arg.Value != nil {
 			a.NewHost = langext.Ptr(*arg.Value)
 			continue
@@ -140,6 +156,14 @@ func (a *CLIArgumentsPasswordsUpdate) Init(positionalArgs []string, optionArgs [
 		return fferr.DirectOutput.New("Unknown argument: " + arg.Key)
 	}
 
+	if err := a.Generator.validate(); err != nil {
+		return err
+	}
+
+	if a.Generator.Generate && a.NewPassword != nil {
+		return fferr.DirectOutput.New("Cannot specify --password together with --generate")
+	}
+
 	return nil
 }
 
@@ -173,6 +197,18 @@ func (a *CLIArgumentsPasswordsUpdate) Execute(ctx *cli.FFSContext) error {
 
 	// ========================================================================
 
+	if a.Generator.Generate {
+		ctx.PrintVerboseHeader("[1] Generate Password")
+
+		pw, err := a.Generator.generate()
+		if err != nil {
+			return err
+		}
+		a.NewPassword = langext.Ptr(pw)
+	}
+
+	// ========================================================================
+
 	ctx.PrintVerboseHeader("[2] Patch Data")
 
 	newData, err := a.patchData(ctx, record, pwrec)
@@ -216,7 +252,11 @@ func (a *CLIArgumentsPasswordsUpdate) Execute(ctx *cli.FFSContext) error {
 		return fferr.NewDirectOutput(consts.ExitcodeUnsupportedOutputFormat, "Unsupported output-format: "+ctx.Opt.Format.String())
 	}
 
-	ctx.PrintPrimaryOutput("Okay.")
+	if a.Generator.Generate {
+		ctx.PrintPrimaryOutput(*a.NewPassword)
+	} else {
+		ctx.PrintPrimaryOutput("Okay.")
+	}
 	return nil
 }
 
@@ -264,12 +304,25 @@ func (a *CLIArgumentsPasswordsUpdate) patchData(ctx *cli.FFSContext, record mode
 	}
 
 	if a.NewPassword != nil {
-		ctx.PrintVerbose(fmt.Sprintf("Patch field [password] from \"%s\" to \"%s\"", pwrec.Password, *a.NewPassword))
+		if a.Generator.Generate {
+			ctx.PrintVerbose("Patch field [password] to the generated password")
+		} else {
+			ctx.PrintVerbose(fmt.Sprintf("Patch field [password] from \"%s\" to \"%s\"", pwrec.Password, *a.NewPassword))
+		}
 
 		newData, err = langext.PatchJson(newData, "password", *a.NewPassword)
 		if err != nil {
 			return nil, errorx.Decorate(err, "failed to patch data of existing record")
 		}
+
+		if *a.NewPassword != pwrec.Password {
+			ctx.PrintVerbose("Patch field [timePasswordChanged] to now")
+
+			newData, err = langext.PatchJson(newData, "timePasswordChanged", time.Now().UnixMilli())
+			if err != nil {
+				return nil, errorx.Decorate(err, "failed to patch data of existing record")
+			}
+		}
 	}
 
 	if a.NewFormSubmitURL != nil {
@@ -92,8 +92,40 @@ func GetModeImpl(m cli.Mode) cli.Verb {
 		return NewCLIArgumentsBookmarksCreateFolder()
 	case cli.ModeBookmarksCreateSeparator:
 		return NewCLIArgumentsBookmarksCreateSeparator()
+	case cli.ModeBookmarksCreateQuery:
+		return NewCLIArgumentsBookmarksCreateQuery()
+	case cli.ModeBookmarksCreateLivemark:
+		return NewCLIArgumentsBookmarksCreateLivemark()
 	case cli.ModeBookmarksUpdate:
 		return NewCLIArgumentsBookmarksUpdate()
+	case cli.ModeBookmarksImport:
+		return NewCLIArgumentsBookmarksImport()
+	case cli.ModeBookmarksMove:
+		return NewCLIArgumentsBookmarksMove()
+	case cli.ModeBookmarksCopy:
+		return NewCLIArgumentsBookmarksCopy()
+	case cli.ModeBookmarksCheck:
+		return NewCLIArgumentsBookmarksCheck()
+	case cli.ModeBookmarksSearch:
+		return NewCLIArgumentsBookmarksSearch()
+	case cli.ModeBookmarksDedupe:
+		return NewCLIArgumentsBookmarksDedupe()
+	case cli.ModeBookmarksMirror:
+		return NewCLIArgumentsBookmarksMirror()
+	case cli.ModeBookmarksSort:
+		return NewCLIArgumentsBookmarksSort()
+	case cli.ModeBookmarksResolve:
+		return NewCLIArgumentsBookmarksResolve()
+	case cli.ModeBookmarksTagsBase:
+		return NewCLIArgumentsBookmarksTagsBase()
+	case cli.ModeBookmarksTagsList:
+		return NewCLIArgumentsBookmarksTagsList()
+	case cli.ModeBookmarksTagsAdd:
+		return NewCLIArgumentsBookmarksTagsAdd()
+	case cli.ModeBookmarksTagsRename:
+		return NewCLIArgumentsBookmarksTagsRename()
+	case cli.ModeBookmarksTagsRemove:
+		return NewCLIArgumentsBookmarksTagsRemove()
 	case cli.ModePasswordsBase:
 		return NewCLIArgumentsPasswordsBase()
 	case cli.ModePasswordsList:
@@ -106,6 +138,12 @@ func GetModeImpl(m cli.Mode) cli.Verb {
 		return NewCLIArgumentsPasswordsUpdate()
 	case cli.ModePasswordsGet:
 		return NewCLIArgumentsPasswordsGet()
+	case cli.ModePasswordsDedupe:
+		return NewCLIArgumentsPasswordsDedupe()
+	case cli.ModeDockerCredentialHelper:
+		return NewCLIArgumentsDockerCredentialHelper()
+	case cli.ModeExec:
+		return NewCLIArgumentsExec()
 	case cli.ModeFormsBase:
 		return NewCLIArgumentsFormsBase()
 	case cli.ModeFormsList:
@@ -116,12 +154,28 @@ func GetModeImpl(m cli.Mode) cli.Verb {
 		return NewCLIArgumentsFormsCreate()
 	case cli.ModeFormsDelete:
 		return NewCLIArgumentsFormsDelete()
+	case cli.ModeFormsUpdate:
+		return NewCLIArgumentsFormsUpdate()
+	case cli.ModeFormsPurge:
+		return NewCLIArgumentsFormsPurge()
+	case cli.ModeFormsScan:
+		return NewCLIArgumentsFormsScan()
 	case cli.ModeHistoryBase:
 		return NewCLIArgumentsHistoryBase()
 	case cli.ModeHistoryList:
 		return NewCLIArgumentsHistoryList()
+	case cli.ModeHistorySearch:
+		return NewCLIArgumentsHistorySearch()
+	case cli.ModeHistoryStats:
+		return NewCLIArgumentsHistoryStats()
+	case cli.ModeHistoryVisits:
+		return NewCLIArgumentsHistoryVisits()
 	case cli.ModeHistoryDelete:
 		return NewCLIArgumentsHistoryDelete()
+	case cli.ModeHistoryPurge:
+		return NewCLIArgumentsHistoryPurge()
+	case cli.ModeHistoryImport:
+		return NewCLIArgumentsHistoryImport()
 	case cli.ModeTabsBase:
 		return NewCLIArgumentsTabsBase()
 	case cli.ModeTabsList:
@@ -153,3 +207,15 @@ func fmtOptDate(ctx *cli.FFSContext, d *time.Time) string {
 	}
 	return d.In(ctx.Opt.TimeZone).Format(ctx.Opt.TimeFormat)
 }
+
+// parseDateArg parses a date argument, either as an RFC 3339 timestamp or as a day (YYYY-MM-DD, in the timezone `loc`)
+// The --timezone option (ctx.Opt.TimeZone) is only available in Execute, so the commands only validate the argument in Init.
+func parseDateArg(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", v, loc)
+}
@@ -10,6 +10,7 @@ import (
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
 	"git.blackforestbytes.com/BlackForestBytes/goext/timeext"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,12 @@ func parseCommandlineInternal() (cli.Verb, cli.Options, error) {
 
 	unprocessedArgs := os.Args[1:]
 
+	// Called as `docker-credential-ffsclient <action>` (via symlink) by the docker cli
+
+	if strings.HasPrefix(filepath.Base(os.Args[0]), "docker-credential-") {
+		unprocessedArgs = append([]string{string(cli.ModeDockerCredentialHelper)}, unprocessedArgs...)
+	}
+
 	// Process special cases
 
 	if len(unprocessedArgs) == 0 {
@@ -70,6 +77,12 @@ func parseCommandlineInternal() (cli.Verb, cli.Options, error) {
 		arg := unprocessedArgs[0]
 		unprocessedArgs = unprocessedArgs[1:]
 
+		if arg == "--" {
+			// everything after `--` is positional (e.g. the command in `ffsclient exec ... -- <cmd>`)
+			positionalArguments = append(positionalArguments, unprocessedArgs...)
+			unprocessedArgs = nil
+			break
+		}
 		if !strings.HasPrefix(arg, "-") {
 			if !positional {
 				return nil, cli.Options{}, fferr.DirectOutput.New("Unknown/Misplaced argument: " + arg)
@@ -25,21 +25,48 @@ const (
 	ModeBookmarksCreateBookmark  Mode = "bookmarks create bookmark"
 	ModeBookmarksCreateFolder    Mode = "bookmarks create folder"
 	ModeBookmarksCreateSeparator Mode = "bookmarks create separator"
+	ModeBookmarksCreateQuery     Mode = "bookmarks create query"
+	ModeBookmarksCreateLivemark  Mode = "bookmarks create livemark"
 	ModeBookmarksUpdate          Mode = "bookmarks update"
+	ModeBookmarksImport          Mode = "bookmarks import"
+	ModeBookmarksMove            Mode = "bookmarks move"
+	ModeBookmarksCopy            Mode = "bookmarks copy"
+	ModeBookmarksCheck           Mode = "bookmarks check"
+	ModeBookmarksSearch          Mode = "bookmarks search"
+	ModeBookmarksDedupe          Mode = "bookmarks dedupe"
+	ModeBookmarksResolve         Mode = "bookmarks resolve"
+	ModeBookmarksMirror          Mode = "bookmarks mirror"
+	ModeBookmarksSort            Mode = "bookmarks sort"
+	ModeBookmarksTagsBase        Mode = "bookmarks tags"
+	ModeBookmarksTagsList        Mode = "bookmarks tags list"
+	ModeBookmarksTagsAdd         Mode = "bookmarks tags add"
+	ModeBookmarksTagsRename      Mode = "bookmarks tags rename"
+	ModeBookmarksTagsRemove      Mode = "bookmarks tags remove"
 	ModePasswordsBase            Mode = "passwords"
 	ModePasswordsList            Mode = "passwords list"
 	ModePasswordsGet             Mode = "passwords get"
 	ModePasswordsCreate          Mode = "passwords create"
 	ModePasswordsUpdate          Mode = "passwords update"
 	ModePasswordsDelete          Mode = "passwords delete"
+	ModePasswordsDedupe          Mode = "passwords dedupe"
+	ModeDockerCredentialHelper   Mode = "docker-credential-helper"
+	ModeExec                     Mode = "exec"
 	ModeFormsBase                Mode = "forms"
 	ModeFormsList                Mode = "forms list"
 	ModeFormsGet                 Mode = "forms get"
 	ModeFormsCreate              Mode = "forms create"
 	ModeFormsDelete              Mode = "forms delete"
+	ModeFormsUpdate              Mode = "forms update"
+	ModeFormsPurge               Mode = "forms purge"
+	ModeFormsScan                Mode = "forms scan"
 	ModeHistoryBase              Mode = "history"
 	ModeHistoryList              Mode = "history list"
+	ModeHistorySearch            Mode = "history search"
+	ModeHistoryStats             Mode = "history stats"
+	ModeHistoryVisits            Mode = "history visits"
 	ModeHistoryDelete            Mode = "history delete"
+	ModeHistoryPurge             Mode = "history purge"
+	ModeHistoryImport            Mode = "history import"
 	ModeTabsBase                 Mode = "tabs"
 	ModeTabsList                 Mode = "tabs list"
 )
@@ -72,7 +99,23 @@ var ModesSpecial = []Mode{
 	ModeBookmarksCreateBookmark,
 	ModeBookmarksCreateFolder,
 	ModeBookmarksCreateSeparator,
+	ModeBookmarksCreateQuery,
+	ModeBookmarksCreateLivemark,
 	ModeBookmarksUpdate,
+	ModeBookmarksImport,
+	ModeBookmarksMove,
+	ModeBookmarksCopy,
+	ModeBookmarksCheck,
+	ModeBookmarksSearch,
+	ModeBookmarksDedupe,
+	ModeBookmarksResolve,
+	ModeBookmarksMirror,
+	ModeBookmarksSort,
+	ModeBookmarksTagsBase,
+	ModeBookmarksTagsList,
+	ModeBookmarksTagsAdd,
+	ModeBookmarksTagsRename,
+	ModeBookmarksTagsRemove,
 
 	ModePasswordsBase,
 	ModePasswordsList,
@@ -80,16 +123,28 @@ var ModesSpecial = []Mode{
 	ModePasswordsCreate,
 	ModePasswordsUpdate,
 	ModePasswordsGet,
+	ModePasswordsDedupe,
+
+	ModeDockerCredentialHelper,
+	ModeExec,
 
 	ModeFormsBase,
 	ModeFormsList,
 	ModeFormsGet,
 	ModeFormsCreate,
 	ModeFormsDelete,
+	ModeFormsUpdate,
+	ModeFormsPurge,
+	ModeFormsScan,
 
 	ModeHistoryBase,
 	ModeHistoryList,
+	ModeHistorySearch,
+	ModeHistoryStats,
+	ModeHistoryVisits,
 	ModeHistoryDelete,
+	ModeHistoryPurge,
+	ModeHistoryImport,
 
 	ModeTabsBase,
 	ModeTabsList,
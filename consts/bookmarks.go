@@ -2,6 +2,20 @@ package consts
 
 // special bookmark IDs
 const (
+	BookmarkIDPlaces  string = "places"
 	BookmarkIDMenu    string = "menu"
 	BookmarkIDToolbar string = "toolbar"
+	BookmarkIDUnfiled string = "unfiled"
+	BookmarkIDMobile  string = "mobile"
 )
+
+// BookmarkRootIDs are the top-level folders (children of `places`), in the order firefox displays them
+var BookmarkRootIDs = []string{BookmarkIDMenu, BookmarkIDToolbar, BookmarkIDUnfiled, BookmarkIDMobile}
+
+// BookmarkRootTitles are the names of the root folders in the firefox UI (the records themselves only contain their ID as title)
+var BookmarkRootTitles = map[string]string{
+	BookmarkIDMenu:    "Bookmarks Menu",
+	BookmarkIDToolbar: "Bookmarks Toolbar",
+	BookmarkIDUnfiled: "Other Bookmarks",
+	BookmarkIDMobile:  "Mobile Bookmarks",
+}
@@ -24,4 +24,10 @@ var (
 	ExitcodeParentNotAFolder          = FFExitCode{83}
 	ExitcodeInvalidPosition           = FFExitCode{84}
 	ExitcodeBookmarkFieldNotSupported = FFExitCode{85}
+	ExitcodeConcurrentModification    = FFExitCode{86}
+	ExitcodeBookmarkCycle             = FFExitCode{87}
+	ExitcodeBookmarkTreeInvalid       = FFExitCode{88}
+	ExitcodeBookmarkPathAmbiguous     = FFExitCode{89}
+	ExitcodeBookmarkMirrorConflict    = FFExitCode{90}
+	ExitcodePasswordAmbiguous         = FFExitCode{91}
 )
@@ -9,6 +9,7 @@ var (
 var (
 	Request404           = FFSyncErrors.NewType("http_404")
 	Request400           = FFSyncErrors.NewType("http_400")
+	Request412           = FFSyncErrors.NewType("http_412")
 	DirectOutput         = FFSyncErrors.NewType("direct_out")
 	UnmarshalConsistency = FFSyncErrors.NewType("unmarshal-consistency")
 )
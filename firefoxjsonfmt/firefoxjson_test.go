@@ -0,0 +1,153 @@
+package firefoxjsonfmt
+
+import (
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+const firefoxBackup = `{"guid":"root________","title":"","index":0,"dateAdded":1600000000000000,"lastModified":1600000000000000,"id":1,"typeCode":2,"type":"text/x-moz-place-container","root":"placesRoot","children":[
+  {"guid":"menu________","title":"menu","index":0,"dateAdded":1600000000000000,"lastModified":1600000000000000,"id":2,"typeCode":2,"type":"text/x-moz-place-container","root":"bookmarksMenuFolder","children":[
+    {"guid":"sepsepsepsep","title":"","index":1,"dateAdded":1600000000000000,"lastModified":1600000000000000,"id":8,"typeCode":3,"type":"text/x-moz-place-separator"},
+    {"guid":"aaaaaaaaaaaa","title":"Mozilla","index":0,"dateAdded":1600000001000000,"lastModified":1600000001000000,"id":7,"typeCode":1,"type":"text/x-moz-place","uri":"https://www.mozilla.org/","tags":"moz,web","keyword":"moz"}
+  ]},
+  {"guid":"toolbar_____","title":"toolbar","index":1,"dateAdded":1600000000000000,"lastModified":1600000000000000,"id":3,"typeCode":2,"type":"text/x-moz-place-container","root":"toolbarFolder","children":[
+    {"guid":"ffffffffffff","title":"Folder","index":0,"dateAdded":1600000002000000,"lastModified":1600000002000000,"id":9,"typeCode":2,"type":"text/x-moz-place-container","children":[]}
+  ]},
+  {"guid":"unfiled_____","title":"unfiled","index":3,"dateAdded":1600000000000000,"lastModified":1600000000000000,"id":5,"typeCode":2,"type":"text/x-moz-place-container","root":"unfiledBookmarksFolder"},
+  {"guid":"mobile______","title":"mobile","index":4,"dateAdded":1600000000000000,"lastModified":1600000000000000,"id":6,"typeCode":2,"type":"text/x-moz-place-container","root":"mobileFolder"}
+]}`
+
+func TestParse(t *testing.T) {
+	items, err := Parse([]byte(firefoxBackup))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]string, 0)
+	for _, v := range items {
+		ids = append(ids, v.ID)
+	}
+	if strings.Join(ids, ",") != "menu,toolbar,unfiled,mobile" {
+		t.Fatalf("unexpected roots: %v", ids)
+	}
+
+	menu := items[0]
+	if len(menu.ResolvedChildren) != 2 {
+		t.Fatalf("expected 2 entries in the menu, got %d", len(menu.ResolvedChildren))
+	}
+
+	bm := menu.ResolvedChildren[0]
+	if bm.Type != models.BookmarkTypeBookmark || bm.Title != "Mozilla" || bm.URI != "https://www.mozilla.org/" || bm.Keyword != "moz" || strings.Join(bm.Tags, "|") != "moz|web" {
+		t.Errorf("unexpected bookmark: %+v", bm.BookmarkRecord)
+	}
+	if bm.ID != "" {
+		t.Errorf("non-root entries should not have an ID, got %q", bm.ID)
+	}
+	if bm.DateAdded == nil || bm.DateAdded.Unix() != 1600000001 {
+		t.Errorf("unexpected dateAdded: %v", bm.DateAdded)
+	}
+
+	if menu.ResolvedChildren[1].Type != models.BookmarkTypeSeparator {
+		t.Errorf("expected a separator, got %v", menu.ResolvedChildren[1].Type)
+	}
+
+	if items[1].ResolvedChildren[0].Type != models.BookmarkTypeFolder || items[1].ResolvedChildren[0].Title != "Folder" {
+		t.Errorf("unexpected toolbar content: %+v", items[1].ResolvedChildren[0].BookmarkRecord)
+	}
+}
+
+func TestFormatRoundtrip(t *testing.T) {
+	added := time.UnixMicro(1650000000123456)
+
+	menu := &models.BookmarkTreeRecord{
+		BookmarkRecord: models.BookmarkRecord{ID: consts.BookmarkIDMenu, Type: models.BookmarkTypeFolder, Title: "menu"},
+		ResolvedChildren: []*models.BookmarkTreeRecord{
+			{BookmarkRecord: models.BookmarkRecord{ID: "aaaaaaaaaaaa", Type: models.BookmarkTypeBookmark, Title: "Go", URI: "https://go.dev/", DateAdded: &added, Tags: []string{"lang", "go"}}},
+			{
+				BookmarkRecord:   models.BookmarkRecord{ID: "bbbbbbbbbbbb", Type: models.BookmarkTypeFolder, Title: "Sub"},
+				ResolvedChildren: []*models.BookmarkTreeRecord{{BookmarkRecord: models.BookmarkRecord{ID: "cccccccccccc", Type: models.BookmarkTypeSeparator}}},
+			},
+		},
+	}
+	toolbar := &models.BookmarkTreeRecord{BookmarkRecord: models.BookmarkRecord{ID: consts.BookmarkIDToolbar, Type: models.BookmarkTypeFolder, Title: "toolbar"}}
+
+	data, err := Format(nil, []*models.BookmarkTreeRecord{toolbar, menu})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"guid":"root________"`) || !strings.Contains(string(data), `"root":"bookmarksMenuFolder"`) {
+		t.Errorf("output is missing the places roots: %s", data)
+	}
+
+	items, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 || items[0].ID != consts.BookmarkIDMenu || items[1].ID != consts.BookmarkIDToolbar {
+		t.Fatalf("unexpected roots: %v", items)
+	}
+
+	bm := items[0].ResolvedChildren[0]
+	if bm.URI != "https://go.dev/" || !bm.DateAdded.Equal(added) || strings.Join(bm.Tags, ",") != "lang,go" {
+		t.Errorf("bookmark did not survive the roundtrip: %+v", bm.BookmarkRecord)
+	}
+
+	sub := items[0].ResolvedChildren[1]
+	if sub.Title != "Sub" || len(sub.ResolvedChildren) != 1 || sub.ResolvedChildren[0].Type != models.BookmarkTypeSeparator {
+		t.Errorf("folder did not survive the roundtrip: %+v", sub)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte(`{"typeCode":1,"uri":"https://example.com"}`)); err == nil {
+		t.Error("Parse should fail if the top-level entry is not a folder")
+	}
+	if _, err := Parse([]byte(`<html>`)); err == nil {
+		t.Error("Parse should fail for non-json data")
+	}
+}
+
+func TestSpecialTypesRoundtrip(t *testing.T) {
+	menu := &models.BookmarkTreeRecord{
+		BookmarkRecord: models.BookmarkRecord{ID: consts.BookmarkIDMenu, Type: models.BookmarkTypeFolder, Title: "menu"},
+		ResolvedChildren: []*models.BookmarkTreeRecord{
+			{BookmarkRecord: models.BookmarkRecord{ID: "aaaaaaaaaaaa", Type: models.BookmarkTypeQuery, Title: "Recent", URI: "place:sort=8&maxResults=10"}},
+			{BookmarkRecord: models.BookmarkRecord{ID: "bbbbbbbbbbbb", Type: models.BookmarkTypeLivemark, Title: "News", FeedURI: "https://example.com/feed.xml", SiteURI: "https://example.com/"}},
+			{BookmarkRecord: models.BookmarkRecord{ID: "cccccccccccc", Type: models.BookmarkTypeMicroSummary, Title: "Status", URI: "https://example.com/status"}},
+		},
+	}
+
+	data, err := Format(nil, []*models.BookmarkTreeRecord{menu})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"name":"livemark/feedURI"`) {
+		t.Errorf("output is missing the livemark annotation: %s", data)
+	}
+
+	items, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || len(items[0].ResolvedChildren) != 3 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+
+	children := items[0].ResolvedChildren
+
+	if q := children[0]; q.Type != models.BookmarkTypeQuery || q.URI != "place:sort=8&maxResults=10" || q.FolderName != "" {
+		t.Errorf("query did not survive the roundtrip: %+v", q.BookmarkRecord)
+	}
+	if lm := children[1]; lm.Type != models.BookmarkTypeLivemark || lm.FeedURI != "https://example.com/feed.xml" || lm.SiteURI != "https://example.com/" || len(lm.ResolvedChildren) != 0 {
+		t.Errorf("livemark did not survive the roundtrip: %+v", lm.BookmarkRecord)
+	}
+	if ms := children[2]; ms.Type != models.BookmarkTypeBookmark || ms.URI != "https://example.com/status" {
+		t.Errorf("microsummary should be exported as a bookmark: %+v", ms.BookmarkRecord)
+	}
+}
@@ -0,0 +1,217 @@
+package firefoxjsonfmt
+
+import (
+	"encoding/json"
+	"ffsyncclient/cli"
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The json format of the firefox bookmark backups (bookmarkbackups/*.json and, mozLz4 compressed, *.jsonlz4)
+// https://searchfox.org/mozilla-central/source/toolkit/components/places/BookmarkJSONUtils.sys.mjs
+
+const (
+	TypeCodeBookmark  = 1
+	TypeCodeFolder    = 2
+	TypeCodeSeparator = 3
+)
+
+const (
+	typeBookmark  = "text/x-moz-place"
+	typeFolder    = "text/x-moz-place-container"
+	typeSeparator = "text/x-moz-place-separator"
+)
+
+type Node struct {
+	GUID         string  `json:"guid"`
+	Title        string  `json:"title"`
+	Index        int     `json:"index"`
+	DateAdded    int64   `json:"dateAdded"`    // microseconds
+	LastModified int64   `json:"lastModified"` // microseconds
+	ID           int     `json:"id"`
+	TypeCode     int     `json:"typeCode"`
+	Type         string  `json:"type"`
+	Root         string  `json:"root,omitempty"`
+	URI          string  `json:"uri,omitempty"`
+	Tags         string  `json:"tags,omitempty"`
+	Keyword      string  `json:"keyword,omitempty"`
+	Annos        []Anno  `json:"annos,omitempty"`
+	Children     []*Node `json:"children,omitempty"`
+}
+
+// Anno is an item annotation, only used for livemarks (older firefox versions)
+type Anno struct {
+	Name    string `json:"name"`
+	Flags   int    `json:"flags"`
+	Expires int    `json:"expires"`
+	Value   any    `json:"value"`
+}
+
+const (
+	annoFeedURI = "livemark/feedURI"
+	annoSiteURI = "livemark/siteURI"
+
+	annoExpireNever = 4
+)
+
+type rootInfo struct {
+	SyncID string
+	GUID   string
+	Root   string
+	Title  string
+}
+
+// the places roots with their IDs in sync, their GUIDs in firefox and their names in the backup file
+var roots = []rootInfo{
+	{SyncID: consts.BookmarkIDPlaces, GUID: "root________", Root: "placesRoot", Title: ""},
+	{SyncID: consts.BookmarkIDMenu, GUID: "menu________", Root: "bookmarksMenuFolder", Title: "menu"},
+	{SyncID: consts.BookmarkIDToolbar, GUID: "toolbar_____", Root: "toolbarFolder", Title: "toolbar"},
+	{SyncID: consts.BookmarkIDUnfiled, GUID: "unfiled_____", Root: "unfiledBookmarksFolder", Title: "unfiled"},
+	{SyncID: consts.BookmarkIDMobile, GUID: "mobile______", Root: "mobileFolder", Title: "mobile"},
+}
+
+func rootBySyncID(id string) (rootInfo, bool) {
+	for _, v := range roots {
+		if v.SyncID == id {
+			return v, true
+		}
+	}
+	return rootInfo{}, false
+}
+
+// Format creates a firefox bookmark backup (json) from the bookmark tree
+// Entries in the records list that are not a known root (menu, toolbar, unfiled, mobile) are placed in the unfiled root.
+func Format(ctx *cli.FFSContext, records []*models.BookmarkTreeRecord) ([]byte, error) {
+	f := &formatter{ctx: ctx, nextID: 1}
+
+	placesRoot, _ := rootBySyncID(consts.BookmarkIDPlaces)
+
+	result := f.newRootNode(placesRoot, nil)
+
+	rootNodes := make(map[string]*Node)
+	orphans := make([]*models.BookmarkTreeRecord, 0)
+
+	for _, v := range records {
+		if v.ID == placesRoot.SyncID {
+			continue
+		}
+		if info, ok := rootBySyncID(v.ID); ok {
+			node := f.newRootNode(info, v)
+			for _, child := range v.ResolvedChildren {
+				f.appendChild(node, child)
+			}
+			rootNodes[info.SyncID] = node
+		} else {
+			orphans = append(orphans, v)
+		}
+	}
+
+	if len(orphans) > 0 {
+		if _, ok := rootNodes[consts.BookmarkIDUnfiled]; !ok {
+			info, _ := rootBySyncID(consts.BookmarkIDUnfiled)
+			rootNodes[consts.BookmarkIDUnfiled] = f.newRootNode(info, nil)
+		}
+		for _, v := range orphans {
+			ctx.PrintVerbose(fmt.Sprintf("Place root-record %s in unfiled", v.ID))
+			f.appendChild(rootNodes[consts.BookmarkIDUnfiled], v)
+		}
+	}
+
+	for _, info := range roots {
+		if node, ok := rootNodes[info.SyncID]; ok {
+			node.Index = len(result.Children)
+			result.Children = append(result.Children, node)
+		}
+	}
+
+	return json.Marshal(result)
+}
+
+type formatter struct {
+	ctx    *cli.FFSContext
+	nextID int
+}
+
+func (f *formatter) newRootNode(info rootInfo, record *models.BookmarkTreeRecord) *Node {
+	node := &Node{
+		GUID:     info.GUID,
+		Title:    info.Title,
+		ID:       f.nextID,
+		TypeCode: TypeCodeFolder,
+		Type:     typeFolder,
+		Root:     info.Root,
+		Children: make([]*Node, 0),
+	}
+	f.nextID++
+
+	if record != nil {
+		node.DateAdded, node.LastModified = fmtDate(record.DateAdded), fmtDate(record.DateAdded)
+	}
+
+	return node
+}
+
+func (f *formatter) appendChild(parent *Node, item *models.BookmarkTreeRecord) {
+	node := &Node{
+		GUID:         item.ID,
+		Title:        item.Title,
+		Index:        len(parent.Children),
+		DateAdded:    fmtDate(item.DateAdded),
+		LastModified: fmtDate(item.DateAdded),
+		ID:           f.nextID,
+	}
+
+	switch item.Type {
+	case models.BookmarkTypeBookmark, models.BookmarkTypeMicroSummary, models.BookmarkTypeQuery:
+		node.TypeCode = TypeCodeBookmark
+		node.Type = typeBookmark
+		node.URI = item.URI
+		node.Tags = strings.Join(item.Tags, ",")
+		node.Keyword = item.Keyword
+
+	case models.BookmarkTypeFolder:
+		node.TypeCode = TypeCodeFolder
+		node.Type = typeFolder
+		node.Children = make([]*Node, 0, len(item.ResolvedChildren))
+
+	case models.BookmarkTypeLivemark:
+		// the entries of a livemark are loaded from the feed, they are never part of the backup
+		node.TypeCode = TypeCodeFolder
+		node.Type = typeFolder
+		node.Annos = []Anno{{Name: annoFeedURI, Expires: annoExpireNever, Value: item.FeedURI}}
+		if item.SiteURI != "" {
+			node.Annos = append(node.Annos, Anno{Name: annoSiteURI, Expires: annoExpireNever, Value: item.SiteURI})
+		}
+		node.Children = make([]*Node, 0)
+		f.nextID++
+		parent.Children = append(parent.Children, node)
+		return
+
+	case models.BookmarkTypeSeparator:
+		node.TypeCode = TypeCodeSeparator
+		node.Type = typeSeparator
+
+	default:
+		f.ctx.PrintVerbose(fmt.Sprintf("[WARN] skip item of type %v in firefox-json-formatter", item.Type))
+		return
+	}
+
+	f.nextID++
+	parent.Children = append(parent.Children, node)
+
+	if node.TypeCode == TypeCodeFolder {
+		for _, child := range item.ResolvedChildren {
+			f.appendChild(node, child)
+		}
+	}
+}
+
+func fmtDate(d *time.Time) int64 {
+	if d == nil {
+		return 0
+	}
+	return d.UnixMicro()
+}
@@ -0,0 +1,154 @@
+package firefoxjsonfmt
+
+import (
+	"encoding/json"
+	"errors"
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Parse reads a firefox bookmark backup (json, use mozlz4.Decompress for *.jsonlz4 files)
+//
+// Returns the roots (menu, toolbar, unfiled, mobile) as folders with their sync-IDs (e.g. consts.BookmarkIDMenu),
+// all other entries have no ID and are contained in the ResolvedChildren of their folder.
+// If the file does not contain the placesRoot (e.g. a single folder) the children of the top-level folder are returned.
+// Bookmarks with a `place:` URI are returned as queries, folders with a livemark annotation as livemarks.
+func Parse(data []byte) ([]*models.BookmarkTreeRecord, error) {
+	var top Node
+	err := json.Unmarshal(data, &top)
+	if err != nil {
+		return nil, err
+	}
+
+	if nodeTypeCode(&top) != TypeCodeFolder {
+		return nil, errors.New("not a firefox bookmark backup (top-level entry is not a folder)")
+	}
+
+	result := make([]*models.BookmarkTreeRecord, 0, len(top.Children))
+
+	for _, child := range sortedChildren(&top) {
+		item, ok := parseNode(child)
+		if !ok {
+			continue
+		}
+		if top.Root == "placesRoot" {
+			if info, ok := rootByNode(child); ok {
+				item.ID = info.SyncID
+			}
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+func rootByNode(node *Node) (rootInfo, bool) {
+	for _, v := range roots {
+		if node.GUID == v.GUID || node.Root == v.Root {
+			return v, true
+		}
+	}
+	if node.Root == "menuFolder" { // used by older versions
+		return rootBySyncID(consts.BookmarkIDMenu)
+	}
+	return rootInfo{}, false
+}
+
+func parseNode(node *Node) (*models.BookmarkTreeRecord, bool) {
+	item := &models.BookmarkTreeRecord{
+		BookmarkRecord: models.BookmarkRecord{
+			Title:     node.Title,
+			DateAdded: parseDate(node.DateAdded, node.LastModified),
+		},
+		ResolvedChildren: make([]*models.BookmarkTreeRecord, 0),
+	}
+
+	switch nodeTypeCode(node) {
+	case TypeCodeBookmark:
+		item.Type = models.BookmarkTypeBookmark
+		item.URI = node.URI
+		if models.IsQueryURI(node.URI) {
+			item.Type = models.BookmarkTypeQuery
+			item.FolderName = models.QueryFolderName(node.URI)
+		}
+		item.Keyword = node.Keyword
+		item.Tags = make([]string, 0)
+		for _, tag := range strings.Split(node.Tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				item.Tags = append(item.Tags, tag)
+			}
+		}
+		return item, true
+
+	case TypeCodeFolder:
+		if feed, ok := nodeAnno(node, annoFeedURI); ok {
+			item.Type = models.BookmarkTypeLivemark
+			item.FeedURI = feed
+			item.SiteURI, _ = nodeAnno(node, annoSiteURI)
+			return item, true
+		}
+
+		item.Type = models.BookmarkTypeFolder
+		for _, child := range sortedChildren(node) {
+			if childItem, ok := parseNode(child); ok {
+				item.ResolvedChildren = append(item.ResolvedChildren, childItem)
+			}
+		}
+		return item, true
+
+	case TypeCodeSeparator:
+		item.Type = models.BookmarkTypeSeparator
+		return item, true
+
+	default:
+		return nil, false
+	}
+}
+
+func nodeTypeCode(node *Node) int {
+	if node.TypeCode != 0 {
+		return node.TypeCode
+	}
+	switch node.Type {
+	case typeBookmark:
+		return TypeCodeBookmark
+	case typeFolder:
+		return TypeCodeFolder
+	case typeSeparator:
+		return TypeCodeSeparator
+	default:
+		return 0
+	}
+}
+
+func nodeAnno(node *Node, name string) (string, bool) {
+	for _, v := range node.Annos {
+		if v.Name == name {
+			if str, ok := v.Value.(string); ok {
+				return str, true
+			}
+		}
+	}
+	return "", false
+}
+
+func sortedChildren(node *Node) []*Node {
+	children := make([]*Node, len(node.Children))
+	copy(children, node.Children)
+	sort.SliceStable(children, func(i, j int) bool { return children[i].Index < children[j].Index })
+	return children
+}
+
+func parseDate(dateAdded int64, lastModified int64) *time.Time {
+	if dateAdded > 0 {
+		return langext.Ptr(time.UnixMicro(dateAdded))
+	}
+	if lastModified > 0 {
+		return langext.Ptr(time.UnixMicro(lastModified))
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ require (
 	github.com/joomcode/errorx v1.2.0
 	github.com/zenazn/pkcs7pad v0.0.0-20170308005700-253a5b1f0e03
 	golang.org/x/crypto v0.37.0
+	golang.org/x/net v0.39.0
 	golang.org/x/term v0.31.0
 )
 
@@ -38,7 +39,6 @@ require (
 	github.com/ugorji/go/codec v1.2.12 // indirect
 	go.mongodb.org/mongo-driver v1.17.3 // indirect
 	golang.org/x/arch v0.16.0 // indirect
-	golang.org/x/net v0.39.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
 	golang.org/x/text v0.24.0 // indirect
@@ -0,0 +1,98 @@
+package historyfilter
+
+import (
+	"ffsyncclient/models"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Filter selects history records (by their page) and their visits (by date and transition type)
+// Empty fields do not filter, multiple domains or transitions are combined with OR.
+type Filter struct {
+	URLRegex      *regexp.Regexp
+	TitleRegex    *regexp.Regexp
+	Domains       []string
+	VisitedAfter  *time.Time // inclusive
+	VisitedBefore *time.Time // exclusive
+	Transitions   []models.HistoryTransitionType
+
+	LastVisitBefore *time.Time // exclusive, matches pages whose visits are all before this time (pages without visits never match)
+}
+
+// HasVisitFilter returns true if the filter selects specific visits
+func (f Filter) HasVisitFilter() bool {
+	return f.VisitedAfter != nil || f.VisitedBefore != nil || len(f.Transitions) > 0
+}
+
+// Apply returns the record with only the matching visits, and false if the record does not match
+// If the filter selects specific visits (see HasVisitFilter), records without matching visits do not match.
+// Deleted records never match.
+func (f Filter) Apply(rec models.HistoryRecord) (models.HistoryRecord, bool) {
+	if rec.Deleted {
+		return models.HistoryRecord{}, false
+	}
+
+	if f.URLRegex != nil && !f.URLRegex.MatchString(rec.URI) {
+		return models.HistoryRecord{}, false
+	}
+	if f.TitleRegex != nil && !f.TitleRegex.MatchString(rec.Title) {
+		return models.HistoryRecord{}, false
+	}
+
+	if len(f.Domains) > 0 {
+		host := Domain(rec.URI)
+		if !slices.ContainsFunc(f.Domains, func(d string) bool { return MatchDomain(host, d) }) {
+			return models.HistoryRecord{}, false
+		}
+	}
+
+	if f.LastVisitBefore != nil && (len(rec.Visits) == 0 || slices.ContainsFunc(rec.Visits, func(v models.HistoryVisit) bool { return !v.VisitDate.Before(*f.LastVisitBefore) })) {
+		return models.HistoryRecord{}, false
+	}
+
+	if !f.HasVisitFilter() {
+		return rec, true
+	}
+
+	visits := make([]models.HistoryVisit, 0, len(rec.Visits))
+	for _, v := range rec.Visits {
+		if f.VisitedAfter != nil && v.VisitDate.Before(*f.VisitedAfter) {
+			continue
+		}
+		if f.VisitedBefore != nil && !v.VisitDate.Before(*f.VisitedBefore) {
+			continue
+		}
+		if len(f.Transitions) > 0 && !slices.Contains(f.Transitions, v.TransitionType) {
+			continue
+		}
+		visits = append(visits, v)
+	}
+
+	if len(visits) == 0 {
+		return models.HistoryRecord{}, false
+	}
+
+	rec.Visits = visits
+	return rec, true
+}
+
+// Domain returns the (lowercase) host of the URL without port, or an empty string if it has none (e.g. `about:` URLs)
+func Domain(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+// MatchDomain returns true if the host is the domain or one of its subdomains
+func MatchDomain(host string, domain string) bool {
+	domain = strings.TrimPrefix(strings.TrimSuffix(strings.ToLower(domain), "."), ".")
+	if host == "" || domain == "" {
+		return false
+	}
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
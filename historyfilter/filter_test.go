@@ -0,0 +1,125 @@
+package historyfilter
+
+import (
+	"ffsyncclient/models"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func visit(day int, hour int, t models.HistoryTransitionType) models.HistoryVisit {
+	return models.HistoryVisit{TransitionType: t, VisitDate: time.Date(2024, 3, day, hour, 0, 0, 0, time.UTC)}
+}
+
+func testRecords() []models.HistoryRecord {
+	return []models.HistoryRecord{
+		{ID: "a", URI: "https://www.Example.com:8443/docs", Title: "Example Docs", Visits: []models.HistoryVisit{
+			visit(1, 10, models.HistoryTransitionTypeTyped),
+			visit(2, 23, models.HistoryTransitionTypeLink),
+			visit(3, 9, models.HistoryTransitionTypeLink),
+		}},
+		{ID: "b", URI: "https://example.com/", Title: "Example", Visits: []models.HistoryVisit{
+			visit(2, 8, models.HistoryTransitionTypeBookmark),
+		}},
+		{ID: "c", URI: "https://notexample.com/", Title: "Other", Visits: []models.HistoryVisit{
+			visit(1, 12, models.HistoryTransitionTypeTyped),
+		}},
+		{ID: "d", URI: "about:config", Title: "Config", Visits: []models.HistoryVisit{}},
+		{ID: "e", URI: "https://example.com/deleted", Deleted: true},
+	}
+}
+
+func apply(f Filter) []models.HistoryRecord {
+	r := make([]models.HistoryRecord, 0)
+	for _, v := range testRecords() {
+		if m, ok := f.Apply(v); ok {
+			r = append(r, m)
+		}
+	}
+	return r
+}
+
+func ids(records []models.HistoryRecord) string {
+	r := ""
+	for _, v := range records {
+		r += v.ID
+	}
+	return r
+}
+
+func TestDomain(t *testing.T) {
+	if v := Domain("https://www.Example.com:8443/docs"); v != "www.example.com" {
+		t.Errorf("unexpected domain %q", v)
+	}
+	if v := Domain("about:config"); v != "" {
+		t.Errorf("unexpected domain %q", v)
+	}
+	if !MatchDomain("www.example.com", "Example.com") || MatchDomain("notexample.com", "example.com") || MatchDomain("", "") {
+		t.Errorf("unexpected domain match")
+	}
+}
+
+func TestFilterPages(t *testing.T) {
+	if v := ids(apply(Filter{})); v != "abcd" {
+		t.Errorf("unexpected result %s", v)
+	}
+	if v := ids(apply(Filter{Domains: []string{"example.com"}})); v != "ab" {
+		t.Errorf("unexpected result %s", v)
+	}
+	if v := ids(apply(Filter{URLRegex: regexp.MustCompile(`/docs$`)})); v != "a" {
+		t.Errorf("unexpected result %s", v)
+	}
+	if v := ids(apply(Filter{TitleRegex: regexp.MustCompile(`(?i)^example`), Domains: []string{"notexample.com", "www.example.com"}})); v != "a" {
+		t.Errorf("unexpected result %s", v)
+	}
+
+	before := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	if r := apply(Filter{LastVisitBefore: &before}); ids(r) != "bc" || len(r[0].Visits) != 1 {
+		t.Errorf("unexpected result %s", ids(r))
+	}
+}
+
+func TestFilterVisits(t *testing.T) {
+	after := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	r := apply(Filter{VisitedAfter: &after, VisitedBefore: &before})
+	if v := ids(r); v != "ab" {
+		t.Fatalf("unexpected result %s", v)
+	}
+	if len(r[0].Visits) != 1 || r[0].Visits[0].TransitionType != models.HistoryTransitionTypeLink {
+		t.Errorf("unexpected visits %v", r[0].Visits)
+	}
+
+	r = apply(Filter{Transitions: []models.HistoryTransitionType{models.HistoryTransitionTypeTyped}})
+	if v := ids(r); v != "ac" {
+		t.Errorf("unexpected result %s", v)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	stats := Aggregate(apply(Filter{}), time.FixedZone("UTC+2", 2*60*60))
+
+	if stats.Pages != 3 || stats.Visits != 5 {
+		t.Errorf("unexpected totals %d %d", stats.Pages, stats.Visits)
+	}
+
+	if len(stats.Domains) != 3 || stats.Domains[0] != (Count{Key: "www.example.com", Visits: 3, Pages: 1}) {
+		t.Errorf("unexpected domains %v", stats.Domains)
+	}
+
+	// 2024-03-02 23:00 UTC is already 2024-03-03 in UTC+2
+	expectedDays := []Count{{"2024-03-01", 2, 2}, {"2024-03-02", 1, 1}, {"2024-03-03", 2, 1}}
+	if len(stats.Days) != len(expectedDays) {
+		t.Fatalf("unexpected days %v", stats.Days)
+	}
+	for i, v := range expectedDays {
+		if stats.Days[i] != v {
+			t.Errorf("unexpected day %v (expected %v)", stats.Days[i], v)
+		}
+	}
+
+	if stats.Transitions[0] != (Count{Key: "LINK", Visits: 2, Pages: 1}) || stats.Transitions[1] != (Count{Key: "TYPED", Visits: 2, Pages: 2}) {
+		t.Errorf("unexpected transitions %v", stats.Transitions)
+	}
+}
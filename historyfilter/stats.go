@@ -0,0 +1,99 @@
+package historyfilter
+
+import (
+	"ffsyncclient/models"
+	"sort"
+	"time"
+)
+
+type Count struct {
+	Key    string
+	Visits int
+	Pages  int // the number of distinct pages (records) with visits
+}
+
+type Stats struct {
+	Pages       int
+	Visits      int
+	Domains     []Count // sorted by visits (descending)
+	Days        []Count // sorted by day (ascending), in the format YYYY-MM-DD
+	Transitions []Count // sorted by visits (descending)
+}
+
+// Aggregate counts the visits of the records per domain, per day (in the timezone `loc`) and per transition type
+func Aggregate(records []models.HistoryRecord, loc *time.Location) Stats {
+	domains := newCounter()
+	days := newCounter()
+	transitions := newCounter()
+
+	stats := Stats{}
+
+	for _, rec := range records {
+		if len(rec.Visits) == 0 {
+			continue
+		}
+
+		stats.Pages++
+		stats.Visits += len(rec.Visits)
+
+		domains.add(rec.ID, Domain(rec.URI))
+		for _, v := range rec.Visits {
+			domains.visit(Domain(rec.URI))
+			days.add(rec.ID, v.VisitDate.In(loc).Format("2006-01-02"))
+			days.visit(v.VisitDate.In(loc).Format("2006-01-02"))
+			transitions.add(rec.ID, v.TransitionType.ConstantString())
+			transitions.visit(v.TransitionType.ConstantString())
+		}
+	}
+
+	stats.Domains = domains.sorted(false)
+	stats.Days = days.sorted(true)
+	stats.Transitions = transitions.sorted(false)
+
+	return stats
+}
+
+type counter struct {
+	counts map[string]*Count
+	pages  map[string]map[string]bool
+}
+
+func newCounter() *counter {
+	return &counter{counts: make(map[string]*Count), pages: make(map[string]map[string]bool)}
+}
+
+func (c *counter) get(key string) *Count {
+	if v, ok := c.counts[key]; ok {
+		return v
+	}
+	c.counts[key] = &Count{Key: key}
+	c.pages[key] = make(map[string]bool)
+	return c.counts[key]
+}
+
+// add counts the page for the key (only once per page)
+func (c *counter) add(page string, key string) {
+	v := c.get(key)
+	if !c.pages[key][page] {
+		c.pages[key][page] = true
+		v.Pages++
+	}
+}
+
+func (c *counter) visit(key string) {
+	c.get(key).Visits++
+}
+
+func (c *counter) sorted(byKey bool) []Count {
+	result := make([]Count, 0, len(c.counts))
+	for _, v := range c.counts {
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !byKey && result[i].Visits != result[j].Visits {
+			return result[i].Visits > result[j].Visits
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
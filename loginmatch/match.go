@@ -0,0 +1,152 @@
+package loginmatch
+
+import (
+	"sort"
+	"time"
+)
+
+// Specificity ranks how well a login matches a queried origin (higher is better)
+// Loosely follows the login-manager of firefox (LoginHelper.sys.mjs)
+type Specificity int
+
+const (
+	NoMatch            Specificity = 0
+	MatchBaseDomain    Specificity = 10 // same registrable domain, e.g. `login.example.com` for `www.example.com`
+	MatchSubdomain     Specificity = 20 // one host is a subdomain of the other, e.g. `example.com` for `www.example.com`
+	MatchHost          Specificity = 30 // same host, but a different port or scheme
+	MatchSchemeUpgrade Specificity = 40 // `http://` login for an `https://` origin
+	MatchExact         Specificity = 50 // same scheme, host and port
+)
+
+type Login struct {
+	Hostname      string
+	FormSubmitURL string
+	HTTPRealm     *string
+	LastUsed      time.Time
+}
+
+type Query struct {
+	Origin        Origin
+	FormSubmitURL *string
+	HTTPRealm     *string
+}
+
+type Result struct {
+	Index       int
+	Specificity Specificity
+}
+
+func MatchOrigin(query Origin, login Origin) Specificity {
+	if query.Host == login.Host {
+		if query.Scheme == login.Scheme && query.EffectivePort() == login.EffectivePort() {
+			return MatchExact
+		}
+		if isSchemeUpgrade(query, login) {
+			return MatchSchemeUpgrade
+		}
+		return MatchHost
+	}
+
+	if query.Scheme != login.Scheme && !isSchemeUpgrade(query, login) {
+		return NoMatch
+	}
+	if query.EffectivePort() != login.EffectivePort() && !isSchemeUpgrade(query, login) {
+		return NoMatch
+	}
+
+	qbd, ok := query.BaseDomain()
+	if !ok {
+		return NoMatch
+	}
+	lbd, ok := login.BaseDomain()
+	if !ok {
+		return NoMatch
+	}
+	if qbd != lbd {
+		return NoMatch
+	}
+
+	if query.IsSubdomainOf(login) || login.IsSubdomainOf(query) {
+		return MatchSubdomain
+	}
+
+	return MatchBaseDomain
+}
+
+// Match returns the specificity of the login for the query, or NoMatch if the login must not be used for it
+//
+// If the query specifies a httpRealm only logins with the same realm are matched.
+// If the query specifies a formSubmitURL, logins with a different form-action origin are not matched,
+// logins with an identical form-action origin are ranked above logins without one.
+func Match(query Query, login Login) Specificity {
+	lo, err := ParseOrigin(login.Hostname)
+	if err != nil {
+		return NoMatch
+	}
+
+	spec := MatchOrigin(query.Origin, lo)
+	if spec == NoMatch {
+		return NoMatch
+	}
+
+	if query.HTTPRealm != nil {
+		if login.HTTPRealm == nil || *login.HTTPRealm != *query.HTTPRealm {
+			return NoMatch
+		}
+	}
+
+	if query.FormSubmitURL != nil && login.FormSubmitURL != "" && login.FormSubmitURL != "javascript:" {
+		qfo, err := ParseOrigin(*query.FormSubmitURL)
+		if err != nil {
+			return NoMatch
+		}
+		lfo, err := ParseOrigin(login.FormSubmitURL)
+		if err != nil {
+			return NoMatch
+		}
+		fspec := MatchOrigin(qfo, lfo)
+		if fspec < MatchSchemeUpgrade {
+			return NoMatch
+		}
+		spec += 1 // rank logins with a matching form-action above logins without one
+	}
+
+	return spec
+}
+
+// Rank returns all matching logins, the most specific first (ties are ordered by last usage)
+func Rank(query Query, logins []Login) []Result {
+	result := make([]Result, 0)
+
+	for i, l := range logins {
+		if spec := Match(query, l); spec != NoMatch {
+			result = append(result, Result{Index: i, Specificity: spec})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Specificity != result[j].Specificity {
+			return result[i].Specificity > result[j].Specificity
+		}
+		return logins[result[i].Index].LastUsed.After(logins[result[j].Index].LastUsed)
+	})
+
+	return result
+}
+
+// Best returns the results with the highest specificity (that is at least `min`), the results must be ordered by Rank
+// An exact match hides scheme-upgrade and other-port matches, so multiple results are only returned if they are equally specific.
+func Best(results []Result, min Specificity) []Result {
+	best := make([]Result, 0)
+	for _, r := range results {
+		if r.Specificity < min || (len(best) > 0 && r.Specificity != best[0].Specificity) {
+			break
+		}
+		best = append(best, r)
+	}
+	return best
+}
+
+func isSchemeUpgrade(query Origin, login Origin) bool {
+	return query.Scheme == "https" && login.Scheme == "http" && query.EffectivePort() == "443" && login.EffectivePort() == "80"
+}
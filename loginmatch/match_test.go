@@ -0,0 +1,167 @@
+package loginmatch
+
+import (
+	"testing"
+	"time"
+)
+
+func mustOrigin(t *testing.T, v string) Origin {
+	o, err := ParseOrigin(v)
+	if err != nil {
+		t.Fatalf("ParseOrigin(%q) failed: %v", v, err)
+	}
+	return o
+}
+
+func TestParseOrigin(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com":          "https://example.com",
+		"example.com":                  "https://example.com",
+		"HTTP://Example.COM/some/path": "http://example.com",
+		"https://example.com:443":      "https://example.com",
+		"https://example.com:8443/x":   "https://example.com:8443",
+		"https://bücher.de":            "https://xn--bcher-kva.de",
+		"https://example.com.":         "https://example.com",
+		"http://[::1]:8080":            "http://[::1]:8080",
+	}
+	for in, expected := range cases {
+		if got := mustOrigin(t, in).String(); got != expected {
+			t.Errorf("ParseOrigin(%q) = %q, expected %q", in, got, expected)
+		}
+	}
+
+	if _, err := ParseOrigin(""); err == nil {
+		t.Error("ParseOrigin should fail for an empty string")
+	}
+}
+
+func TestBaseDomain(t *testing.T) {
+	cases := map[string]string{
+		"https://www.example.com":     "example.com",
+		"https://a.b.example.co.uk":   "example.co.uk",
+		"https://user.github.io":      "user.github.io",
+		"https://foo.user.github.io":  "user.github.io",
+		"https://xn--bcher-kva.de":    "xn--bcher-kva.de",
+		"https://login.bücher.de/abc": "xn--bcher-kva.de",
+	}
+	for in, expected := range cases {
+		got, ok := mustOrigin(t, in).BaseDomain()
+		if !ok || got != expected {
+			t.Errorf("BaseDomain(%q) = (%q, %v), expected %q", in, got, ok, expected)
+		}
+	}
+
+	for _, in := range []string{"https://co.uk", "https://github.io", "http://127.0.0.1"} {
+		if got, ok := mustOrigin(t, in).BaseDomain(); ok {
+			t.Errorf("BaseDomain(%q) should fail, got %q", in, got)
+		}
+	}
+}
+
+func TestMatchOrigin(t *testing.T) {
+	cases := []struct {
+		query    string
+		login    string
+		expected Specificity
+	}{
+		{"https://github.com", "https://github.com", MatchExact},
+		{"github.com", "https://github.com", MatchExact},
+		{"https://github.com", "http://github.com", MatchSchemeUpgrade},
+		{"http://github.com", "https://github.com", MatchHost},
+		{"https://github.com", "https://github.com:8443", MatchHost},
+		{"https://www.github.com", "https://github.com", MatchSubdomain},
+		{"https://github.com", "https://gist.github.com", MatchSubdomain},
+		{"https://api.github.com", "https://gist.github.com", MatchBaseDomain},
+		{"https://www.github.com", "http://github.com", MatchSubdomain},
+		{"https://github.com", "https://gitlab.com", NoMatch},
+		{"https://alice.github.io", "https://bob.github.io", NoMatch},
+		{"https://example.co.uk", "https://other.co.uk", NoMatch},
+		{"https://www.example.com", "ftp://example.com", NoMatch},
+		{"https://www.example.com", "https://example.com:8443", NoMatch},
+	}
+	for _, c := range cases {
+		if got := MatchOrigin(mustOrigin(t, c.query), mustOrigin(t, c.login)); got != c.expected {
+			t.Errorf("MatchOrigin(%q, %q) = %d, expected %d", c.query, c.login, got, c.expected)
+		}
+	}
+}
+
+func TestMatchRealmAndFormSubmitURL(t *testing.T) {
+	realm := "Restricted"
+	other := "Other"
+	form := "https://github.com/session"
+
+	q := Query{Origin: mustOrigin(t, "https://github.com"), HTTPRealm: &realm}
+	if Match(q, Login{Hostname: "https://github.com", HTTPRealm: &realm}) != MatchExact {
+		t.Error("login with the same realm should match")
+	}
+	if Match(q, Login{Hostname: "https://github.com", HTTPRealm: &other}) != NoMatch {
+		t.Error("login with a different realm should not match")
+	}
+	if Match(q, Login{Hostname: "https://github.com"}) != NoMatch {
+		t.Error("form login should not match a realm query")
+	}
+
+	q = Query{Origin: mustOrigin(t, "https://github.com"), FormSubmitURL: &form}
+	if Match(q, Login{Hostname: "https://github.com", FormSubmitURL: "https://github.com"}) != MatchExact+1 {
+		t.Error("login with the same form-action should be ranked above the plain origin match")
+	}
+	if Match(q, Login{Hostname: "https://github.com", FormSubmitURL: ""}) != MatchExact {
+		t.Error("login without form-action should match")
+	}
+	if Match(q, Login{Hostname: "https://github.com", FormSubmitURL: "https://evil.example"}) != NoMatch {
+		t.Error("login with a different form-action should not match")
+	}
+}
+
+func TestRank(t *testing.T) {
+	now := time.Now()
+
+	logins := []Login{
+		{Hostname: "https://gitlab.com"},
+		{Hostname: "https://gist.github.com"},
+		{Hostname: "http://github.com"},
+		{Hostname: "https://github.com", LastUsed: now.Add(-time.Hour)},
+		{Hostname: "https://github.com", LastUsed: now},
+	}
+
+	result := Rank(Query{Origin: mustOrigin(t, "github.com")}, logins)
+
+	expected := []int{4, 3, 2, 1}
+	if len(result) != len(expected) {
+		t.Fatalf("Rank returned %d results, expected %d", len(result), len(expected))
+	}
+	for i, idx := range expected {
+		if result[i].Index != idx {
+			t.Errorf("Rank result[%d] = %d, expected %d", i, result[i].Index, idx)
+		}
+	}
+}
+
+func TestBest(t *testing.T) {
+	logins := []Login{
+		{Hostname: "http://github.com:8080"},
+		{Hostname: "https://github.com"},
+		{Hostname: "https://gist.github.com"},
+	}
+
+	best := Best(Rank(Query{Origin: mustOrigin(t, "github.com")}, logins), MatchHost)
+	if len(best) != 1 || best[0].Index != 1 {
+		t.Errorf("expected only the exact match, got %v", best)
+	}
+
+	logins = append(logins, Login{Hostname: "https://github.com:443"})
+	best = Best(Rank(Query{Origin: mustOrigin(t, "github.com")}, logins), MatchHost)
+	if len(best) != 2 {
+		t.Errorf("expected both exact matches, got %v", best)
+	}
+
+	best = Best(Rank(Query{Origin: mustOrigin(t, "http://github.com")}, logins[:1]), MatchHost)
+	if len(best) != 1 || best[0].Specificity != MatchHost {
+		t.Errorf("expected the other-port match, got %v", best)
+	}
+
+	if best = Best(Rank(Query{Origin: mustOrigin(t, "www.github.com")}, logins), MatchHost); len(best) != 0 {
+		t.Errorf("expected no match above the minimum, got %v", best)
+	}
+}
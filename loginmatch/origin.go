@@ -0,0 +1,94 @@
+package loginmatch
+
+import (
+	"errors"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var schemeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*://`)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ftp":   "21",
+}
+
+type Origin struct {
+	Scheme string // lowercase, `https` if the input had no scheme
+	Host   string // lowercase and punycode-encoded (IDN)
+	Port   string // empty if the input had no explicit port
+}
+
+// ParseOrigin parses a login hostname or an url (`https://example.org`, `example.org:8080`, `https://bücher.de/path`)
+func ParseOrigin(v string) (Origin, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return Origin{}, errors.New("empty origin")
+	}
+
+	if !schemeRegex.MatchString(v) {
+		v = "https://" + v
+	}
+
+	u, err := url.Parse(v)
+	if err != nil {
+		return Origin{}, err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return Origin{}, errors.New("origin has no host")
+	}
+
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+
+	return Origin{
+		Scheme: strings.ToLower(u.Scheme),
+		Host:   host,
+		Port:   u.Port(),
+	}, nil
+}
+
+func (o Origin) EffectivePort() string {
+	if o.Port != "" {
+		return o.Port
+	}
+	return defaultPorts[o.Scheme]
+}
+
+func (o Origin) String() string {
+	host := o.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if o.Port != "" && o.Port != defaultPorts[o.Scheme] {
+		return o.Scheme + "://" + host + ":" + o.Port
+	}
+	return o.Scheme + "://" + host
+}
+
+// BaseDomain returns the registrable domain (eTLD+1) of the host, according to the public suffix list
+// Returns false for ip-addresses and hosts that are a public suffix themselves
+func (o Origin) BaseDomain() (string, bool) {
+	if net.ParseIP(o.Host) != nil {
+		return "", false
+	}
+
+	bd, err := publicsuffix.EffectiveTLDPlusOne(o.Host)
+	if err != nil {
+		return "", false
+	}
+
+	return bd, true
+}
+
+func (o Origin) IsSubdomainOf(other Origin) bool {
+	return strings.HasSuffix(o.Host, "."+other.Host)
+}
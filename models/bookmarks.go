@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"ffsyncclient/cli"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -57,6 +58,11 @@ type BookmarkCreatePayloadSchema struct {
 	SiteURI           *string   `json:"siteUri,omitempty"`       // [livemark]
 }
 
+type BookmarkTombstonePayloadSchema struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+}
+
 func (j BookmarkPayloadSchema) ToModel() BookmarkRecord {
 	return BookmarkRecord{
 		ID:                j.ID,
@@ -93,6 +99,24 @@ const (
 	BookmarkTypeSeparator    BookmarkType = "separator"
 )
 
+// IsQueryURI returns true for the URIs of firefox queries (smart bookmarks), e.g. `place:sort=8&maxResults=10`
+func IsQueryURI(uri string) bool {
+	return strings.HasPrefix(uri, "place:")
+}
+
+// QueryFolderName returns the `folderName` of a query
+// Firefox syncs the tag of a tag-query (e.g. `place:tag=go`) as its folderName, all other queries have none.
+func QueryFolderName(uri string) string {
+	if !IsQueryURI(uri) {
+		return ""
+	}
+	params, err := url.ParseQuery(strings.TrimPrefix(uri, "place:"))
+	if err != nil {
+		return ""
+	}
+	return params.Get("tag")
+}
+
 type BookmarkRecord struct {
 	ID                string
 	Deleted           bool
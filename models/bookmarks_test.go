@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decodeBookmark(t *testing.T, data string) BookmarkRecord {
+	var schema BookmarkPayloadSchema
+	if err := json.Unmarshal([]byte(data), &schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema.ToModel()
+}
+
+func TestQuerySchema(t *testing.T) {
+	bm := decodeBookmark(t, `{"id":"aaaaaaaaaaaa","type":"query","parentid":"menu","title":"Go","bmkUri":"place:tag=go","tags":[],"keyword":"","folderName":"go","queryId":"MostVisited"}`)
+
+	if bm.Type != BookmarkTypeQuery || bm.URI != "place:tag=go" || bm.FolderName != "go" || bm.QueryID != "MostVisited" {
+		t.Errorf("unexpected query: %+v", bm)
+	}
+
+	if !IsQueryURI(bm.URI) || IsQueryURI("https://example.com/") {
+		t.Error("IsQueryURI returned an unexpected result")
+	}
+	if v := QueryFolderName("place:tag=go%20lang&sort=1"); v != "go lang" {
+		t.Errorf("unexpected folderName: %q", v)
+	}
+	if v := QueryFolderName("place:sort=8&maxResults=10"); v != "" {
+		t.Errorf("unexpected folderName: %q", v)
+	}
+}
+
+func TestLivemarkSchema(t *testing.T) {
+	bm := decodeBookmark(t, `{"id":"bbbbbbbbbbbb","type":"livemark","parentid":"menu","title":"News","children":[],"feedUri":"https://example.com/feed.xml","siteUri":"https://example.com/"}`)
+
+	if bm.Type != BookmarkTypeLivemark || bm.FeedURI != "https://example.com/feed.xml" || bm.SiteURI != "https://example.com/" || len(bm.Children) != 0 {
+		t.Errorf("unexpected livemark: %+v", bm)
+	}
+
+	data, err := json.Marshal(BookmarkCreatePayloadSchema{ID: bm.ID, Type: string(bm.Type), Title: &bm.Title, FeedURI: &bm.FeedURI, SiteURI: &bm.SiteURI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decodeBookmark(t, string(data)).FeedURI != bm.FeedURI {
+		t.Errorf("livemark did not survive the roundtrip: %s", data)
+	}
+}
+
+func TestMicrosummarySchema(t *testing.T) {
+	bm := decodeBookmark(t, `{"id":"cccccccccccc","type":"microsummary","parentid":"menu","title":"Status","bmkUri":"https://example.com/status","generatorUri":"urn:gen","staticTitle":"Status (static)"}`)
+
+	if bm.Type != BookmarkTypeMicroSummary || bm.URI != "https://example.com/status" || bm.GeneratorUri != "urn:gen" || bm.StaticTitle != "Status (static)" {
+		t.Errorf("unexpected microsummary: %+v", bm)
+	}
+}
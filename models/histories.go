@@ -5,6 +5,8 @@ import (
 	"ffsyncclient/cli"
 	"fmt"
 	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -60,6 +62,16 @@ func (t HistoryTransitionType) ConstantString() string {
 	}
 }
 
+// ParseHistoryTransitionType parses a transition type from its constant name (e.g. `TYPED`, case-insensitive) or its numeric value
+func ParseHistoryTransitionType(v string) (HistoryTransitionType, bool) {
+	for t := HistoryTransitionTypeLink; t <= HistoryTransitionTypeReload; t++ {
+		if strings.EqualFold(v, t.ConstantString()) || v == strconv.Itoa(int(t)) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
 func (j HistoryPayloadSchema) ToModel() HistoryRecord {
 	visits := make([]HistoryVisit, 0, len(j.Visits))
 	for _, v := range j.Visits {
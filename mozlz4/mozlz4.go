@@ -0,0 +1,206 @@
+package mozlz4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// mozLz4 is the file format of firefox for compressed json files (e.g. bookmarkbackups/*.jsonlz4, sessionstore.jsonlz4)
+// It consists of a magic header, the uncompressed size (uint32, little-endian) and a single LZ4 block (without the LZ4 frame format)
+
+var magic = []byte("mozLz40\x00")
+
+const headerSize = 8 + 4
+
+// IsMozLz4 returns true if data starts with the mozLz4 magic header
+func IsMozLz4(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Compress wraps data into a mozLz4 container
+func Compress(data []byte) []byte {
+	result := make([]byte, headerSize, headerSize+len(data)+len(data)/255+16)
+	copy(result, magic)
+	binary.LittleEndian.PutUint32(result[8:], uint32(len(data)))
+
+	return compressBlock(result, data)
+}
+
+// Decompress reads a mozLz4 container and returns the uncompressed content
+func Decompress(data []byte) ([]byte, error) {
+	if !IsMozLz4(data) {
+		return nil, errors.New("missing mozLz4 magic header")
+	}
+	if len(data) < headerSize {
+		return nil, errors.New("truncated mozLz4 header")
+	}
+
+	size := binary.LittleEndian.Uint32(data[8:12])
+
+	// every input byte produces at most 255 output bytes, larger sizes are corrupt (and must not be preallocated)
+	if uint64(size) > 255*uint64(len(data)-headerSize) {
+		return nil, fmt.Errorf("invalid mozLz4 header: uncompressed size %d is too large for %d bytes of input", size, len(data)-headerSize)
+	}
+
+	return decompressBlock(data[headerSize:], int(size))
+}
+
+func decompressBlock(src []byte, size int) ([]byte, error) {
+	dst := make([]byte, 0, size)
+
+	i := 0
+	for i < len(src) {
+		token := src[i]
+		i++
+
+		litLen, n, err := readLength(src[i:], int(token>>4))
+		if err != nil {
+			return nil, err
+		}
+		i += n
+
+		if i+litLen > len(src) {
+			return nil, errors.New("lz4: literals exceed input")
+		}
+		if len(dst)+litLen > size {
+			return nil, fmt.Errorf("lz4: output exceeds the expected %d bytes", size)
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+
+		if i == len(src) {
+			break // the last sequence only contains literals
+		}
+
+		if i+2 > len(src) {
+			return nil, errors.New("lz4: truncated match offset")
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+
+		if offset == 0 || offset > len(dst) {
+			return nil, fmt.Errorf("lz4: invalid match offset %d", offset)
+		}
+
+		matchLen, n, err := readLength(src[i:], int(token&0x0F))
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		matchLen += 4
+
+		if len(dst)+matchLen > size {
+			return nil, fmt.Errorf("lz4: output exceeds the expected %d bytes", size)
+		}
+
+		// the match can overlap with the bytes it produces, so copy byte by byte
+		start := len(dst) - offset
+		for j := 0; j < matchLen; j++ {
+			dst = append(dst, dst[start+j])
+		}
+	}
+
+	if len(dst) != size {
+		return nil, fmt.Errorf("lz4: expected %d bytes, got %d", size, len(dst))
+	}
+
+	return dst, nil
+}
+
+func readLength(src []byte, v int) (int, int, error) {
+	if v != 15 {
+		return v, 0, nil
+	}
+
+	n := 0
+	for {
+		if n >= len(src) {
+			return 0, 0, errors.New("lz4: truncated length")
+		}
+		b := src[n]
+		n++
+		v += int(b)
+		if b != 255 {
+			return v, n, nil
+		}
+	}
+}
+
+const (
+	minMatch    = 4
+	lastLiteral = 5  // the last 5 bytes are always literals
+	mfLimit     = 12 // the last match must start at least 12 bytes before the end
+	maxOffset   = 65535
+	hashLog     = 16
+)
+
+func compressBlock(dst []byte, src []byte) []byte {
+	if len(src) < mfLimit+1 {
+		return appendSequence(dst, src, 0, 0)
+	}
+
+	table := make([]int, 1<<hashLog) // position+1 of the last occurrence of a hash
+
+	anchor := 0
+	limit := len(src) - mfLimit
+
+	for i := 0; i < limit; {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := (seq * 2654435761) >> (32 - hashLog)
+
+		ref := table[h] - 1
+		table[h] = i + 1
+
+		if ref < 0 || i-ref > maxOffset || binary.LittleEndian.Uint32(src[ref:]) != seq {
+			i++
+			continue
+		}
+
+		matchLen := minMatch
+		for i+matchLen < len(src)-lastLiteral && src[ref+matchLen] == src[i+matchLen] {
+			matchLen++
+		}
+
+		dst = appendSequence(dst, src[anchor:i], i-ref, matchLen)
+
+		i += matchLen
+		anchor = i
+	}
+
+	return appendSequence(dst, src[anchor:], 0, 0)
+}
+
+// appendSequence writes literals followed by a match (or only the literals if matchLen == 0)
+func appendSequence(dst []byte, literals []byte, offset int, matchLen int) []byte {
+	litLen := len(literals)
+
+	token := byte(min(litLen, 15) << 4)
+	if matchLen > 0 {
+		token |= byte(min(matchLen-minMatch, 15))
+	}
+
+	dst = append(dst, token)
+	if litLen >= 15 {
+		dst = appendLength(dst, litLen-15)
+	}
+	dst = append(dst, literals...)
+
+	if matchLen > 0 {
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(offset))
+		if matchLen-minMatch >= 15 {
+			dst = appendLength(dst, matchLen-minMatch-15)
+		}
+	}
+
+	return dst
+}
+
+func appendLength(dst []byte, v int) []byte {
+	for v >= 255 {
+		dst = append(dst, 255)
+		v -= 255
+	}
+	return append(dst, byte(v))
+}
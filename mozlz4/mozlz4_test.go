@@ -0,0 +1,80 @@
+package mozlz4
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestRoundtrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(42))
+
+	random := make([]byte, 100_000)
+	rnd.Read(random)
+
+	cases := map[string][]byte{
+		"empty":      {},
+		"short":      []byte("hello"),
+		"repetitive": []byte(strings.Repeat("abcabcabc", 10_000)),
+		"zeros":      make([]byte, 70_000),
+		"random":     random,
+		"json":       []byte(strings.Repeat(`{"guid":"menu________","title":"menu","typeCode":2,"children":[]},`, 500)),
+	}
+
+	for name, data := range cases {
+		compressed := Compress(data)
+		if !IsMozLz4(compressed) {
+			t.Errorf("[%s] missing magic header", name)
+		}
+
+		result, err := Decompress(compressed)
+		if err != nil {
+			t.Errorf("[%s] decompress failed: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(result, data) {
+			t.Errorf("[%s] roundtrip changed the data", name)
+		}
+	}
+
+	if len(Compress(cases["repetitive"])) > 2000 {
+		t.Errorf("repetitive data was not compressed")
+	}
+}
+
+func TestDecompressKnownBlock(t *testing.T) {
+	// "abcd" as literals, then a match with offset 4 and length 8, then "xyz!!" as last literals
+	block := []byte{0x44, 'a', 'b', 'c', 'd', 0x04, 0x00, 0x50, 'x', 'y', 'z', '!', '!'}
+	data := append([]byte("mozLz40\x00\x11\x00\x00\x00"), block...)
+
+	result, err := Decompress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result) != "abcdabcdabcdxyz!!" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+func TestDecompressInvalid(t *testing.T) {
+	if _, err := Decompress([]byte("not compressed")); err == nil {
+		t.Error("Decompress should fail without magic header")
+	}
+	if _, err := Decompress([]byte("mozLz40\x00\x10\x00\x00\x00\x00\x00\x05\x00")); err == nil {
+		t.Error("Decompress should fail for an invalid offset")
+	}
+}
+
+func TestDecompressBogusSize(t *testing.T) {
+	// a header that claims 4 GiB of uncompressed data must be rejected before anything is allocated
+	if _, err := Decompress([]byte("mozLz40\x00\xff\xff\xff\xff\x10a")); err == nil {
+		t.Error("Decompress should fail for a size that the input cannot produce")
+	}
+
+	// the block produces more bytes than the header announces
+	block := []byte{0x44, 'a', 'b', 'c', 'd', 0x04, 0x00, 0x50, 'x', 'y', 'z', '!', '!'}
+	if _, err := Decompress(append([]byte("mozLz40\x00\x08\x00\x00\x00"), block...)); err == nil {
+		t.Error("Decompress should fail if the output exceeds the announced size")
+	}
+}
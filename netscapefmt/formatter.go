@@ -26,41 +26,63 @@ func Format(ctx *cli.FFSContext, records []*models.BookmarkTreeRecord) string {
 
 	printer.appendLine("<DL><p>")
 	printer.inc()
+
+	// the menu (and all other non-root entries) are written directly into the top-level list
 	for _, v := range records {
-		if v.ID != consts.BookmarkIDToolbar {
+		if v.ID == consts.BookmarkIDPlaces || v.ID == consts.BookmarkIDToolbar || v.ID == consts.BookmarkIDUnfiled || v.ID == consts.BookmarkIDMobile {
+			continue
+		}
+		if v.ID == consts.BookmarkIDMenu || v.Type == models.BookmarkTypeFolder {
 			for _, child := range v.ResolvedChildren {
 				printItem(ctx, printer, child)
 			}
+		} else {
+			printItem(ctx, printer, v)
 		}
 	}
-	for _, v := range records {
-		if v.ID == consts.BookmarkIDToolbar {
-			itemstr := "<DT><H3"
-			if v.DateAdded != nil {
-				itemstr += fmt.Sprintf(" ADD_DATE=\"%d\"", v.DateAdded.Unix())
-				itemstr += fmt.Sprintf(" LAST_MODIFIED=\"%d\"", v.DateAdded.Unix())
-			}
-			itemstr += " PERSONAL_TOOLBAR_FOLDER=\"true\""
-			itemstr += fmt.Sprintf(">%s</H3>", escape("Bookmarks Toolbar"))
-			printer.appendLine(itemstr)
-			printer.appendLine("<DL><p>")
-			printer.inc()
-			for _, child := range v.ResolvedChildren {
-				printItem(ctx, printer, child)
+
+	// the other roots are written as (marked) folders
+	for _, rootID := range []string{consts.BookmarkIDToolbar, consts.BookmarkIDUnfiled, consts.BookmarkIDMobile} {
+		for _, v := range records {
+			if v.ID == rootID {
+				printRoot(ctx, printer, v)
 			}
-			printer.dec()
-			printer.appendLine("</DL><p>")
 		}
 	}
+
 	printer.dec()
 	printer.appendLine("</DL>")
 
 	return printer.String()
 }
 
+func printRoot(ctx *cli.FFSContext, printer *ncPrinter, root *models.BookmarkTreeRecord) {
+	itemstr := "<DT><H3"
+	if root.DateAdded != nil {
+		itemstr += fmt.Sprintf(" ADD_DATE=\"%d\"", root.DateAdded.Unix())
+		itemstr += fmt.Sprintf(" LAST_MODIFIED=\"%d\"", root.DateAdded.Unix())
+	}
+	switch root.ID {
+	case consts.BookmarkIDToolbar:
+		itemstr += " PERSONAL_TOOLBAR_FOLDER=\"true\""
+	case consts.BookmarkIDUnfiled:
+		itemstr += " UNFILED_BOOKMARKS_FOLDER=\"true\""
+	}
+	itemstr += fmt.Sprintf(">%s</H3>", escape(consts.BookmarkRootTitles[root.ID]))
+
+	printer.appendLine(itemstr)
+	printer.appendLine("<DL><p>")
+	printer.inc()
+	for _, child := range root.ResolvedChildren {
+		printItem(ctx, printer, child)
+	}
+	printer.dec()
+	printer.appendLine("</DL><p>")
+}
+
 func printItem(ctx *cli.FFSContext, printer *ncPrinter, item *models.BookmarkTreeRecord) {
 	switch item.Type {
-	case models.BookmarkTypeBookmark:
+	case models.BookmarkTypeBookmark, models.BookmarkTypeMicroSummary, models.BookmarkTypeQuery:
 		itemstr := "<DT><A"
 		itemstr += fmt.Sprintf(" HREF=\"%s\"", escape(item.URI))
 		if item.DateAdded != nil {
@@ -78,6 +100,20 @@ func printItem(ctx *cli.FFSContext, printer *ncPrinter, item *models.BookmarkTre
 		printer.appendLine(itemstr)
 		return
 
+	case models.BookmarkTypeLivemark:
+		// livemarks are written like firefox did before they were removed (the site as HREF, the feed as FEEDURL)
+		itemstr := "<DT><A"
+		itemstr += fmt.Sprintf(" FEEDURL=\"%s\"", escape(item.FeedURI))
+		itemstr += fmt.Sprintf(" HREF=\"%s\"", escape(item.SiteURI))
+		if item.DateAdded != nil {
+			itemstr += fmt.Sprintf(" ADD_DATE=\"%d\"", item.DateAdded.Unix())
+			itemstr += fmt.Sprintf(" LAST_MODIFIED=\"%d\"", item.DateAdded.Unix())
+		}
+		itemstr += fmt.Sprintf(">%s</A>", escape(item.Title))
+
+		printer.appendLine(itemstr)
+		return
+
 	case models.BookmarkTypeFolder:
 		itemstr := "<DT><H3"
 		if item.DateAdded != nil {
@@ -0,0 +1,208 @@
+package netscapefmt
+
+import (
+	"bytes"
+	"errors"
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"git.blackforestbytes.com/BlackForestBytes/goext/langext"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse reads a netscape bookmark file (as written by Format, or exported by firefox, chrome, edge, etc.)
+//
+// Returns the entries of the top-level <DL>, folders contain their entries in ResolvedChildren.
+// The folders marked with PERSONAL_TOOLBAR_FOLDER or UNFILED_BOOKMARKS_FOLDER get the ID consts.BookmarkIDToolbar or consts.BookmarkIDUnfiled,
+// all other entries have no ID.
+// If an entry has no ADD_DATE its LAST_MODIFIED date is used as DateAdded.
+// Links with a FEEDURL are parsed as livemarks, links to `place:` URIs as queries.
+func Parse(data []byte) ([]*models.BookmarkTreeRecord, error) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
+
+	root := &models.BookmarkTreeRecord{ResolvedChildren: make([]*models.BookmarkTreeRecord, 0)}
+
+	stack := make([]*models.BookmarkTreeRecord, 0) // open <DL> lists
+	foundList := false
+
+	var pendingFolder *models.BookmarkTreeRecord = nil // last <H3>, waiting for its <DL>
+	var lastItem *models.BookmarkTreeRecord = nil      // last <A> or <H3>, target of a following <DD>
+
+	var textTarget *string = nil // current <A>, <H3> or <DD> that collects its text content
+	var textEnd atom.Atom = 0
+
+	current := func() *models.BookmarkTreeRecord {
+		if len(stack) == 0 {
+			return root
+		}
+		return stack[len(stack)-1]
+	}
+
+	endText := func() {
+		if textTarget != nil {
+			*textTarget = strings.TrimSpace(*textTarget)
+		}
+		textTarget = nil
+		textEnd = 0
+	}
+
+	for {
+		tt := tokenizer.Next()
+
+		switch tt {
+		case html.ErrorToken:
+			if errors.Is(tokenizer.Err(), io.EOF) {
+				endText()
+				if !foundList {
+					return nil, errors.New("not a netscape bookmark file (no <DL> found)")
+				}
+				return root.ResolvedChildren, nil
+			}
+			return nil, tokenizer.Err()
+
+		case html.TextToken:
+			if textTarget != nil {
+				*textTarget += string(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			tok := tokenizer.Token()
+			if textTarget != nil && tok.DataAtom == textEnd {
+				endText()
+				continue
+			}
+			if tok.DataAtom == atom.Dl {
+				endText()
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				lastItem = nil
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := tokenizer.Token()
+
+			if textTarget != nil && textEnd == atom.Dd && tok.DataAtom != atom.Br {
+				endText() // <DD> has no closing tag, its text ends with the next element
+			}
+
+			switch tok.DataAtom {
+			case atom.Dl:
+				foundList = true
+				if pendingFolder != nil {
+					stack = append(stack, pendingFolder)
+				} else if len(stack) == 0 {
+					stack = append(stack, root)
+				} else {
+					// <DL> without a preceding <H3>, treat it as an anonymous folder
+					folder := newParsedItem(models.BookmarkTypeFolder)
+					current().ResolvedChildren = append(current().ResolvedChildren, folder)
+					stack = append(stack, folder)
+				}
+				pendingFolder = nil
+				lastItem = nil
+
+			case atom.A:
+				item := newParsedItem(models.BookmarkTypeBookmark)
+				item.URI = attr(tok, "href")
+				item.DateAdded = parseDate(tok)
+				item.Keyword = attr(tok, "shortcuturl")
+				item.Tags = parseTags(attr(tok, "tags"))
+
+				if feed := attr(tok, "feedurl"); feed != "" {
+					item.Type = models.BookmarkTypeLivemark
+					item.FeedURI = feed
+					item.SiteURI = item.URI
+					item.URI = ""
+				} else if models.IsQueryURI(item.URI) {
+					item.Type = models.BookmarkTypeQuery
+					item.FolderName = models.QueryFolderName(item.URI)
+				}
+
+				current().ResolvedChildren = append(current().ResolvedChildren, item)
+
+				pendingFolder = nil
+				lastItem = item
+				textTarget = &item.Title
+				textEnd = atom.A
+
+			case atom.H3:
+				item := newParsedItem(models.BookmarkTypeFolder)
+				item.DateAdded = parseDate(tok)
+				if strings.EqualFold(attr(tok, "personal_toolbar_folder"), "true") {
+					item.ID = consts.BookmarkIDToolbar
+				} else if strings.EqualFold(attr(tok, "unfiled_bookmarks_folder"), "true") {
+					item.ID = consts.BookmarkIDUnfiled
+				}
+
+				current().ResolvedChildren = append(current().ResolvedChildren, item)
+
+				pendingFolder = item
+				lastItem = item
+				textTarget = &item.Title
+				textEnd = atom.H3
+
+			case atom.Hr:
+				current().ResolvedChildren = append(current().ResolvedChildren, newParsedItem(models.BookmarkTypeSeparator))
+				pendingFolder = nil
+				lastItem = nil
+
+			case atom.Dd:
+				if lastItem != nil {
+					textTarget = &lastItem.Description
+					textEnd = atom.Dd
+				}
+			}
+		}
+	}
+}
+
+func newParsedItem(bmtype models.BookmarkType) *models.BookmarkTreeRecord {
+	return &models.BookmarkTreeRecord{
+		BookmarkRecord:   models.BookmarkRecord{Type: bmtype},
+		ResolvedChildren: make([]*models.BookmarkTreeRecord, 0),
+	}
+}
+
+func attr(tok html.Token, key string) string {
+	for _, v := range tok.Attr {
+		if strings.EqualFold(v.Key, key) {
+			return v.Val
+		}
+	}
+	return ""
+}
+
+func parseDate(tok html.Token) *time.Time {
+	for _, key := range []string{"add_date", "last_modified"} {
+		v, err := strconv.ParseInt(strings.TrimSpace(attr(tok, key)), 10, 64)
+		if err != nil || v <= 0 {
+			continue
+		}
+
+		// the format uses unix-seconds, but some exporters write milli- or microseconds
+		if v > 100_000_000_000_000 {
+			return langext.Ptr(time.UnixMicro(v))
+		} else if v > 100_000_000_000 {
+			return langext.Ptr(time.UnixMilli(v))
+		} else {
+			return langext.Ptr(time.Unix(v, 0))
+		}
+	}
+	return nil
+}
+
+func parseTags(v string) []string {
+	result := make([]string, 0)
+	for _, tag := range strings.Split(v, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
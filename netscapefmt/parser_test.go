@@ -0,0 +1,162 @@
+package netscapefmt
+
+import (
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+const chromeExport = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1600000000" LAST_MODIFIED="1600000100" PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://github.com/" ADD_DATE="1600000001" SHORTCUTURL="gh" TAGS="dev, git">GitHub &amp; Co</A>
+        <DD>Where the code lives
+        <HR>
+        <DT><H3 LAST_MODIFIED="1600000200">Empty</H3>
+        <DL><p>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="https://example.com/">Example</A>
+</DL><p>
+`
+
+func TestParseChromeExport(t *testing.T) {
+	items, err := Parse([]byte(chromeExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("expected 2 top-level items, got %d", len(items))
+	}
+
+	toolbar := items[0]
+	if toolbar.Type != models.BookmarkTypeFolder || toolbar.ID != consts.BookmarkIDToolbar || toolbar.Title != "Bookmarks bar" {
+		t.Errorf("unexpected toolbar folder: %+v", toolbar.BookmarkRecord)
+	}
+	if len(toolbar.ResolvedChildren) != 3 {
+		t.Fatalf("expected 3 items in the toolbar, got %d", len(toolbar.ResolvedChildren))
+	}
+
+	gh := toolbar.ResolvedChildren[0]
+	if gh.Type != models.BookmarkTypeBookmark || gh.URI != "https://github.com/" || gh.Title != "GitHub & Co" {
+		t.Errorf("unexpected bookmark: %+v", gh.BookmarkRecord)
+	}
+	if gh.Keyword != "gh" || strings.Join(gh.Tags, "|") != "dev|git" || gh.Description != "Where the code lives" {
+		t.Errorf("unexpected bookmark attributes: %+v", gh.BookmarkRecord)
+	}
+	if gh.DateAdded == nil || gh.DateAdded.Unix() != 1600000001 {
+		t.Errorf("unexpected ADD_DATE: %v", gh.DateAdded)
+	}
+
+	if toolbar.ResolvedChildren[1].Type != models.BookmarkTypeSeparator {
+		t.Errorf("expected a separator, got %v", toolbar.ResolvedChildren[1].Type)
+	}
+
+	empty := toolbar.ResolvedChildren[2]
+	if empty.Type != models.BookmarkTypeFolder || empty.Title != "Empty" || len(empty.ResolvedChildren) != 0 {
+		t.Errorf("unexpected folder: %+v", empty.BookmarkRecord)
+	}
+	if empty.DateAdded == nil || empty.DateAdded.Unix() != 1600000200 {
+		t.Errorf("expected LAST_MODIFIED as fallback date, got %v", empty.DateAdded)
+	}
+
+	if items[1].URI != "https://example.com/" || items[1].Title != "Example" || items[1].ID != "" {
+		t.Errorf("unexpected bookmark: %+v", items[1].BookmarkRecord)
+	}
+}
+
+func TestParseFormatRoundtrip(t *testing.T) {
+	added := time.Unix(1650000000, 0)
+
+	bookmark := &models.BookmarkTreeRecord{BookmarkRecord: models.BookmarkRecord{
+		ID: "aaaaaaaaaaaa", Type: models.BookmarkTypeBookmark, Title: "<Go>", URI: "https://go.dev/?a=1&b=2", DateAdded: &added, Tags: []string{"lang"},
+	}}
+	folder := &models.BookmarkTreeRecord{
+		BookmarkRecord:   models.BookmarkRecord{ID: "bbbbbbbbbbbb", Type: models.BookmarkTypeFolder, Title: "Folder"},
+		ResolvedChildren: []*models.BookmarkTreeRecord{bookmark},
+	}
+	menu := &models.BookmarkTreeRecord{
+		BookmarkRecord:   models.BookmarkRecord{ID: consts.BookmarkIDMenu, Type: models.BookmarkTypeFolder},
+		ResolvedChildren: []*models.BookmarkTreeRecord{folder},
+	}
+	toolbar := &models.BookmarkTreeRecord{
+		BookmarkRecord:   models.BookmarkRecord{ID: consts.BookmarkIDToolbar, Type: models.BookmarkTypeFolder},
+		ResolvedChildren: []*models.BookmarkTreeRecord{{BookmarkRecord: models.BookmarkRecord{Type: models.BookmarkTypeSeparator}}},
+	}
+	unfiled := &models.BookmarkTreeRecord{
+		BookmarkRecord:   models.BookmarkRecord{ID: consts.BookmarkIDUnfiled, Type: models.BookmarkTypeFolder},
+		ResolvedChildren: []*models.BookmarkTreeRecord{{BookmarkRecord: models.BookmarkRecord{ID: "dddddddddddd", Type: models.BookmarkTypeBookmark, Title: "Other", URI: "https://example.org/"}}},
+	}
+
+	items, err := Parse([]byte(Format(nil, []*models.BookmarkTreeRecord{unfiled, menu, toolbar})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 3 || items[0].Title != "Folder" || items[1].ID != consts.BookmarkIDToolbar || items[2].ID != consts.BookmarkIDUnfiled {
+		t.Fatalf("unexpected top-level items: %v", items)
+	}
+
+	if items[2].Title != "Other Bookmarks" || len(items[2].ResolvedChildren) != 1 || items[2].ResolvedChildren[0].URI != "https://example.org/" {
+		t.Errorf("unfiled root did not survive the roundtrip: %+v", items[2])
+	}
+
+	bm := items[0].ResolvedChildren[0]
+	if bm.Title != "<Go>" || bm.URI != "https://go.dev/?a=1&b=2" || !bm.DateAdded.Equal(added) || strings.Join(bm.Tags, ",") != "lang" {
+		t.Errorf("bookmark did not survive the roundtrip: %+v", bm.BookmarkRecord)
+	}
+
+	if len(items[1].ResolvedChildren) != 1 || items[1].ResolvedChildren[0].Type != models.BookmarkTypeSeparator {
+		t.Errorf("toolbar did not survive the roundtrip: %v", items[1].ResolvedChildren)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("hello world")); err == nil {
+		t.Error("Parse should fail for a file without <DL>")
+	}
+}
+
+func TestSpecialTypesRoundtrip(t *testing.T) {
+	menu := &models.BookmarkTreeRecord{
+		BookmarkRecord: models.BookmarkRecord{ID: consts.BookmarkIDMenu, Type: models.BookmarkTypeFolder},
+		ResolvedChildren: []*models.BookmarkTreeRecord{
+			{BookmarkRecord: models.BookmarkRecord{ID: "aaaaaaaaaaaa", Type: models.BookmarkTypeQuery, Title: "Go", URI: "place:tag=go", FolderName: "go"}},
+			{BookmarkRecord: models.BookmarkRecord{ID: "bbbbbbbbbbbb", Type: models.BookmarkTypeLivemark, Title: "News", FeedURI: "https://example.com/feed.xml", SiteURI: "https://example.com/"}},
+			{BookmarkRecord: models.BookmarkRecord{ID: "cccccccccccc", Type: models.BookmarkTypeMicroSummary, Title: "Status", URI: "https://example.com/status", GeneratorUri: "urn:gen"}},
+		},
+	}
+
+	output := Format(nil, []*models.BookmarkTreeRecord{menu})
+	if !strings.Contains(output, `FEEDURL="https://example.com/feed.xml"`) || !strings.Contains(output, `HREF="place:tag=go"`) {
+		t.Errorf("output is missing the query or livemark:\n%s", output)
+	}
+
+	items, err := Parse([]byte(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+
+	if q := items[0]; q.Type != models.BookmarkTypeQuery || q.URI != "place:tag=go" || q.FolderName != "go" || q.Title != "Go" {
+		t.Errorf("query did not survive the roundtrip: %+v", q.BookmarkRecord)
+	}
+	if lm := items[1]; lm.Type != models.BookmarkTypeLivemark || lm.FeedURI != "https://example.com/feed.xml" || lm.SiteURI != "https://example.com/" || lm.URI != "" {
+		t.Errorf("livemark did not survive the roundtrip: %+v", lm.BookmarkRecord)
+	}
+	if ms := items[2]; ms.Type != models.BookmarkTypeBookmark || ms.URI != "https://example.com/status" {
+		t.Errorf("microsummary should be exported as a bookmark: %+v", ms.BookmarkRecord)
+	}
+}
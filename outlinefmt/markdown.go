@@ -0,0 +1,88 @@
+package outlinefmt
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/models"
+	"fmt"
+	"strings"
+)
+
+// FormatMarkdown writes the bookmark tree as a markdown document
+// Folders become headings (the roots are level 1, deeper than level 6 is written as level 6), bookmarks become list items.
+func FormatMarkdown(ctx *cli.FFSContext, records []*models.BookmarkTreeRecord) string {
+	sb := &strings.Builder{}
+
+	entries, folders := splitChildren(topLevel(records))
+
+	writeMarkdownEntries(ctx, sb, entries)
+	for _, v := range folders {
+		writeMarkdownFolder(ctx, sb, v, 1)
+	}
+
+	return strings.TrimLeft(sb.String(), "\n")
+}
+
+func writeMarkdownFolder(ctx *cli.FFSContext, sb *strings.Builder, folder *models.BookmarkTreeRecord, level int) {
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("#", min(level, 6)) + " " + escapeMarkdown(singleLine(folderTitle(folder))) + "\n")
+	sb.WriteString("\n")
+
+	entries, folders := splitChildren(folder.ResolvedChildren)
+
+	writeMarkdownEntries(ctx, sb, entries)
+	for _, v := range folders {
+		writeMarkdownFolder(ctx, sb, v, level+1)
+	}
+}
+
+func writeMarkdownEntries(ctx *cli.FFSContext, sb *strings.Builder, entries []*models.BookmarkTreeRecord) {
+	for _, item := range entries {
+		switch item.Type {
+		case models.BookmarkTypeBookmark, models.BookmarkTypeMicroSummary, models.BookmarkTypeQuery, models.BookmarkTypeLivemark:
+			line := fmt.Sprintf("- [%s](%s)", escapeMarkdown(singleLine(linkTitle(item))), escapeMarkdownURL(linkURL(item)))
+			if item.Type == models.BookmarkTypeLivemark {
+				line += " *(feed)*"
+			}
+			for _, tag := range item.Tags {
+				line += " `" + strings.ReplaceAll(tag, "`", "'") + "`"
+			}
+			sb.WriteString(line + "\n")
+			if desc := singleLine(item.Description); desc != "" {
+				sb.WriteString("  " + escapeMarkdown(desc) + "\n")
+			}
+
+		case models.BookmarkTypeSeparator:
+			sb.WriteString("\n---\n\n")
+
+		default:
+			ctx.PrintVerbose(fmt.Sprintf("[WARN] skip item of type %v in markdown-formatter", item.Type))
+		}
+	}
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`#`, `\#`,
+)
+
+func escapeMarkdown(v string) string {
+	return markdownEscaper.Replace(v)
+}
+
+var markdownURLEscaper = strings.NewReplacer(
+	` `, `%20`,
+	`(`, `%28`,
+	`)`, `%29`,
+	`<`, `%3C`,
+	`>`, `%3E`,
+)
+
+func escapeMarkdownURL(v string) string {
+	return markdownURLEscaper.Replace(v)
+}
@@ -0,0 +1,87 @@
+package outlinefmt
+
+import (
+	"encoding/xml"
+	"ffsyncclient/cli"
+	"ffsyncclient/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// http://opml.org/spec2.opml
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text        string        `xml:"text,attr"`
+	Type        string        `xml:"type,attr,omitempty"`
+	URL         string        `xml:"url,attr,omitempty"`
+	XMLURL      string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL     string        `xml:"htmlUrl,attr,omitempty"`
+	Created     string        `xml:"created,attr,omitempty"`
+	Category    string        `xml:"category,attr,omitempty"`
+	Description string        `xml:"description,attr,omitempty"`
+	Children    []opmlOutline `xml:"outline"`
+}
+
+// FormatOPML writes the bookmark tree as an OPML 2.0 outline
+// Folders become outlines, bookmarks `link` outlines (their tags are written as category) and livemarks `rss` outlines.
+// OPML has no separators, they are skipped.
+func FormatOPML(ctx *cli.FFSContext, records []*models.BookmarkTreeRecord) (string, error) {
+	doc := opmlDocument{
+		Version: "2.0",
+		Title:   "Bookmarks",
+		Body:    opmlOutlines(ctx, topLevel(records)),
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xml.Header + string(data) + "\n", nil
+}
+
+func opmlOutlines(ctx *cli.FFSContext, items []*models.BookmarkTreeRecord) []opmlOutline {
+	result := make([]opmlOutline, 0, len(items))
+
+	for _, item := range items {
+		outline := opmlOutline{Text: singleLine(linkTitle(item))}
+		if item.DateAdded != nil {
+			outline.Created = item.DateAdded.UTC().Format(time.RFC1123Z)
+		}
+
+		switch item.Type {
+		case models.BookmarkTypeFolder:
+			outline.Text = singleLine(folderTitle(item))
+			outline.Children = opmlOutlines(ctx, item.ResolvedChildren)
+
+		case models.BookmarkTypeBookmark, models.BookmarkTypeMicroSummary, models.BookmarkTypeQuery:
+			outline.Type = "link"
+			outline.URL = item.URI
+			outline.Category = strings.Join(item.Tags, ",")
+			outline.Description = singleLine(item.Description)
+
+		case models.BookmarkTypeLivemark:
+			outline.Type = "rss"
+			outline.XMLURL = item.FeedURI
+			outline.HTMLURL = item.SiteURI
+
+		default:
+			if item.Type != models.BookmarkTypeSeparator {
+				ctx.PrintVerbose(fmt.Sprintf("[WARN] skip item of type %v in opml-formatter", item.Type))
+			}
+			continue
+		}
+
+		result = append(result, outline)
+	}
+
+	return result
+}
@@ -0,0 +1,72 @@
+package outlinefmt
+
+import (
+	"ffsyncclient/cli"
+	"ffsyncclient/models"
+	"fmt"
+	"strings"
+)
+
+// FormatOrg writes the bookmark tree as an org-mode document
+// Folders become headlines (the roots are level 1), bookmarks become list items.
+func FormatOrg(ctx *cli.FFSContext, records []*models.BookmarkTreeRecord) string {
+	sb := &strings.Builder{}
+
+	entries, folders := splitChildren(topLevel(records))
+
+	writeOrgEntries(ctx, sb, entries)
+	for _, v := range folders {
+		writeOrgFolder(ctx, sb, v, 1)
+	}
+
+	return strings.TrimLeft(sb.String(), "\n")
+}
+
+func writeOrgFolder(ctx *cli.FFSContext, sb *strings.Builder, folder *models.BookmarkTreeRecord, level int) {
+	sb.WriteString("\n")
+	sb.WriteString(strings.Repeat("*", level) + " " + singleLine(folderTitle(folder)) + "\n")
+
+	entries, folders := splitChildren(folder.ResolvedChildren)
+
+	writeOrgEntries(ctx, sb, entries)
+	for _, v := range folders {
+		writeOrgFolder(ctx, sb, v, level+1)
+	}
+}
+
+func writeOrgEntries(ctx *cli.FFSContext, sb *strings.Builder, entries []*models.BookmarkTreeRecord) {
+	for _, item := range entries {
+		switch item.Type {
+		case models.BookmarkTypeBookmark, models.BookmarkTypeMicroSummary, models.BookmarkTypeQuery, models.BookmarkTypeLivemark:
+			line := fmt.Sprintf("- [[%s][%s]]", escapeOrgURL(linkURL(item)), escapeOrgText(singleLine(linkTitle(item))))
+			if item.Type == models.BookmarkTypeLivemark {
+				line += " /(feed)/"
+			}
+			for _, tag := range item.Tags {
+				line += " =" + strings.ReplaceAll(tag, "=", "-") + "="
+			}
+			sb.WriteString(line + "\n")
+			if desc := singleLine(item.Description); desc != "" {
+				sb.WriteString("  " + desc + "\n")
+			}
+
+		case models.BookmarkTypeSeparator:
+			sb.WriteString("-----\n")
+
+		default:
+			ctx.PrintVerbose(fmt.Sprintf("[WARN] skip item of type %v in org-formatter", item.Type))
+		}
+	}
+}
+
+// brackets would end the link, they are replaced by braces in the description and escaped in the URL
+var orgTextEscaper = strings.NewReplacer(`[`, `{`, `]`, `}`)
+var orgURLEscaper = strings.NewReplacer(`[`, `%5B`, `]`, `%5D`)
+
+func escapeOrgText(v string) string {
+	return orgTextEscaper.Replace(v)
+}
+
+func escapeOrgURL(v string) string {
+	return orgURLEscaper.Replace(v)
+}
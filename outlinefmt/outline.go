@@ -0,0 +1,69 @@
+package outlinefmt
+
+import (
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"strings"
+)
+
+// Formats for publishing bookmarks as documents (Markdown, Org-mode and OPML)
+//
+// Folders become (nested) headings or outlines, bookmarks become links.
+// In Markdown and Org-mode the entries of a folder are written before its subfolders,
+// otherwise an entry after a subfolder would appear to be part of the subfolder.
+
+// topLevel returns the entries that are written at the top-level (the places root is skipped, its children are roots themselves)
+func topLevel(records []*models.BookmarkTreeRecord) []*models.BookmarkTreeRecord {
+	result := make([]*models.BookmarkTreeRecord, 0, len(records))
+	for _, v := range records {
+		if v.ID == consts.BookmarkIDPlaces {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+func folderTitle(item *models.BookmarkTreeRecord) string {
+	if title, ok := consts.BookmarkRootTitles[item.ID]; ok {
+		return title
+	}
+	return item.Title
+}
+
+func isFolder(item *models.BookmarkTreeRecord) bool {
+	return item.Type == models.BookmarkTypeFolder
+}
+
+// splitChildren returns the entries (bookmarks, separators, etc) and the subfolders of the folder
+func splitChildren(items []*models.BookmarkTreeRecord) ([]*models.BookmarkTreeRecord, []*models.BookmarkTreeRecord) {
+	entries := make([]*models.BookmarkTreeRecord, 0, len(items))
+	folders := make([]*models.BookmarkTreeRecord, 0)
+	for _, v := range items {
+		if isFolder(v) {
+			folders = append(folders, v)
+		} else {
+			entries = append(entries, v)
+		}
+	}
+	return entries, folders
+}
+
+// linkURL returns the target of the entry (livemarks link to their feed)
+func linkURL(item *models.BookmarkTreeRecord) string {
+	if item.Type == models.BookmarkTypeLivemark {
+		return item.FeedURI
+	}
+	return item.URI
+}
+
+func linkTitle(item *models.BookmarkTreeRecord) string {
+	if item.Title != "" {
+		return item.Title
+	}
+	return linkURL(item)
+}
+
+func singleLine(v string) string {
+	return strings.Join(strings.Fields(v), " ")
+}
@@ -0,0 +1,106 @@
+package outlinefmt
+
+import (
+	"encoding/xml"
+	"ffsyncclient/consts"
+	"ffsyncclient/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTree() []*models.BookmarkTreeRecord {
+	added := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	sub := &models.BookmarkTreeRecord{
+		BookmarkRecord: models.BookmarkRecord{ID: "ffffffffffff", Type: models.BookmarkTypeFolder, Title: "Go [lang]"},
+		ResolvedChildren: []*models.BookmarkTreeRecord{
+			{BookmarkRecord: models.BookmarkRecord{ID: "aaaaaaaaaaaa", Type: models.BookmarkTypeBookmark, Title: "Go *docs*", URI: "https://go.dev/doc/(x)", DateAdded: &added, Tags: []string{"go", "docs"}, Description: "The\nofficial docs"}},
+		},
+	}
+
+	toolbar := &models.BookmarkTreeRecord{
+		BookmarkRecord: models.BookmarkRecord{ID: consts.BookmarkIDToolbar, Type: models.BookmarkTypeFolder},
+		ResolvedChildren: []*models.BookmarkTreeRecord{
+			sub,
+			{BookmarkRecord: models.BookmarkRecord{ID: "bbbbbbbbbbbb", Type: models.BookmarkTypeBookmark, Title: "Example", URI: "https://example.com/"}},
+			{BookmarkRecord: models.BookmarkRecord{ID: "cccccccccccc", Type: models.BookmarkTypeSeparator}},
+			{BookmarkRecord: models.BookmarkRecord{ID: "dddddddddddd", Type: models.BookmarkTypeLivemark, Title: "News", FeedURI: "https://example.com/feed.xml", SiteURI: "https://example.com/"}},
+		},
+	}
+
+	places := &models.BookmarkTreeRecord{BookmarkRecord: models.BookmarkRecord{ID: consts.BookmarkIDPlaces, Type: models.BookmarkTypeFolder}}
+
+	return []*models.BookmarkTreeRecord{places, toolbar}
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	expected := strings.Join([]string{
+		"# Bookmarks Toolbar",
+		"",
+		"- [Example](https://example.com/)",
+		"",
+		"---",
+		"",
+		"- [News](https://example.com/feed.xml) *(feed)*",
+		"",
+		`## Go \[lang\]`,
+		"",
+		"- [Go \\*docs\\*](https://go.dev/doc/%28x%29) `go` `docs`",
+		"  The official docs",
+		"",
+	}, "\n")
+
+	if v := FormatMarkdown(nil, testTree()); v != expected {
+		t.Errorf("unexpected markdown:\n%s\n\nexpected:\n%s", v, expected)
+	}
+}
+
+func TestFormatOrg(t *testing.T) {
+	expected := strings.Join([]string{
+		"* Bookmarks Toolbar",
+		"- [[https://example.com/][Example]]",
+		"-----",
+		"- [[https://example.com/feed.xml][News]] /(feed)/",
+		"",
+		"** Go [lang]",
+		"- [[https://go.dev/doc/(x)][Go *docs*]] =go= =docs=",
+		"  The official docs",
+		"",
+	}, "\n")
+
+	if v := FormatOrg(nil, testTree()); v != expected {
+		t.Errorf("unexpected org document:\n%s\n\nexpected:\n%s", v, expected)
+	}
+}
+
+func TestFormatOPML(t *testing.T) {
+	data, err := FormatOPML(nil, testTree())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc opmlDocument
+	if err := xml.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("invalid xml: %v\n%s", err, data)
+	}
+
+	if doc.Version != "2.0" || len(doc.Body) != 1 || doc.Body[0].Text != "Bookmarks Toolbar" {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	toolbar := doc.Body[0].Children
+	if len(toolbar) != 3 {
+		t.Fatalf("expected 3 outlines in the toolbar (separators are skipped), got %d", len(toolbar))
+	}
+
+	if toolbar[0].Text != "Go [lang]" || len(toolbar[0].Children) != 1 {
+		t.Errorf("unexpected folder: %+v", toolbar[0])
+	}
+	if bm := toolbar[0].Children[0]; bm.Type != "link" || bm.URL != "https://go.dev/doc/(x)" || bm.Category != "go,docs" || bm.Created != "Wed, 01 May 2024 12:00:00 +0000" {
+		t.Errorf("unexpected bookmark: %+v", bm)
+	}
+	if lm := toolbar[2]; lm.Type != "rss" || lm.XMLURL != "https://example.com/feed.xml" || lm.HTMLURL != "https://example.com/" {
+		t.Errorf("unexpected livemark: %+v", lm)
+	}
+}
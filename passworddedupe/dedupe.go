@@ -0,0 +1,134 @@
+package passworddedupe
+
+import (
+	"ffsyncclient/loginmatch"
+	"ffsyncclient/models"
+	"strings"
+	"time"
+)
+
+type Group struct {
+	Origin           string // the normalized origin (scheme, host and port)
+	Username         string
+	HTTPRealm        *string // only set for HTTP-auth logins
+	HasFormSubmitURL bool
+	Entries          []models.PasswordRecord
+	Keep             int // the index of the entry that survives
+}
+
+type Merged struct {
+	TimesUsed *int64     // the sum of all timesUsed counters (nil if no entry has one)
+	Created   *time.Time // the earliest timeCreated
+	LastUsed  *time.Time // the latest timeLastUsed
+}
+
+// NormalizeOrigin returns the origin (scheme://host[:port]) of the hostname, hostnames that cannot be parsed are only lowercased
+func NormalizeOrigin(hostname string) string {
+	if o, err := loginmatch.ParseOrigin(hostname); err == nil {
+		return o.String()
+	}
+	return strings.ToLower(hostname)
+}
+
+// Key returns the key that is used to detect duplicates
+//
+// Two logins are only duplicates if they have the same origin and username and are of the same kind,
+// an HTTP-auth login (with a httpRealm) is never merged with a form login (with a formSubmitURL).
+func Key(pw models.PasswordRecord) string {
+	realm := "-"
+	if pw.HTTPRealm != nil {
+		realm = "realm:" + *pw.HTTPRealm
+	}
+
+	form := "0"
+	if pw.FormSubmitURL != "" {
+		form = "1"
+	}
+
+	return NormalizeOrigin(pw.Hostname) + "\n" + pw.Username + "\n" + realm + "\n" + form
+}
+
+// Find groups the (not deleted) logins by their Key
+//
+// `modified` contains the server modification time of the records, it is used if a login has neither passwordChanged nor timeCreated.
+// Only groups with more than one entry are returned, the groups and their entries keep the order of `passwords`.
+func Find(passwords []models.PasswordRecord, modified map[string]time.Time) []Group {
+	groupKeys := make([]string, 0)
+	groupMap := make(map[string]*Group)
+
+	for _, v := range passwords {
+		if v.Deleted {
+			continue
+		}
+
+		key := Key(v)
+		if grp, ok := groupMap[key]; ok {
+			grp.Entries = append(grp.Entries, v)
+		} else {
+			groupKeys = append(groupKeys, key)
+			groupMap[key] = &Group{
+				Origin:           NormalizeOrigin(v.Hostname),
+				Username:         v.Username,
+				HTTPRealm:        v.HTTPRealm,
+				HasFormSubmitURL: v.FormSubmitURL != "",
+				Entries:          []models.PasswordRecord{v},
+			}
+		}
+	}
+
+	result := make([]Group, 0)
+	for _, key := range groupKeys {
+		grp := *groupMap[key]
+		if len(grp.Entries) < 2 {
+			continue
+		}
+		grp.Keep = chooseKeep(grp.Entries, modified)
+		result = append(result, grp)
+	}
+
+	return result
+}
+
+// chooseKeep returns the index of the entry with the most recently changed password
+func chooseKeep(entries []models.PasswordRecord, modified map[string]time.Time) int {
+	best := 0
+	for i, v := range entries {
+		if changeTime(v, modified).After(changeTime(entries[best], modified)) {
+			best = i
+		}
+	}
+	return best
+}
+
+func changeTime(v models.PasswordRecord, modified map[string]time.Time) time.Time {
+	if v.PasswordChanged != nil {
+		return *v.PasswordChanged
+	}
+	if v.Created != nil {
+		return *v.Created
+	}
+	return modified[v.ID]
+}
+
+// Merge returns the usage data of the surviving entry after merging the group
+func Merge(grp Group) Merged {
+	result := Merged{}
+
+	for _, v := range grp.Entries {
+		if v.TimesUsed != nil {
+			sum := *v.TimesUsed
+			if result.TimesUsed != nil {
+				sum += *result.TimesUsed
+			}
+			result.TimesUsed = &sum
+		}
+		if v.Created != nil && (result.Created == nil || v.Created.Before(*result.Created)) {
+			result.Created = v.Created
+		}
+		if v.LastUsed != nil && (result.LastUsed == nil || v.LastUsed.After(*result.LastUsed)) {
+			result.LastUsed = v.LastUsed
+		}
+	}
+
+	return result
+}
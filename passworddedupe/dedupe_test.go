@@ -0,0 +1,90 @@
+package passworddedupe
+
+import (
+	"ffsyncclient/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(d int) *time.Time {
+	v := time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	return &v
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func testPasswords() []models.PasswordRecord {
+	return []models.PasswordRecord{
+		{ID: "a", Hostname: "https://GitHub.com", FormSubmitURL: "https://github.com", Username: "john", Created: date(1), TimesUsed: ptr(int64(3)), LastUsed: date(4)},
+		{ID: "b", Hostname: "https://github.com:443/", FormSubmitURL: "https://github.com", Username: "john", PasswordChanged: date(6), Created: date(2), TimesUsed: ptr(int64(2)), LastUsed: date(3)},
+		{ID: "c", Hostname: "https://github.com", HTTPRealm: ptr("GitHub"), Username: "john", PasswordChanged: date(9)},
+		{ID: "d", Hostname: "https://github.com", FormSubmitURL: "https://github.com", Username: "jane"},
+		{ID: "e", Hostname: "http://github.com", FormSubmitURL: "http://github.com", Username: "john"},
+		{ID: "f", Hostname: "https://github.com", FormSubmitURL: "https://github.com", Username: "john", PasswordChanged: date(20), Deleted: true},
+		{ID: "g", Hostname: "https://github.com", HTTPRealm: ptr("Other"), Username: "john"},
+		{ID: "h", Hostname: "https://github.com", HTTPRealm: ptr("GitHub"), Username: "john"},
+		{ID: "i", Hostname: "https://gist.github.com", FormSubmitURL: "https://gist.github.com", Username: "john"},
+	}
+}
+
+func ids(grp Group) string {
+	r := make([]string, 0, len(grp.Entries))
+	for _, v := range grp.Entries {
+		r = append(r, v.ID)
+	}
+	return strings.Join(r, ",")
+}
+
+func TestFind(t *testing.T) {
+	groups := Find(testPasswords(), map[string]time.Time{"h": *date(30)})
+	if len(groups) != 2 {
+		t.Fatalf("expected two groups, got %d", len(groups))
+	}
+
+	if ids(groups[0]) != "a,b" || groups[0].Origin != "https://github.com" || !groups[0].HasFormSubmitURL || groups[0].HTTPRealm != nil {
+		t.Errorf("unexpected form group: %s (%s)", ids(groups[0]), groups[0].Origin)
+	}
+	if groups[0].Entries[groups[0].Keep].ID != "b" {
+		t.Errorf("expected b to be kept (latest password change), got %s", groups[0].Entries[groups[0].Keep].ID)
+	}
+
+	if ids(groups[1]) != "c,h" || groups[1].HasFormSubmitURL || groups[1].HTTPRealm == nil || *groups[1].HTTPRealm != "GitHub" {
+		t.Errorf("unexpected http-auth group: %s", ids(groups[1]))
+	}
+	if groups[1].Entries[groups[1].Keep].ID != "h" {
+		t.Errorf("expected h to be kept (latest modification), got %s", groups[1].Entries[groups[1].Keep].ID)
+	}
+}
+
+func TestKey(t *testing.T) {
+	form := models.PasswordRecord{Hostname: "https://example.com", FormSubmitURL: "https://example.com", Username: "john"}
+	auth := models.PasswordRecord{Hostname: "https://example.com", HTTPRealm: ptr(""), Username: "john"}
+	none := models.PasswordRecord{Hostname: "https://example.com", Username: "john"}
+
+	if Key(form) == Key(auth) || Key(form) == Key(none) || Key(auth) == Key(none) {
+		t.Errorf("form logins, http-auth logins and logins without formSubmitURL must have different keys")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	groups := Find(testPasswords(), nil)
+
+	m := Merge(groups[0])
+	if m.TimesUsed == nil || *m.TimesUsed != 5 {
+		t.Errorf("expected timesUsed 5, got %v", m.TimesUsed)
+	}
+	if m.Created == nil || !m.Created.Equal(*date(1)) {
+		t.Errorf("expected the earliest timeCreated, got %v", m.Created)
+	}
+	if m.LastUsed == nil || !m.LastUsed.Equal(*date(4)) {
+		t.Errorf("expected the latest timeLastUsed, got %v", m.LastUsed)
+	}
+
+	m = Merge(groups[1])
+	if m.TimesUsed != nil || m.Created != nil || m.LastUsed != nil {
+		t.Errorf("expected no usage data, got %+v", m)
+	}
+}
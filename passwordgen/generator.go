@@ -0,0 +1,221 @@
+package passwordgen
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+)
+
+const (
+	CharsetLower   = "abcdefghijklmnopqrstuvwxyz"
+	CharsetUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	CharsetDigits  = "0123456789"
+	CharsetSymbols = "-~!@#$%^&*_+=)}:;\"'>,.?]"
+)
+
+// character classes of the firefox password generator (PasswordGenerator.sys.mjs),
+// visually ambiguous characters (l, o, I, O, 0, 1) are omitted
+const (
+	firefoxLower   = "abcdefghijkmnpqrstuvwxyz"
+	firefoxUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	firefoxDigits  = "23456789"
+	firefoxSymbols = "-~!@#$%^&*_+=)}:;\"'>,.?]"
+)
+
+const FirefoxDefaultLength = 15
+
+const (
+	pronounceableConsonants = "bcdfghjklmnprstvwxz"
+	pronounceableVowels     = "aeiou"
+)
+
+// EFF large wordlist (https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases), CC BY 3.0 US
+//
+//go:embed wordlist_eff_large.txt
+var effLargeWordlist string
+
+// Random returns a password of `length` characters, uniformly chosen from charset
+func Random(length int, charset string) (string, error) {
+	if length <= 0 {
+		return "", errors.New("password length must be positive")
+	}
+
+	runes := []rune(charset)
+	if len(runes) == 0 {
+		return "", errors.New("charset must not be empty")
+	}
+
+	result := make([]rune, length)
+	for i := range result {
+		c, err := randElement(runes)
+		if err != nil {
+			return "", err
+		}
+		result[i] = c
+	}
+
+	return string(result), nil
+}
+
+// Firefox generates a password like the firefox password manager:
+// at least one lowercase letter, uppercase letter and digit (and one symbol if withSymbols), the rest is filled up with alphanumeric characters
+func Firefox(length int, withSymbols bool) (string, error) {
+	required := []string{firefoxLower, firefoxUpper, firefoxDigits}
+	if withSymbols {
+		required = append(required, firefoxSymbols)
+	}
+
+	if length < len(required) {
+		return "", errors.New("password length is too short for the firefox preset")
+	}
+
+	result := make([]rune, 0, length)
+
+	for _, charset := range required {
+		c, err := randElement([]rune(charset))
+		if err != nil {
+			return "", err
+		}
+		result = append(result, c)
+	}
+
+	all := []rune(firefoxLower + firefoxUpper + firefoxDigits)
+	for len(result) < length {
+		c, err := randElement(all)
+		if err != nil {
+			return "", err
+		}
+		result = append(result, c)
+	}
+
+	err := shuffle(result)
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// Pronounceable returns a lowercase password of alternating consonants and vowels (e.g. `tokavelu`)
+// Has noticeably less entropy per character than Random, use a higher length
+func Pronounceable(length int) (string, error) {
+	if length <= 0 {
+		return "", errors.New("password length must be positive")
+	}
+
+	startWithVowel, err := randInt(2)
+	if err != nil {
+		return "", err
+	}
+
+	result := make([]rune, length)
+	for i := range result {
+		charset := pronounceableConsonants
+		if (i+startWithVowel)%2 == 1 {
+			charset = pronounceableVowels
+		}
+		c, err := randElement([]rune(charset))
+		if err != nil {
+			return "", err
+		}
+		result[i] = c
+	}
+
+	return string(result), nil
+}
+
+// Passphrase returns `count` random words from the wordlist, joined by separator
+func Passphrase(count int, separator string, wordlist []string) (string, error) {
+	if count <= 0 {
+		return "", errors.New("word count must be positive")
+	}
+
+	if len(wordlist) < 2 {
+		return "", errors.New("wordlist must contain at least two words")
+	}
+
+	words := make([]string, count)
+	for i := range words {
+		w, err := randElement(wordlist)
+		if err != nil {
+			return "", err
+		}
+		words[i] = w
+	}
+
+	return strings.Join(words, separator), nil
+}
+
+// DefaultWordlist returns the (embedded) EFF large wordlist with 7776 words
+func DefaultWordlist() []string {
+	return parseWordlist(effLargeWordlist)
+}
+
+// LoadWordlist reads a wordlist file with one word per line,
+// lines in diceware format (`11111 abacus`) are also supported
+func LoadWordlist(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	words := parseWordlist(string(data))
+	if len(words) < 2 {
+		return nil, errors.New("wordlist must contain at least two words")
+	}
+
+	return words, nil
+}
+
+func parseWordlist(data string) []string {
+	result := make([]string, 0, 8192)
+	known := make(map[string]bool, 8192)
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		word := fields[len(fields)-1]
+		if known[word] {
+			continue
+		}
+		known[word] = true
+		result = append(result, word)
+	}
+
+	return result
+}
+
+func randInt(max int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
+
+func randElement[T any](arr []T) (T, error) {
+	idx, err := randInt(len(arr))
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return arr[idx], nil
+}
+
+func shuffle[T any](arr []T) error {
+	for i := len(arr) - 1; i > 0; i-- {
+		j, err := randInt(i + 1)
+		if err != nil {
+			return err
+		}
+		arr[i], arr[j] = arr[j], arr[i]
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package passwordgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func onlyContains(v string, charset string) bool {
+	for _, c := range v {
+		if !strings.ContainsRune(charset, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRandom(t *testing.T) {
+	pw, err := Random(32, CharsetLower+CharsetDigits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pw) != 32 {
+		t.Errorf("expected length 32, got %d", len(pw))
+	}
+	if !onlyContains(pw, CharsetLower+CharsetDigits) {
+		t.Errorf("password %q contains characters outside of the charset", pw)
+	}
+
+	if _, err := Random(0, CharsetLower); err == nil {
+		t.Error("Random should fail for length 0")
+	}
+	if _, err := Random(8, ""); err == nil {
+		t.Error("Random should fail for an empty charset")
+	}
+}
+
+func TestFirefox(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		pw, err := Firefox(FirefoxDefaultLength, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pw) != FirefoxDefaultLength {
+			t.Fatalf("expected length %d, got %d", FirefoxDefaultLength, len(pw))
+		}
+		if !strings.ContainsAny(pw, firefoxLower) || !strings.ContainsAny(pw, firefoxUpper) || !strings.ContainsAny(pw, firefoxDigits) {
+			t.Fatalf("password %q is missing a required character class", pw)
+		}
+		if strings.ContainsAny(pw, "lIoO01") || strings.ContainsAny(pw, firefoxSymbols) {
+			t.Fatalf("password %q contains an ambiguous character or symbol", pw)
+		}
+
+		pw, err = Firefox(FirefoxDefaultLength, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.ContainsAny(pw, firefoxSymbols) {
+			t.Fatalf("password %q is missing a symbol", pw)
+		}
+	}
+
+	if _, err := Firefox(2, false); err == nil {
+		t.Error("Firefox should fail if the length cannot fit all required classes")
+	}
+}
+
+func TestPronounceable(t *testing.T) {
+	pw, err := Pronounceable(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pw) != 20 {
+		t.Errorf("expected length 20, got %d", len(pw))
+	}
+	for i := 1; i < len(pw); i++ {
+		if strings.ContainsRune(pronounceableVowels, rune(pw[i])) == strings.ContainsRune(pronounceableVowels, rune(pw[i-1])) {
+			t.Fatalf("password %q does not alternate consonants and vowels", pw)
+		}
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	wl := DefaultWordlist()
+	if len(wl) != 7776 {
+		t.Fatalf("expected 7776 words in the default wordlist, got %d", len(wl))
+	}
+
+	pp, err := Passphrase(6, "-", wl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strings.Split(pp, "-")) < 6 {
+		t.Errorf("passphrase %q has less than 6 words", pp)
+	}
+}
+
+func TestLoadWordlist(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "words.txt")
+	err := os.WriteFile(fp, []byte("11111\tapple\n11112 banana\n\ncherry\ncherry\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wl, err := LoadWordlist(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(wl, ",") != "apple,banana,cherry" {
+		t.Errorf("unexpected wordlist: %v", wl)
+	}
+}
@@ -0,0 +1,251 @@
+package secretscan
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Detects sensitive values (credit card numbers, IBANs, API tokens and email addresses) in free text, e.g. in form autocomplete entries
+
+type Kind string
+
+const (
+	KindCreditCard Kind = "credit-card"
+	KindIBAN       Kind = "iban"
+	KindToken      Kind = "token"
+	KindEmail      Kind = "email"
+)
+
+var AllKinds = []Kind{KindCreditCard, KindIBAN, KindToken, KindEmail}
+
+// ParseKind parses a kind from its name (case-insensitive)
+func ParseKind(v string) (Kind, bool) {
+	for _, k := range AllKinds {
+		if strings.EqualFold(v, string(k)) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+type Finding struct {
+	Kind   Kind
+	Match  string // the matching part of the value
+	Detail string // e.g. the card network or the token provider
+}
+
+var (
+	rexCardCandidate = regexp.MustCompile(`\d(?:[ -]?\d){11,18}`)
+	rexIBANCandidate = regexp.MustCompile(`(?i)\b[a-z]{2}\d{2}(?: ?[a-z0-9]){11,30}\b`)
+	rexEmail         = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}\b`)
+	rexTokenWord     = regexp.MustCompile(`[A-Za-z0-9_\-+/=.]{20,}`)
+)
+
+// knownTokens are the formats of common API keys and access tokens
+var knownTokens = []struct {
+	Name  string
+	Regex *regexp.Regexp
+}{
+	{"github", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"gitlab", regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`)},
+	{"slack", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9\-]{10,}\b`)},
+	{"aws", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"google", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"stripe", regexp.MustCompile(`\b[sr]k_(?:live|test)_[A-Za-z0-9]{16,}\b`)},
+	{"openai", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_\-]{32,}\b`)},
+	{"npm", regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{8,}\.eyJ[A-Za-z0-9_\-]{8,}\.[A-Za-z0-9_\-]{8,}`)},
+	{"private-key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY-----`)},
+}
+
+// ibanLengths contains the IBAN length of the SEPA countries (other countries are only validated by their checksum)
+var ibanLengths = map[string]int{
+	"AD": 24, "AT": 20, "BE": 16, "BG": 22, "CH": 21, "CY": 28, "CZ": 24, "DE": 22, "DK": 18, "EE": 20,
+	"ES": 24, "FI": 18, "FR": 27, "GB": 22, "GI": 23, "GR": 27, "HR": 21, "HU": 28, "IE": 22, "IS": 26,
+	"IT": 27, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MT": 31, "NL": 18, "NO": 15, "PL": 28,
+	"PT": 25, "RO": 24, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "VA": 22,
+}
+
+// Scan returns all sensitive values found in the text
+// Every part of the text is reported at most once (an IBAN is not also reported as a token).
+func Scan(value string) []Finding {
+	result := make([]Finding, 0)
+
+	for _, m := range rexCardCandidate.FindAllString(value, -1) {
+		digits := stripSeparators(m)
+		if network, ok := CardNetwork(digits); ok && Luhn(digits) {
+			result = append(result, Finding{Kind: KindCreditCard, Match: m, Detail: network})
+		}
+	}
+
+	for _, m := range rexIBANCandidate.FindAllString(value, -1) {
+		if ValidIBAN(m) {
+			result = append(result, Finding{Kind: KindIBAN, Match: m, Detail: strings.ToUpper(m[:2])})
+		}
+	}
+
+	for _, m := range rexEmail.FindAllString(value, -1) {
+		result = append(result, Finding{Kind: KindEmail, Match: m})
+	}
+
+	for _, tok := range knownTokens {
+		for _, m := range tok.Regex.FindAllString(value, -1) {
+			if !overlaps(result, m) {
+				result = append(result, Finding{Kind: KindToken, Match: m, Detail: tok.Name})
+			}
+		}
+	}
+
+	for _, m := range rexTokenWord.FindAllString(value, -1) {
+		if !overlaps(result, m) && IsHighEntropy(m) {
+			result = append(result, Finding{Kind: KindToken, Match: m, Detail: "high entropy"})
+		}
+	}
+
+	return result
+}
+
+// Luhn returns true if the digits have a valid luhn checksum (the last digit)
+func Luhn(digits string) bool {
+	if len(digits) < 2 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// CardNetwork returns the network of a card number by its prefix (IIN) and length
+func CardNetwork(digits string) (string, bool) {
+	n := len(digits)
+	prefix := func(lo, hi int, size int) bool {
+		if n < size {
+			return false
+		}
+		v := 0
+		for _, c := range digits[:size] {
+			v = v*10 + int(c-'0')
+		}
+		return v >= lo && v <= hi
+	}
+
+	switch {
+	case prefix(34, 34, 2) || prefix(37, 37, 2):
+		return "amex", n == 15
+	case prefix(4, 4, 1):
+		return "visa", n == 13 || n == 16 || n == 19
+	case prefix(51, 55, 2) || prefix(2221, 2720, 4):
+		return "mastercard", n == 16
+	case prefix(6011, 6011, 4) || prefix(644, 649, 3) || prefix(65, 65, 2):
+		return "discover", n >= 16 && n <= 19
+	case prefix(3528, 3589, 4):
+		return "jcb", n >= 16 && n <= 19
+	case prefix(300, 305, 3) || prefix(36, 36, 2) || prefix(38, 39, 2):
+		return "diners", n >= 14 && n <= 19
+	case prefix(62, 62, 2):
+		return "unionpay", n >= 16 && n <= 19
+	case prefix(50, 50, 2) || prefix(56, 69, 2):
+		return "maestro", n >= 12 && n <= 19
+	default:
+		return "", false
+	}
+}
+
+// ValidIBAN returns true if the value (with or without spaces) is an IBAN with a valid checksum (ISO 13616, mod 97)
+func ValidIBAN(value string) bool {
+	iban := strings.ToUpper(strings.ReplaceAll(value, " ", ""))
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	if l, ok := ibanLengths[iban[:2]]; ok && l != len(iban) {
+		return false
+	}
+
+	numeric := strings.Builder{}
+	for _, c := range iban[4:] + iban[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			numeric.WriteRune(c)
+		case c >= 'A' && c <= 'Z':
+			numeric.WriteString(big.NewInt(int64(c - 'A' + 10)).String())
+		default:
+			return false
+		}
+	}
+
+	v, ok := new(big.Int).SetString(numeric.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(v, big.NewInt(97)).Int64() == 1
+}
+
+// IsHighEntropy returns true for random looking strings (at least 32 chars of mixed letters and digits, with an entropy of at least 4 bits per char)
+func IsHighEntropy(value string) bool {
+	if len(value) < 32 {
+		return false
+	}
+
+	hasUpper, hasLower, hasDigit := false, false, false
+	freq := make(map[rune]int)
+	for _, c := range value {
+		freq[c]++
+		switch {
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		}
+	}
+	if !hasDigit || !(hasUpper && hasLower) {
+		return false
+	}
+
+	entropy := 0.0
+	for _, cnt := range freq {
+		p := float64(cnt) / float64(len(value))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 4.0
+}
+
+// Mask hides the middle of the value, only the first and last (max 4) chars are kept
+func Mask(value string) string {
+	r := []rune(value)
+	keep := min(4, len(r)/4)
+	return string(r[:keep]) + strings.Repeat("*", len(r)-2*keep) + string(r[len(r)-keep:])
+}
+
+func stripSeparators(v string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(v)
+}
+
+// overlaps returns true if the match is part of (or contains) an already found value
+func overlaps(findings []Finding, match string) bool {
+	for _, f := range findings {
+		if strings.Contains(f.Match, match) || strings.Contains(match, f.Match) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,436 @@
+package sqlitefmt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DB is a read-only sqlite database (with the committed frames of its write-ahead-log applied)
+// Only table b-trees are read, indices are ignored and WITHOUT ROWID tables are not supported.
+type DB struct {
+	data     []byte
+	wal      map[uint32][]byte
+	pageSize int
+	usable   int
+
+	tables map[string]schemaTable
+}
+
+type schemaTable struct {
+	name     string
+	rootPage uint32
+	columns  []string
+	rowidCol int // index of the INTEGER PRIMARY KEY column, or -1
+}
+
+// Row is a row of a table, indexed by the column name
+// Integers are int64, floats are float64, texts are string and blobs are []byte.
+type Row map[string]any
+
+// Int returns the integer value of the column (or 0 if it is NULL or not an integer)
+func (r Row) Int(col string) int64 {
+	switch v := r[col].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+// String returns the text value of the column (or an empty string if it is NULL)
+func (r Row) String(col string) string {
+	switch v := r[col].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Open reads the database file and (if it exists) its write-ahead-log `<path>-wal`
+// The files are only read, so this also works while the database is opened by another program (e.g. a running browser).
+func Open(path string) (*DB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wal, err := os.ReadFile(path + "-wal")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return Parse(data, wal)
+}
+
+// Parse reads a database from the content of the database file and its (optional) write-ahead-log
+func Parse(data []byte, wal []byte) (*DB, error) {
+	if len(data) < headerSize || !bytes.HasPrefix(data, magic) {
+		return nil, errors.New("not a sqlite database (missing magic header)")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+
+	if enc := binary.BigEndian.Uint32(data[56:]); enc != 0 && enc != 1 {
+		return nil, fmt.Errorf("unsupported text encoding %d (only UTF-8 is supported)", enc)
+	}
+
+	db := &DB{
+		data:     data,
+		pageSize: pageSize,
+		usable:   pageSize - int(data[20]),
+		tables:   make(map[string]schemaTable),
+	}
+
+	if len(wal) > 0 {
+		frames, err := parseWAL(wal, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		db.wal = frames
+	}
+
+	err := db.readSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Tables returns the names of all tables
+func (db *DB) Tables() []string {
+	r := make([]string, 0, len(db.tables))
+	for _, t := range db.tables {
+		r = append(r, t.name)
+	}
+	return r
+}
+
+// HasTable returns true if the table exists (case-insensitive)
+func (db *DB) HasTable(name string) bool {
+	_, ok := db.tables[strings.ToLower(name)]
+	return ok
+}
+
+// Columns returns the column names of the table
+func (db *DB) Columns(name string) ([]string, error) {
+	t, ok := db.tables[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("table '%s' not found", name)
+	}
+	return t.columns, nil
+}
+
+// Rows returns all rows of the table, ordered by their rowid
+// Columns that were added after a row was written (ALTER TABLE ADD COLUMN) are NULL.
+func (db *DB) Rows(name string) ([]Row, error) {
+	t, ok := db.tables[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("table '%s' not found", name)
+	}
+
+	result := make([]Row, 0)
+	err := db.walkTable(t.rootPage, func(rowid int64, values []any) error {
+		row := make(Row, len(t.columns))
+		for i, col := range t.columns {
+			if i == t.rowidCol {
+				row[col] = rowid
+			} else if i < len(values) {
+				row[col] = values[i]
+			} else {
+				row[col] = nil
+			}
+		}
+		result = append(result, row)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("table '%s': %w", name, err)
+	}
+
+	return result, nil
+}
+
+func (db *DB) readSchema() error {
+	return db.walkTable(1, func(rowid int64, values []any) error {
+		if len(values) < 5 {
+			return errors.New("invalid schema record")
+		}
+
+		typ, _ := values[0].(string)
+		name, _ := values[1].(string)
+		root, _ := values[3].(int64)
+		sql, _ := values[4].(string)
+
+		if typ != "table" || root <= 0 {
+			return nil // indices, views, triggers and virtual tables
+		}
+
+		columns, rowidCol, withoutRowid := parseCreateTable(sql)
+		if withoutRowid {
+			return nil
+		}
+
+		db.tables[strings.ToLower(name)] = schemaTable{name: name, rootPage: uint32(root), columns: columns, rowidCol: rowidCol}
+		return nil
+	})
+}
+
+func (db *DB) page(pgno uint32) ([]byte, error) {
+	if p, ok := db.wal[pgno]; ok {
+		return p, nil
+	}
+
+	start := int(pgno-1) * db.pageSize
+	if pgno == 0 || start+db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("page %d is out of range", pgno)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// walkTable calls fn for all rows of the table b-tree (in rowid order)
+func (db *DB) walkTable(root uint32, fn func(rowid int64, values []any) error) error {
+	visited := make(map[uint32]bool)
+
+	var walk func(pgno uint32) error
+	walk = func(pgno uint32) error {
+		if visited[pgno] {
+			return fmt.Errorf("page %d is referenced multiple times", pgno)
+		}
+		visited[pgno] = true
+
+		page, err := db.page(pgno)
+		if err != nil {
+			return err
+		}
+
+		offset := 0
+		if pgno == 1 {
+			offset = headerSize
+		}
+
+		ncells := int(binary.BigEndian.Uint16(page[offset+3:]))
+
+		switch page[offset] {
+
+		case pageTypeLeafTable:
+			for i := 0; i < ncells; i++ {
+				ptr := offset + 8 + 2*i
+				if ptr+2 > len(page) {
+					return fmt.Errorf("page %d: invalid cell pointer", pgno)
+				}
+				rowid, payload, err := db.readLeafCell(page, int(binary.BigEndian.Uint16(page[ptr:])))
+				if err != nil {
+					return fmt.Errorf("page %d: %w", pgno, err)
+				}
+				values, err := decodeRecord(payload)
+				if err != nil {
+					return fmt.Errorf("page %d: %w", pgno, err)
+				}
+				if err := fn(rowid, values); err != nil {
+					return err
+				}
+			}
+			return nil
+
+		case pageTypeInteriorTable:
+			for i := 0; i < ncells; i++ {
+				ptr := offset + 12 + 2*i
+				if ptr+2 > len(page) {
+					return fmt.Errorf("page %d: invalid cell pointer", pgno)
+				}
+				cellOffset := int(binary.BigEndian.Uint16(page[ptr:]))
+				if cellOffset+4 > len(page) {
+					return fmt.Errorf("page %d: invalid cell offset", pgno)
+				}
+				if err := walk(binary.BigEndian.Uint32(page[cellOffset:])); err != nil {
+					return err
+				}
+			}
+			return walk(binary.BigEndian.Uint32(page[offset+8:]))
+
+		default:
+			return fmt.Errorf("page %d: unexpected page type 0x%02x", pgno, page[offset])
+		}
+	}
+
+	return walk(root)
+}
+
+// readLeafCell returns the rowid and the complete payload (including overflow pages) of a table leaf cell
+func (db *DB) readLeafCell(page []byte, offset int) (int64, []byte, error) {
+	if offset >= len(page) {
+		return 0, nil, errors.New("invalid cell offset")
+	}
+
+	size, n1 := getVarint(page[offset:])
+	if n1 == 0 {
+		return 0, nil, errors.New("truncated cell")
+	}
+	rowid, n2 := getVarint(page[offset+n1:])
+	if n2 == 0 {
+		return 0, nil, errors.New("truncated cell")
+	}
+	start := offset + n1 + n2
+
+	maxLocal := db.usable - 35
+	minLocal := (db.usable-12)*32/255 - 23
+
+	local := int(size)
+	if int(size) > maxLocal {
+		local = minLocal + (int(size)-minLocal)%(db.usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+
+	if start+local > len(page) {
+		return 0, nil, errors.New("cell exceeds page")
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, page[start:start+local]...)
+
+	if local < int(size) {
+		if start+local+4 > len(page) {
+			return 0, nil, errors.New("cell exceeds page")
+		}
+		next := binary.BigEndian.Uint32(page[start+local:])
+		for len(payload) < int(size) {
+			if next == 0 {
+				return 0, nil, errors.New("truncated overflow chain")
+			}
+			ovfl, err := db.page(next)
+			if err != nil {
+				return 0, nil, err
+			}
+			n := min(int(size)-len(payload), db.usable-4)
+			payload = append(payload, ovfl[4:4+n]...)
+			next = binary.BigEndian.Uint32(ovfl)
+		}
+	}
+
+	return int64(rowid), payload, nil
+}
+
+// parseCreateTable returns the column names of a CREATE TABLE statement and the index of the rowid alias (INTEGER PRIMARY KEY)
+func parseCreateTable(sql string) ([]string, int, bool) {
+	start := strings.Index(sql, "(")
+	end := strings.LastIndex(sql, ")")
+	if start < 0 || end < start {
+		return nil, -1, false
+	}
+
+	withoutRowid := strings.Contains(strings.ToUpper(strings.Join(strings.Fields(sql[end+1:]), " ")), "WITHOUT ROWID")
+
+	columns := make([]string, 0)
+	rowidCol := -1
+
+	for _, def := range splitTopLevel(sql[start+1 : end]) {
+		name, rest := splitIdentifier(strings.TrimSpace(def))
+		if name == "" {
+			continue
+		}
+
+		switch strings.ToUpper(name) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue // table constraints
+		}
+
+		tokens := strings.Fields(strings.ToUpper(rest))
+		if len(tokens) > 0 && tokens[0] == "INTEGER" && strings.Contains(strings.Join(tokens, " "), "PRIMARY KEY") && !strings.Contains(strings.Join(tokens, " "), "PRIMARY KEY DESC") {
+			rowidCol = len(columns)
+		}
+		columns = append(columns, unquoteIdentifier(name))
+	}
+
+	return columns, rowidCol, withoutRowid
+}
+
+// splitTopLevel splits the column definitions at commas that are not inside parentheses or quotes
+func splitTopLevel(v string) []string {
+	result := make([]string, 0)
+	depth := 0
+	var quote rune
+	last := 0
+
+	for i, c := range v {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			result = append(result, v[last:i])
+			last = i + 1
+		}
+	}
+
+	return append(result, v[last:])
+}
+
+// splitIdentifier splits the (possibly quoted) first identifier from the rest of the definition
+func splitIdentifier(def string) (string, string) {
+	if def == "" {
+		return "", ""
+	}
+
+	closing := map[byte]byte{'"': '"', '`': '`', '[': ']'}
+	if c, ok := closing[def[0]]; ok {
+		for i := 1; i < len(def); i++ {
+			if def[i] != c {
+				continue
+			}
+			if c == '"' && i+1 < len(def) && def[i+1] == '"' {
+				i++ // escaped quote
+				continue
+			}
+			return def[:i+1], def[i+1:]
+		}
+		return def, ""
+	}
+
+	if i := strings.IndexAny(def, " \t\r\n"); i >= 0 {
+		return def[:i], def[i:]
+	}
+	return def, ""
+}
+
+func unquoteIdentifier(v string) string {
+	if len(v) >= 2 {
+		switch {
+		case v[0] == '"' && v[len(v)-1] == '"':
+			return strings.ReplaceAll(v[1:len(v)-1], `""`, `"`)
+		case v[0] == '`' && v[len(v)-1] == '`':
+			return v[1 : len(v)-1]
+		case v[0] == '[' && v[len(v)-1] == ']':
+			return v[1 : len(v)-1]
+		}
+	}
+	return v
+}
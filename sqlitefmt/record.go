@@ -0,0 +1,199 @@
+package sqlitefmt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// putVarint encodes v as a sqlite varint (big-endian, 7 bits per byte, the 9th byte uses all 8 bits)
+func putVarint(v uint64) []byte {
+	if v&(uint64(0xff000000)<<32) != 0 {
+		buf := make([]byte, 9)
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return buf
+	}
+
+	buf := make([]byte, 0, 9)
+	for {
+		buf = append(buf, byte(v&0x7f)|0x80)
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	buf[0] &= 0x7f
+
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return buf
+}
+
+// getVarint decodes a sqlite varint, it returns the number of read bytes (or 0 if the data is truncated)
+func getVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		if i >= len(data) {
+			return 0, 0
+		}
+		v = (v << 7) | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	if len(data) < 9 {
+		return 0, 0
+	}
+	return (v << 8) | uint64(data[8]), 9
+}
+
+// encodeRecord serializes the values in the sqlite record format (header with serial types, followed by the values)
+// Supported values are nil, bool, int, int64, float64, string and []byte.
+func encodeRecord(values []any) ([]byte, error) {
+	types := make([]byte, 0, len(values))
+	body := make([]byte, 0)
+
+	for _, v := range values {
+		switch vv := v.(type) {
+		case nil:
+			types = append(types, putVarint(0)...)
+		case bool:
+			types = append(types, putVarint(serialTypeInt(int64(boolToInt(vv)), &body))...)
+		case int:
+			types = append(types, putVarint(serialTypeInt(int64(vv), &body))...)
+		case int64:
+			types = append(types, putVarint(serialTypeInt(vv, &body))...)
+		case float64:
+			types = append(types, putVarint(7)...)
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(vv))
+		case string:
+			types = append(types, putVarint(uint64(len(vv))*2+13)...)
+			body = append(body, vv...)
+		case []byte:
+			types = append(types, putVarint(uint64(len(vv))*2+12)...)
+			body = append(body, vv...)
+		default:
+			return nil, fmt.Errorf("unsupported value type %T", v)
+		}
+	}
+
+	// the header size includes its own varint
+	headerSize := len(types) + 1
+	for len(putVarint(uint64(headerSize)))+len(types) != headerSize {
+		headerSize = len(types) + len(putVarint(uint64(headerSize)))
+	}
+
+	result := make([]byte, 0, headerSize+len(body))
+	result = append(result, putVarint(uint64(headerSize))...)
+	result = append(result, types...)
+	result = append(result, body...)
+	return result, nil
+}
+
+// serialTypeInt appends the integer with the smallest possible serial type to body and returns the type
+func serialTypeInt(v int64, body *[]byte) uint64 {
+	switch {
+	case v == 0:
+		return 8
+	case v == 1:
+		return 9
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		*body = append(*body, byte(v))
+		return 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		*body = binary.BigEndian.AppendUint16(*body, uint16(v))
+		return 2
+	case v >= -(1<<23) && v < (1<<23):
+		*body = append(*body, byte(v>>16), byte(v>>8), byte(v))
+		return 3
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		*body = binary.BigEndian.AppendUint32(*body, uint32(v))
+		return 4
+	case v >= -(1<<47) && v < (1<<47):
+		*body = append(*body, byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+		return 5
+	default:
+		*body = binary.BigEndian.AppendUint64(*body, uint64(v))
+		return 6
+	}
+}
+
+func boolToInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// decodeRecord parses a record in the sqlite record format
+// Integers are returned as int64, floats as float64, texts as string and blobs as []byte.
+func decodeRecord(data []byte) ([]any, error) {
+	headerSize, n := getVarint(data)
+	if n == 0 || headerSize > uint64(len(data)) {
+		return nil, errors.New("truncated record header")
+	}
+
+	values := make([]any, 0)
+	body := data[headerSize:]
+	for i := n; i < int(headerSize); {
+		st, sn := getVarint(data[i:headerSize])
+		if sn == 0 {
+			return nil, errors.New("truncated record header")
+		}
+		i += sn
+
+		size := serialTypeSize(st)
+		if size > uint64(len(body)) {
+			return nil, errors.New("truncated record body")
+		}
+		raw := body[:size]
+		body = body[size:]
+
+		switch {
+		case st == 0:
+			values = append(values, nil)
+		case st >= 1 && st <= 6:
+			v := int64(int8(raw[0])) // sign extension of the first byte
+			for _, b := range raw[1:] {
+				v = (v << 8) | int64(b)
+			}
+			values = append(values, v)
+		case st == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(raw)))
+		case st == 8:
+			values = append(values, int64(0))
+		case st == 9:
+			values = append(values, int64(1))
+		case st >= 12 && st%2 == 0:
+			values = append(values, append([]byte{}, raw...))
+		case st >= 13:
+			values = append(values, string(raw))
+		default:
+			return nil, fmt.Errorf("invalid serial type %d", st)
+		}
+	}
+
+	return values, nil
+}
+
+func serialTypeSize(st uint64) uint64 {
+	switch st {
+	case 0, 8, 9, 10, 11:
+		return 0
+	case 1, 2, 3, 4:
+		return st
+	case 5:
+		return 6
+	case 6, 7:
+		return 8
+	default:
+		return (st - 12) / 2
+	}
+}
@@ -0,0 +1,197 @@
+package sqlitefmt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVarint(t *testing.T) {
+	cases := []uint64{0, 1, 0x7f, 0x80, 0x3fff, 0x4000, 1 << 32, 1<<56 - 1, 1 << 56, math.MaxUint64}
+
+	for _, v := range cases {
+		data := putVarint(v)
+		r, n := getVarint(data)
+		if r != v || n != len(data) {
+			t.Errorf("[%d] roundtrip failed: %d (%d bytes of %d)", v, r, n, len(data))
+		}
+	}
+
+	if !bytes.Equal(putVarint(0x80), []byte{0x81, 0x00}) {
+		t.Errorf("unexpected encoding %x", putVarint(0x80))
+	}
+}
+
+func TestRecordRoundtrip(t *testing.T) {
+	values := []any{nil, int64(0), int64(1), int64(-1), int64(300), int64(-70000), int64(1 << 40), int64(math.MinInt64), 1.5, "hällo", []byte{1, 2}, ""}
+
+	data, err := encodeRecord(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := decodeRecord(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, values) {
+		t.Errorf("roundtrip failed: %v", result)
+	}
+
+	if _, err := encodeRecord([]any{struct{}{}}); err == nil {
+		t.Errorf("expected an error for unsupported values")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rows := make([][]any, 0)
+	for i := 3000; i > 0; i-- {
+		rows = append(rows, []any{i, "https://example.com/" + strings.Repeat("x", i%50), nil})
+	}
+	rows = append(rows, []any{5000, strings.Repeat("large", 3000), []byte{0xff}})
+
+	tables := []Table{
+		{Name: "places", Columns: []Column{{Name: "id", PrimaryKey: true}, {Name: "url", Type: "TEXT"}, {Name: "data", Type: "BLOB"}}, Rows: rows},
+		{Name: "empty table", Columns: []Column{{Name: "v", Type: "INTEGER"}}},
+	}
+
+	if v := tables[1].SQL(); v != `CREATE TABLE "empty table" (v INTEGER)` {
+		t.Errorf("unexpected sql %q", v)
+	}
+
+	data, err := Write(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(data, magic) || len(data)%PageSize != 0 {
+		t.Fatalf("invalid file header")
+	}
+	if v := binary.BigEndian.Uint32(data[28:]); int(v) != len(data)/PageSize {
+		t.Errorf("unexpected page count %d", v)
+	}
+	if data[headerSize] != pageTypeLeafTable || binary.BigEndian.Uint16(data[headerSize+3:]) != 2 {
+		t.Errorf("unexpected schema page")
+	}
+
+	if _, err := Write([]Table{{Name: "t", Columns: []Column{{Name: "id", PrimaryKey: true}}, Rows: [][]any{{1}, {1}}}}); err == nil {
+		t.Errorf("expected an error for duplicate primary keys")
+	}
+	if _, err := Write([]Table{{Name: "t", Columns: []Column{{Name: "id"}}, Rows: [][]any{{1, 2}}}}); err == nil {
+		t.Errorf("expected an error for invalid rows")
+	}
+}
+
+func TestReadRoundtrip(t *testing.T) {
+	rows := make([][]any, 0)
+	for i := 1; i <= 5000; i++ {
+		rows = append(rows, []any{int64(i * 2), "https://example.com/" + strings.Repeat("x", i%80), float64(i) / 2})
+	}
+	rows = append(rows, []any{int64(20000), strings.Repeat("large", 5000), nil})
+
+	data, err := Write([]Table{
+		{Name: "places", Columns: []Column{{Name: "id", PrimaryKey: true}, {Name: "url", Type: "TEXT"}, {Name: "score", Type: "REAL"}}, Rows: rows},
+		{Name: "my table", Columns: []Column{{Name: "a b", Type: "TEXT"}, {Name: "c"}}, Rows: [][]any{{"x", []byte{1}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Parse(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := db.Rows("places")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != len(rows) {
+		t.Fatalf("expected %d rows, got %d", len(rows), len(result))
+	}
+	for i, r := range result {
+		if r.Int("id") != rows[i][0].(int64) || r.String("url") != rows[i][1].(string) || r["score"] != rows[i][2] {
+			t.Fatalf("row %d changed: %v", i, r)
+		}
+	}
+
+	if cols, err := db.Columns("My Table"); err != nil || strings.Join(cols, "|") != "a b|c" {
+		t.Errorf("unexpected columns %v (%v)", cols, err)
+	}
+	if result, err := db.Rows("my table"); err != nil || result[0].String("a b") != "x" || !bytes.Equal(result[0]["c"].([]byte), []byte{1}) {
+		t.Errorf("unexpected rows %v (%v)", result, err)
+	}
+
+	if _, err := Parse([]byte("not a database"), nil); err == nil {
+		t.Errorf("expected an error for invalid data")
+	}
+}
+
+func TestReadWAL(t *testing.T) {
+	data, err := Write([]Table{{Name: "t", Columns: []Column{{Name: "v", Type: "TEXT"}}, Rows: [][]any{{"old"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := Write([]Table{{Name: "t", Columns: []Column{{Name: "v", Type: "TEXT"}}, Rows: [][]any{{"new"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncommitted, err := Write([]Table{{Name: "t", Columns: []Column{{Name: "v", Type: "TEXT"}}, Rows: [][]any{{"uncommitted"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wal := make([]byte, walHeaderSize)
+	binary.BigEndian.PutUint32(wal[0:], walMagicBigEndian)
+	binary.BigEndian.PutUint32(wal[4:], 3007000)
+	binary.BigEndian.PutUint32(wal[8:], PageSize)
+	binary.BigEndian.PutUint32(wal[16:], 0x1234)
+	binary.BigEndian.PutUint32(wal[20:], 0x5678)
+	s0, s1 := walChecksum(binary.BigEndian, wal[:24], 0, 0)
+	binary.BigEndian.PutUint32(wal[24:], s0)
+	binary.BigEndian.PutUint32(wal[28:], s1)
+
+	addFrame := func(pgno uint32, commit uint32, page []byte) {
+		frame := make([]byte, walFrameHeaderSize)
+		binary.BigEndian.PutUint32(frame[0:], pgno)
+		binary.BigEndian.PutUint32(frame[4:], commit)
+		copy(frame[8:], wal[16:24])
+		s0, s1 = walChecksum(binary.BigEndian, frame[:8], s0, s1)
+		s0, s1 = walChecksum(binary.BigEndian, page, s0, s1)
+		binary.BigEndian.PutUint32(frame[16:], s0)
+		binary.BigEndian.PutUint32(frame[20:], s1)
+		wal = append(append(wal, frame...), page...)
+	}
+	addFrame(2, 2, changed[PageSize:2*PageSize])
+	addFrame(2, 0, uncommitted[PageSize:2*PageSize])
+
+	for walData, expected := range map[string]string{"": "old", string(wal): "new", string(wal[:walHeaderSize+10]): "old"} {
+		db, err := Parse(data, []byte(walData))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := db.Rows("t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 || rows[0].String("v") != expected {
+			t.Errorf("expected %q, got %v", expected, rows)
+		}
+	}
+}
+
+func TestParseCreateTable(t *testing.T) {
+	cols, rowid, withoutRowid := parseCreateTable("CREATE TABLE moz_places (   id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR, frecency INTEGER DEFAULT -1 NOT NULL, guid TEXT, CONSTRAINT x UNIQUE(url, guid), `last` DECIMAL(10, 2))")
+	if strings.Join(cols, ",") != "id,url,title,frecency,guid,last" || rowid != 0 || withoutRowid {
+		t.Errorf("unexpected result %v %d %v", cols, rowid, withoutRowid)
+	}
+
+	cols, rowid, withoutRowid = parseCreateTable(`CREATE TABLE "t" ("a ""b""" TEXT, [c] INTEGER, PRIMARY KEY(c)) WITHOUT ROWID`)
+	if strings.Join(cols, ",") != `a "b",c` || rowid != -1 || !withoutRowid {
+		t.Errorf("unexpected result %v %d %v", cols, rowid, withoutRowid)
+	}
+}
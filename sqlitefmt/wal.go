@@ -0,0 +1,84 @@
+package sqlitefmt
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The write-ahead-log contains changed pages that are not yet written back into the database file (firefox uses journal_mode=WAL).
+// It consists of a 32 byte header and frames (24 byte header + page), a frame with a non-zero `commit` field ends a transaction.
+// Every frame has a cumulative checksum, the log ends at the first frame with a wrong salt or checksum.
+
+const (
+	walHeaderSize      = 32
+	walFrameHeaderSize = 24
+
+	walMagicLittleEndian = 0x377f0682
+	walMagicBigEndian    = 0x377f0683
+)
+
+// parseWAL returns the newest committed version of all pages in the log
+func parseWAL(wal []byte, pageSize int) (map[uint32][]byte, error) {
+	if len(wal) < walHeaderSize {
+		return nil, nil // an empty or truncated log contains no committed transaction
+	}
+
+	var order binary.ByteOrder
+	switch binary.BigEndian.Uint32(wal[0:]) {
+	case walMagicLittleEndian:
+		order = binary.LittleEndian
+	case walMagicBigEndian:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("invalid write-ahead-log magic header")
+	}
+
+	if v := int(binary.BigEndian.Uint32(wal[8:])); v != pageSize {
+		return nil, fmt.Errorf("the page size of the write-ahead-log (%d) does not match the database (%d)", v, pageSize)
+	}
+
+	salt1 := binary.BigEndian.Uint32(wal[16:])
+	salt2 := binary.BigEndian.Uint32(wal[20:])
+
+	s0, s1 := walChecksum(order, wal[:24], 0, 0)
+	if s0 != binary.BigEndian.Uint32(wal[24:]) || s1 != binary.BigEndian.Uint32(wal[28:]) {
+		return nil, nil // invalid header, the log is ignored (like sqlite does)
+	}
+
+	committed := make(map[uint32][]byte)
+	pending := make(map[uint32][]byte)
+
+	for offset := walHeaderSize; offset+walFrameHeaderSize+pageSize <= len(wal); offset += walFrameHeaderSize + pageSize {
+		frame := wal[offset : offset+walFrameHeaderSize]
+		page := wal[offset+walFrameHeaderSize : offset+walFrameHeaderSize+pageSize]
+
+		if binary.BigEndian.Uint32(frame[8:]) != salt1 || binary.BigEndian.Uint32(frame[12:]) != salt2 {
+			break
+		}
+
+		s0, s1 = walChecksum(order, frame[:8], s0, s1)
+		s0, s1 = walChecksum(order, page, s0, s1)
+		if s0 != binary.BigEndian.Uint32(frame[16:]) || s1 != binary.BigEndian.Uint32(frame[20:]) {
+			break
+		}
+
+		pending[binary.BigEndian.Uint32(frame[0:])] = page
+
+		if binary.BigEndian.Uint32(frame[4:]) != 0 {
+			for pgno, p := range pending {
+				committed[pgno] = p
+			}
+			pending = make(map[uint32][]byte)
+		}
+	}
+
+	return committed, nil
+}
+
+func walChecksum(order binary.ByteOrder, data []byte, s0 uint32, s1 uint32) (uint32, uint32) {
+	for i := 0; i+8 <= len(data); i += 8 {
+		s0 += order.Uint32(data[i:]) + s1
+		s1 += order.Uint32(data[i+4:]) + s0
+	}
+	return s0, s1
+}
@@ -0,0 +1,304 @@
+package sqlitefmt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sqlite is the file format of firefox for its profile databases (e.g. places.sqlite)
+// The writer creates a minimal database (schema format 4, UTF-8, no indices, no freelist) with one table b-tree per table.
+// See https://www.sqlite.org/fileformat2.html
+
+const (
+	PageSize = 4096
+
+	headerSize = 100
+
+	pageTypeInteriorTable = 0x05
+	pageTypeLeafTable     = 0x0D
+
+	maxLocal = PageSize - 35                 // max payload of a table leaf cell that is stored on the page
+	minLocal = (PageSize-12)*32/255 - 23     // min payload of a table leaf cell that is stored on the page (if it overflows)
+	maxCells = (PageSize - 12) / (4 + 9 + 2) // number of interior cells that always fit into one page
+)
+
+var magic = []byte("SQLite format 3\x00")
+
+var plainIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Column is a column of a table
+// If PrimaryKey is true the column is an alias for the rowid (INTEGER PRIMARY KEY), its values must be unique integers.
+type Column struct {
+	Name       string
+	Type       string
+	PrimaryKey bool
+}
+
+// Table is a table with its rows, every row has one value per column (nil, bool, int, int64, float64, string or []byte)
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]any
+}
+
+// SQL returns the CREATE TABLE statement of the table (as stored in the sqlite_schema table)
+func (t Table) SQL() string {
+	cols := make([]string, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		def := quoteIdentifier(c.Name)
+		if c.PrimaryKey {
+			def += " INTEGER PRIMARY KEY"
+		} else if c.Type != "" {
+			def += " " + c.Type
+		}
+		cols = append(cols, def)
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(t.Name), strings.Join(cols, ", "))
+}
+
+func quoteIdentifier(v string) string {
+	if plainIdentifier.MatchString(v) {
+		return v
+	}
+	return `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+}
+
+type writer struct {
+	pages [][]byte
+}
+
+type cell struct {
+	rowid int64
+	data  []byte
+}
+
+type treeRef struct {
+	page     uint32
+	maxRowid int64
+}
+
+// Write creates a sqlite database file with the tables
+func Write(tables []Table) ([]byte, error) {
+	w := &writer{}
+	w.allocPage() // page 1 contains the file header and the sqlite_schema table
+
+	schema := make([]cell, 0, len(tables))
+	for i, t := range tables {
+		cells, err := w.tableCells(t)
+		if err != nil {
+			return nil, fmt.Errorf("table '%s': %w", t.Name, err)
+		}
+
+		root := w.buildTree(cells)
+
+		record, err := encodeRecord([]any{"table", t.Name, t.Name, int64(root), t.SQL()})
+		if err != nil {
+			return nil, err
+		}
+		schema = append(schema, w.leafCell(int64(i+1), record))
+	}
+
+	if n := w.fillLeaf(w.pages[0], headerSize, schema); n != len(schema) {
+		return nil, errors.New("database schema does not fit into the first page")
+	}
+
+	w.writeHeader()
+
+	result := make([]byte, 0, len(w.pages)*PageSize)
+	for _, p := range w.pages {
+		result = append(result, p...)
+	}
+	return result, nil
+}
+
+func (w *writer) allocPage() uint32 {
+	w.pages = append(w.pages, make([]byte, PageSize))
+	return uint32(len(w.pages))
+}
+
+func (w *writer) page(pgno uint32) []byte {
+	return w.pages[pgno-1]
+}
+
+func (w *writer) writeHeader() {
+	h := w.pages[0]
+	copy(h, magic)
+	binary.BigEndian.PutUint16(h[16:], PageSize)
+	h[18] = 1                                                // file format write version (legacy)
+	h[19] = 1                                                // file format read version (legacy)
+	h[20] = 0                                                // reserved space per page
+	h[21] = 64                                               // max embedded payload fraction
+	h[22] = 32                                               // min embedded payload fraction
+	h[23] = 32                                               // leaf payload fraction
+	binary.BigEndian.PutUint32(h[24:], 1)                    // file change counter
+	binary.BigEndian.PutUint32(h[28:], uint32(len(w.pages))) // database size in pages
+	binary.BigEndian.PutUint32(h[40:], 1)                    // schema cookie
+	binary.BigEndian.PutUint32(h[44:], 4)                    // schema format number
+	binary.BigEndian.PutUint32(h[56:], 1)                    // text encoding (UTF-8)
+	binary.BigEndian.PutUint32(h[92:], 1)                    // version-valid-for (= file change counter)
+	binary.BigEndian.PutUint32(h[96:], 3045000)              // SQLITE_VERSION_NUMBER
+}
+
+// tableCells returns the leaf cells of all rows, sorted by their rowid
+func (w *writer) tableCells(t Table) ([]cell, error) {
+	pk := -1
+	for i, c := range t.Columns {
+		if c.PrimaryKey {
+			if pk >= 0 {
+				return nil, errors.New("multiple primary key columns")
+			}
+			pk = i
+		}
+	}
+
+	type row struct {
+		rowid  int64
+		values []any
+	}
+
+	rows := make([]row, 0, len(t.Rows))
+	for i, values := range t.Rows {
+		if len(values) != len(t.Columns) {
+			return nil, fmt.Errorf("row %d has %d values (expected %d)", i, len(values), len(t.Columns))
+		}
+
+		rowid := int64(i + 1)
+		if pk >= 0 {
+			switch v := values[pk].(type) {
+			case int:
+				rowid = int64(v)
+			case int64:
+				rowid = v
+			default:
+				return nil, fmt.Errorf("row %d has a non-integer primary key (%T)", i, values[pk])
+			}
+			values = append([]any{}, values...)
+			values[pk] = nil // the rowid alias is stored as NULL in the record
+		}
+
+		rows = append(rows, row{rowid: rowid, values: values})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].rowid < rows[j].rowid })
+
+	cells := make([]cell, 0, len(rows))
+	for i, r := range rows {
+		if i > 0 && rows[i-1].rowid == r.rowid {
+			return nil, fmt.Errorf("duplicate primary key %d", r.rowid)
+		}
+
+		record, err := encodeRecord(r.values)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+
+		cells = append(cells, w.leafCell(r.rowid, record))
+	}
+
+	return cells, nil
+}
+
+// leafCell creates a table leaf cell, a large payload is split into overflow pages
+func (w *writer) leafCell(rowid int64, payload []byte) cell {
+	data := append(putVarint(uint64(len(payload))), putVarint(uint64(rowid))...)
+
+	if len(payload) <= maxLocal {
+		return cell{rowid: rowid, data: append(data, payload...)}
+	}
+
+	local := minLocal + (len(payload)-minLocal)%(PageSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+
+	data = append(data, payload[:local]...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(w.pages)+1))
+
+	rest := payload[local:]
+	for len(rest) > 0 {
+		pgno := w.allocPage()
+		n := copy(w.page(pgno)[4:], rest)
+		rest = rest[n:]
+		if len(rest) > 0 {
+			binary.BigEndian.PutUint32(w.page(pgno), pgno+1)
+		}
+	}
+
+	return cell{rowid: rowid, data: data}
+}
+
+// buildTree writes the cells into leaf pages (and the interior pages above them), it returns the root page
+func (w *writer) buildTree(cells []cell) uint32 {
+	level := make([]treeRef, 0)
+
+	for len(cells) > 0 || len(level) == 0 {
+		pgno := w.allocPage()
+		n := w.fillLeaf(w.page(pgno), 0, cells)
+		ref := treeRef{page: pgno}
+		if n > 0 {
+			ref.maxRowid = cells[n-1].rowid
+		}
+		level = append(level, ref)
+		cells = cells[n:]
+	}
+
+	for len(level) > 1 {
+		next := make([]treeRef, 0, len(level)/maxCells+1)
+		for len(level) > 0 {
+			n := min(len(level), maxCells+1)
+			next = append(next, w.fillInterior(level[:n]))
+			level = level[n:]
+		}
+		level = next
+	}
+
+	return level[0].page
+}
+
+// fillLeaf writes as many cells as fit into the leaf page, it returns the number of written cells
+func (w *writer) fillLeaf(page []byte, offset int, cells []cell) int {
+	content := PageSize
+	n := 0
+	for _, c := range cells {
+		if offset+8+2*(n+1) > content-len(c.data) {
+			break
+		}
+		content -= len(c.data)
+		copy(page[content:], c.data)
+		binary.BigEndian.PutUint16(page[offset+8+2*n:], uint16(content))
+		n++
+	}
+
+	page[offset] = pageTypeLeafTable
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(n))
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+	return n
+}
+
+// fillInterior writes an interior page with the children (the last child is the right-most pointer)
+func (w *writer) fillInterior(children []treeRef) treeRef {
+	pgno := w.allocPage()
+	page := w.page(pgno)
+
+	content := PageSize
+	for i, c := range children[:len(children)-1] {
+		data := binary.BigEndian.AppendUint32(nil, c.page)
+		data = append(data, putVarint(uint64(c.maxRowid))...)
+		content -= len(data)
+		copy(page[content:], data)
+		binary.BigEndian.PutUint16(page[12+2*i:], uint16(content))
+	}
+
+	last := children[len(children)-1]
+
+	page[0] = pageTypeInteriorTable
+	binary.BigEndian.PutUint16(page[3:], uint16(len(children)-1))
+	binary.BigEndian.PutUint16(page[5:], uint16(content))
+	binary.BigEndian.PutUint32(page[8:], last.page)
+
+	return treeRef{page: pgno, maxRowid: last.maxRowid}
+}
@@ -0,0 +1,128 @@
+package syncclient
+
+import (
+	"encoding/json"
+	"ffsyncclient/fferr"
+	"ffsyncclient/models"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joomcode/errorx"
+)
+
+type batchTestRequest struct {
+	Query             string
+	IfUnmodifiedSince string
+	Records           int
+}
+
+func batchTestServer(t *testing.T, handle func(n int, r *http.Request, w http.ResponseWriter)) (*FxAClient, FFSyncSession, *[]batchTestRequest) {
+	requests := make([]batchTestRequest, 0)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var bsos []recordsRequestSchema
+		if err := json.NewDecoder(r.Body).Decode(&bsos); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		requests = append(requests, batchTestRequest{Query: r.URL.RawQuery, IfUnmodifiedSince: r.Header.Get("X-If-Unmodified-Since"), Records: len(bsos)})
+		handle(len(requests), r, w)
+	}))
+	t.Cleanup(srv.Close)
+
+	// the hawk signature cannot handle an explicit port, so the requests are routed to the test server by the transport
+	client := NewFxAClient(testCtx(), "http://sync.test")
+	client.client.Transport = batchTestTransport{target: srv.Listener.Addr().String()}
+
+	session := FFSyncSession{APIEndpoint: "http://sync.test", HawkID: "id", HawkKey: "key", HawkHashAlgorithm: "sha256"}
+
+	return client, session, &requests
+}
+
+type batchTestTransport struct {
+	target string
+}
+
+func (t batchTestTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Host = t.target
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func batchTestRecords(n int) []models.RecordUpdate {
+	r := make([]models.RecordUpdate, 0, n)
+	for i := 0; i < n; i++ {
+		r = append(r, models.RecordUpdate{ID: fmt.Sprintf("id%d", i), Payload: new(string)})
+	}
+	return r
+}
+
+func TestPostRecordsBatchedAtomic(t *testing.T) {
+	client, session, requests := batchTestServer(t, func(n int, r *http.Request, w http.ResponseWriter) {
+		if r.URL.Query().Get("commit") == "true" {
+			_, _ = w.Write([]byte(`{"modified": 1700000000.25, "success": [], "failed": {}}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"batch": "b1", "success": [], "failed": {}}`))
+	})
+
+	modified, err := client.PostRecordsBatched(testCtx(), session, "bookmarks", batchTestRecords(250), new(float64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified != 1700000000.25 {
+		t.Errorf("unexpected modified timestamp %f", modified)
+	}
+
+	expected := []batchTestRequest{
+		{Query: "batch=true", IfUnmodifiedSince: "0.00", Records: 100},
+		{Query: "batch=b1", IfUnmodifiedSince: "", Records: 100},
+		{Query: "batch=b1&commit=true", IfUnmodifiedSince: "", Records: 50},
+	}
+	if len(*requests) != len(expected) {
+		t.Fatalf("expected %d requests, got %v", len(expected), *requests)
+	}
+	for i, v := range expected {
+		if (*requests)[i] != v {
+			t.Errorf("request %d: expected %+v, got %+v", i, v, (*requests)[i])
+		}
+	}
+}
+
+func TestPostRecordsBatchedSingleRequest(t *testing.T) {
+	client, session, requests := batchTestServer(t, func(n int, r *http.Request, w http.ResponseWriter) {
+		_, _ = w.Write([]byte(`{"modified": 5, "success": [], "failed": {}}`))
+	})
+
+	if _, err := client.PostRecordsBatched(testCtx(), session, "bookmarks", batchTestRecords(3), nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 1 || (*requests)[0].Query != "batch=true&commit=true" {
+		t.Errorf("unexpected requests %v", *requests)
+	}
+}
+
+func TestPostRecordsBatchedUnsupported(t *testing.T) {
+	client, session, requests := batchTestServer(t, func(n int, r *http.Request, w http.ResponseWriter) {
+		if n == 3 {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"modified": %d, "success": [], "failed": {}}`, n)))
+	})
+
+	_, err := client.PostRecordsBatched(testCtx(), session, "bookmarks", batchTestRecords(250), new(float64))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if errorx.IsOfType(err, fferr.Request412) || !strings.Contains(err.Error(), "200 of 250 records were already written") {
+		t.Errorf("a partial write must not be reported as a conflict: %v", err)
+	}
+
+	if v := (*requests)[1]; v.Query != "" || v.IfUnmodifiedSince != "1.00" {
+		t.Errorf("without batch support every request should be guarded by the previous timestamp: %+v", v)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -952,6 +953,43 @@ func (f FxAClient) DeleteRecord(ctx *cli.FFSContext, session FFSyncSession, coll
 	return nil
 }
 
+// DeleteRecords (hard-)deletes multiple records of a collection (DELETE /storage/<collection>?ids=...)
+func (f FxAClient) DeleteRecords(ctx *cli.FFSContext, session FFSyncSession, collection string, recordids []string) error {
+	const batchSize = 100 // the sync server allows max 100 ids per request
+
+	for i := 0; i < len(recordids); i += batchSize {
+		batch := recordids[i:min(i+batchSize, len(recordids))]
+
+		_, err := f.request(ctx, session, "DELETE", fmt.Sprintf("/storage/%s?ids=%s", url.PathEscape(collection), url.QueryEscape(strings.Join(batch, ","))), nil)
+		if err != nil {
+			return errorx.Decorate(err, "API request failed")
+		}
+	}
+
+	return nil
+}
+
+// SoftDeleteRecords replaces multiple records of a collection with tombstones ({id, deleted:true}), in batches of POST /storage/<collection>
+func (f FxAClient) SoftDeleteRecords(ctx *cli.FFSContext, session FFSyncSession, collection string, recordids []string) error {
+	updates := make([]models.RecordUpdate, 0, len(recordids))
+	for _, id := range recordids {
+		plainpayload, err := json.Marshal(deletedPayloadData{ID: id, Deleted: true})
+		if err != nil {
+			return err
+		}
+
+		payload, err := f.EncryptPayload(ctx, session, collection, string(plainpayload))
+		if err != nil {
+			return err
+		}
+
+		updates = append(updates, models.RecordUpdate{ID: id, Payload: langext.Ptr(payload)})
+	}
+
+	_, err := f.PostRecordsBatched(ctx, session, collection, updates, nil)
+	return err
+}
+
 func (f FxAClient) DeleteCollection(ctx *cli.FFSContext, session FFSyncSession, collection string) error {
 	_, err := f.request(ctx, session, "DELETE", fmt.Sprintf("/storage/%s", url.PathEscape(collection)), nil)
 	if err != nil {
@@ -1030,6 +1068,137 @@ func (f FxAClient) PutRecord(ctx *cli.FFSContext, session FFSyncSession, collect
 	return nil
 }
 
+// PostRecords writes multiple records in a single request (POST /storage/<collection>)
+// If ifUnmodifiedSince is set, the server rejects the whole batch (fferr.Request412) when the collection was modified after this timestamp.
+// Returns the new last-modified timestamp of the collection.
+func (f FxAClient) PostRecords(ctx *cli.FFSContext, session FFSyncSession, collection string, data []models.RecordUpdate, ifUnmodifiedSince *float64) (float64, error) {
+	resp, err := f.postRecords(ctx, session, collection, data, "", ifUnmodifiedSince)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Modified, nil
+}
+
+// PostRecordsBatched writes the records atomically with the batch upload of the sync server (POST /storage/<collection>?batch=true)
+//
+// The records are sent in chunks of the default `max_post_records` of the sync server (100), the server only applies them when the
+// last chunk is committed (&commit=true). If any request fails, nothing is written.
+// If ifUnmodifiedSince is set, the first request is rejected (fferr.Request412) when the collection was modified after this timestamp.
+// If the server does not support batch uploads, every chunk is written immediately, an error after the first chunk is then
+// returned as an errorx.InternalError with the number of already written records.
+// Returns the new last-modified timestamp of the collection (or ifUnmodifiedSince / 0 if there is nothing to write).
+func (f FxAClient) PostRecordsBatched(ctx *cli.FFSContext, session FFSyncSession, collection string, data []models.RecordUpdate, ifUnmodifiedSince *float64) (float64, error) {
+	const batchSize = 100 // default `max_post_records` of the sync server
+
+	if len(data) == 0 {
+		return langext.Coalesce(ifUnmodifiedSince, 0), nil
+	}
+
+	batchID := "true"
+	atomic := true
+
+	for i := 0; i < len(data); i += batchSize {
+		chunk := data[i:min(i+batchSize, len(data))]
+		last := i+batchSize >= len(data)
+
+		query := ""
+		if atomic {
+			query = "batch=" + url.QueryEscape(batchID)
+			if last {
+				query += "&commit=true"
+			}
+		}
+
+		ctx.PrintVerbose(fmt.Sprintf("Write %d records (chunk %d, batch: %s)", len(chunk), i/batchSize+1, query))
+
+		resp, err := f.postRecords(ctx, session, collection, chunk, query, ifUnmodifiedSince)
+		if err != nil && !atomic {
+			return 0, errorx.InternalError.Wrap(err, fmt.Sprintf("the server does not support batch uploads, %d of %d records were already written", i, len(data)))
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if last {
+			return resp.Modified, nil
+		}
+
+		if i == 0 {
+			batchID = parseBatchID(resp.Batch)
+			if batchID == "" {
+				ctx.PrintVerbose("The server does not support batch uploads, the records are written in separate requests")
+				atomic = false
+			}
+		}
+
+		if atomic {
+			ifUnmodifiedSince = nil // only the first request of a batch is guarded
+		} else if ifUnmodifiedSince != nil {
+			ifUnmodifiedSince = langext.Ptr(resp.Modified)
+		}
+	}
+
+	return 0, errorx.InternalError.New("unreachable")
+}
+
+// postRecords sends a single POST /storage/<collection> request (with an optional query for batch uploads)
+func (f FxAClient) postRecords(ctx *cli.FFSContext, session FFSyncSession, collection string, data []models.RecordUpdate, query string, ifUnmodifiedSince *float64) (postRecordsResponseSchema, error) {
+	bsos := make([]recordsRequestSchema, 0, len(data))
+	for _, v := range data {
+		bsos = append(bsos, recordsRequestSchema{
+			ID:        langext.Ptr(v.ID),
+			SortIndex: v.SortIndex,
+			Payload:   v.Payload,
+			TTL:       v.TTL,
+		})
+	}
+
+	headers := make(map[string]string)
+	if ifUnmodifiedSince != nil {
+		headers["X-If-Unmodified-Since"] = strconv.FormatFloat(*ifUnmodifiedSince, 'f', 2, 64)
+	}
+
+	relurl := fmt.Sprintf("/storage/%s", url.PathEscape(collection))
+	if query != "" {
+		relurl += "?" + query
+	}
+
+	binResp, err := f.requestWithHeaders(ctx, session, "POST", relurl, bsos, headers)
+	if err != nil {
+		return postRecordsResponseSchema{}, errorx.Decorate(err, "API request failed")
+	}
+
+	var resp postRecordsResponseSchema
+	err = json.Unmarshal(binResp, &resp)
+	if err != nil {
+		return postRecordsResponseSchema{}, errorx.Decorate(err, "failed to unmarshal response:\n"+string(binResp))
+	}
+
+	if len(resp.Failed) > 0 {
+		failed := make([]string, 0, len(resp.Failed))
+		for k, v := range resp.Failed {
+			failed = append(failed, fmt.Sprintf("%s (%s)", k, strings.Join(v, ", ")))
+		}
+		sort.Strings(failed)
+		return postRecordsResponseSchema{}, errorx.InternalError.New(fmt.Sprintf("failed to write %d record(s): %s", len(failed), strings.Join(failed, "; ")))
+	}
+
+	return resp, nil
+}
+
+// parseBatchID returns the id of an open batch upload (the servers return it as a string or a number), or "" if there is none
+func parseBatchID(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var v string
+	if err := json.Unmarshal(raw, &v); err == nil {
+		return v
+	}
+	return string(raw)
+}
+
 func (f FxAClient) EncryptPayload(ctx *cli.FFSContext, session FFSyncSession, collection string, rawpayload string) (string, error) {
 
 	bulkKeys := session.BulkKeys[""]
@@ -1083,7 +1252,7 @@ func (f FxAClient) requestWithHawkToken(ctx *cli.FFSContext, method string, relu
 		return hawkAuth, nil
 	}
 
-	res, err := f.internalRequest(ctx, auth, method, requestURL, body)
+	res, err := f.internalRequest(ctx, auth, method, requestURL, body, nil)
 	if err != nil {
 		return nil, nil, errorx.Decorate(err, "Request failed")
 	}
@@ -1092,6 +1261,10 @@ func (f FxAClient) requestWithHawkToken(ctx *cli.FFSContext, method string, relu
 }
 
 func (f FxAClient) request(ctx *cli.FFSContext, session FFSyncSession, method string, relurl string, body any) ([]byte, error) {
+	return f.requestWithHeaders(ctx, session, method, relurl, body, nil)
+}
+
+func (f FxAClient) requestWithHeaders(ctx *cli.FFSContext, session FFSyncSession, method string, relurl string, body any, headers map[string]string) ([]byte, error) {
 	requestURL := session.APIEndpoint + relurl
 
 	auth := func(method string, url string, body string, contentType string) (string, error) {
@@ -1103,7 +1276,7 @@ func (f FxAClient) request(ctx *cli.FFSContext, session FFSyncSession, method st
 		return hawkAuth, nil
 	}
 
-	res, err := f.internalRequest(ctx, auth, method, requestURL, body)
+	res, err := f.internalRequest(ctx, auth, method, requestURL, body, headers)
 	if err != nil {
 		return nil, errorx.Decorate(err, "Request failed")
 	}
@@ -1111,7 +1284,7 @@ func (f FxAClient) request(ctx *cli.FFSContext, session FFSyncSession, method st
 	return res, nil
 }
 
-func (f FxAClient) internalRequest(ctx *cli.FFSContext, auth func(method string, url string, body string, contentType string) (string, error), method string, requestURL string, body any, fastlyRetry ...bool) ([]byte, error) {
+func (f FxAClient) internalRequest(ctx *cli.FFSContext, auth func(method string, url string, body string, contentType string) (string, error), method string, requestURL string, body any, headers map[string]string, fastlyRetry ...bool) ([]byte, error) {
 	strBody := ""
 	var bodyReader io.Reader = nil
 	if body != nil {
@@ -1132,6 +1305,9 @@ func (f FxAClient) internalRequest(ctx *cli.FFSContext, auth func(method string,
 	req.Header.Add("User-Agent", "firefox-sync-client/"+consts.FFSCLIENT_VERSION)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Host", req.URL.Host)
+	for k, v := range headers {
+		req.Header.Add(k, v)
+	}
 
 	// Inject cached Fastly anti-bot cookie for *.firefox.com requests
 	if strings.HasSuffix(req.URL.Host, ".firefox.com") || req.URL.Host == "firefox.com" {
@@ -1176,7 +1352,7 @@ func (f FxAClient) internalRequest(ctx *cli.FFSContext, auth func(method string,
 		}
 		f.cookieCache.set("firefox.com", cookie)
 		ctx.PrintVerbose("Retrying request with Fastly cookie...")
-		return f.internalRequest(ctx, auth, method, requestURL, body, true)
+		return f.internalRequest(ctx, auth, method, requestURL, body, headers, true)
 	}
 
 	ctx.PrintVerbose(fmt.Sprintf("Request returned statuscode %d", rawResp.StatusCode))
@@ -1221,7 +1397,11 @@ func (f FxAClient) internalRequest(ctx *cli.FFSContext, auth func(method string,
 		return nil, fferr.Request400.New(fmt.Sprintf("call to %v returned statuscode %v", requestURL, rawResp.StatusCode))
 	}
 
-	if rawResp.StatusCode != 200 {
+	if rawResp.StatusCode == 412 {
+		return nil, fferr.Request412.New(fmt.Sprintf("call to %v returned statuscode %v (%s)", requestURL, rawResp.StatusCode, "The collection was modified since the specified X-If-Unmodified-Since timestamp."))
+	}
+
+	if rawResp.StatusCode != 200 && rawResp.StatusCode != 202 { // 202: accepted into an open batch upload
 		if len(string(respBodyRaw)) > 1 {
 			return nil, errorx.InternalError.New(fmt.Sprintf("call to %v returned statuscode %v\nBody:\n%v", requestURL, rawResp.StatusCode, string(respBodyRaw)))
 		} else {
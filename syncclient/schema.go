@@ -1,5 +1,7 @@
 package syncclient
 
+import "encoding/json"
+
 type loginRequestSchema struct {
 	Email  string `json:"email"`
 	AuthPW string `json:"authPW"`
@@ -114,6 +116,13 @@ type recordsRequestSchema struct {
 	TTL       *int64  `json:"ttl,omitempty"`
 }
 
+type postRecordsResponseSchema struct {
+	Modified float64             `json:"modified"`
+	Success  []string            `json:"success"`
+	Failed   map[string][]string `json:"failed"`
+	Batch    json.RawMessage     `json:"batch"` // the id of the open batch (only with ?batch=true), a string or a number depending on the server
+}
+
 type sessionStatusResponseSchema struct {
 	State  string `json:"state"`
 	UserID string `json:"uid"`